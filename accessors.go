@@ -0,0 +1,1020 @@
+package gitcfg
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// lookupOptional reads key into dst when it is set, leaving dst untouched
+// when the key or its section is absent. Conversion errors are returned.
+func lookupOptional[T Constraint](c *Config, key string, dst *T) error {
+	value, err := Get[T](c, key)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrSectionNotFound) {
+			return nil
+		}
+		return err
+	}
+	*dst = value
+	return nil
+}
+
+// fieldReader reads a series of optional keys, stopping at the first error.
+type fieldReader struct {
+	c   *Config
+	err error
+}
+
+func readField[T Constraint](r *fieldReader, key string, dst *T) {
+	if r.err != nil {
+		return
+	}
+	r.err = lookupOptional(r.c, key, dst)
+}
+
+// GetCommitConfig returns the commit.* settings. Absent keys keep git's
+// defaults.
+func (c *Config) GetCommitConfig() (*CommitConfig, error) {
+	cfg := &CommitConfig{Status: true}
+
+	r := &fieldReader{c: c}
+	readField(r, CommitGPGSign, &cfg.GPGSign)
+	readField(r, CommitTemplate, &cfg.Template)
+	readField(r, CommitCleanup, &cfg.Cleanup)
+	readField(r, CommitStatus, &cfg.Status)
+	readField(r, CommitVerbose, &cfg.Verbose)
+	readField(r, CommitAuthor, &cfg.Author)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if cfg.Template != "" {
+		template, err := expandPath(cfg.Template)
+		if err != nil {
+			return nil, &ConfigError{
+				Op:      "get",
+				Key:     "template",
+				Section: "commit",
+				Err:     err,
+			}
+		}
+		cfg.Template = template
+	}
+
+	return cfg, nil
+}
+
+// GetCommitTemplate returns the expanded path of commit.template and verifies
+// that the file exists.
+func (c *Config) GetCommitTemplate() (string, error) {
+	path, err := c.GetPath(CommitTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", &ConfigError{
+			Op:      "get",
+			Key:     "template",
+			Section: "commit",
+			Err:     fmt.Errorf("commit template %s is not accessible: %w", path, err),
+		}
+	}
+
+	return path, nil
+}
+
+// GetStatusConfig returns the status.* settings. Absent keys keep git's
+// defaults.
+func (c *Config) GetStatusConfig() (*StatusConfig, error) {
+	cfg := &StatusConfig{
+		AheadBehind:        true,
+		ShowUntrackedFiles: "normal",
+	}
+
+	r := &fieldReader{c: c}
+	readField(r, StatusShort, &cfg.Short)
+	readField(r, StatusBranch, &cfg.Branch)
+	readField(r, StatusAheadBehind, &cfg.AheadBehind)
+	readField(r, StatusShowStash, &cfg.ShowStash)
+	readField(r, StatusShowUntrackedFiles, &cfg.ShowUntrackedFiles)
+	readField(r, StatusRenameLimit, &cfg.RenameLimit)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	summary, err := c.getIntOrBool(StatusSubmoduleSummary, -1)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SubmoduleSummary = summary
+
+	return cfg, nil
+}
+
+// getIntOrBool reads a key that git accepts either as a number or a boolean.
+// A true boolean maps to trueValue and false to 0; an absent key yields 0.
+func (c *Config) getIntOrBool(key string, trueValue int) (int, error) {
+	raw, err := Get[string](c, key)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrSectionNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if n, err := convertValue[int](raw); err == nil {
+		return n, nil
+	}
+
+	b, err := parseBool(raw)
+	if err != nil {
+		section, subkey, _ := parseConfigKey(key)
+		return 0, &ConfigError{
+			Op:      "get",
+			Key:     subkey,
+			Section: section,
+			Err:     fmt.Errorf("%w: expected a number or boolean, got %q", ErrInvalidValue, raw),
+		}
+	}
+	if b {
+		return trueValue, nil
+	}
+	return 0, nil
+}
+
+// readIntField reads an integer that may carry git's k, m or g suffix.
+func readIntField(r *fieldReader, key string, dst *int) {
+	var value string
+	readField(r, key, &value)
+	if r.err != nil || !r.c.Has(key) {
+		return
+	}
+
+	n, err := parseGitInt(value)
+	if err == nil && int64(int(n)) != n {
+		err = fmt.Errorf("integer value out of range: %s", value)
+	}
+	if err != nil {
+		r.err = fieldError(key, err)
+		return
+	}
+	*dst = int(n)
+}
+
+// readDurationField reads a timeout given in seconds or as a Go duration.
+func readDurationField(r *fieldReader, key string, dst *time.Duration) {
+	var value string
+	readField(r, key, &value)
+	if r.err != nil || !r.c.Has(key) {
+		return
+	}
+
+	d, err := parseDuration(value)
+	if err != nil {
+		r.err = fieldError(key, err)
+		return
+	}
+	*dst = d
+}
+
+func fieldError(key string, err error) error {
+	section, subkey, _ := parseConfigKey(key)
+	return &ConfigError{
+		Op:      "get",
+		Key:     subkey,
+		Section: section,
+		Err:     fmt.Errorf("%w: %v", ErrInvalidValue, err),
+	}
+}
+
+func readMultiField(r *fieldReader, key string, dst *[]string) {
+	if r.err != nil {
+		return
+	}
+
+	values, err := r.c.GetMultiValue(key)
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+			r.err = err
+		}
+		return
+	}
+	*dst = values
+}
+
+// GetTagConfig returns the tag.* settings.
+func (c *Config) GetTagConfig() (*TagConfig, error) {
+	cfg := &TagConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, TagGPGSign, &cfg.GPGSign)
+	readField(r, TagSort, &cfg.Sort)
+	readField(r, TagForceSignAnnotated, &cfg.ForceSignAnnotated)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetNoteConfig returns the notes.* settings, including every displayRef
+// value and the notes.rewrite.<command> entries.
+func (c *Config) GetNoteConfig() (*NoteConfig, error) {
+	cfg := &NoteConfig{
+		Rewrite: c.GetSection(NotesRewrite),
+	}
+
+	r := &fieldReader{c: c}
+	readMultiField(r, NotesDisplayRef, &cfg.DisplayRef)
+	readField(r, NotesRewriteRef, &cfg.RewriteRef)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetAMConfig returns the am.* settings, zero-valued when none are set.
+func (c *Config) GetAMConfig() (*AMConfig, error) {
+	cfg := &AMConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, AMKeepCR, &cfg.KeepCR)
+	readField(r, AMThreeWay, &cfg.ThreeWay)
+	readField(r, AMSignoff, &cfg.Signoff)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetApplyConfig returns the apply.* settings, zero-valued when none are set.
+func (c *Config) GetApplyConfig() (*ApplyConfig, error) {
+	cfg := &ApplyConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, ApplyIgnoreWhitespace, &cfg.IgnoreWhitespace)
+	readField(r, ApplyWhitespace, &cfg.Whitespace)
+	readField(r, ApplyStat, &cfg.Stat)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetPath returns a path-valued key with a leading ~ and environment
+// variables expanded, made absolute.
+func (c *Config) GetPath(key string) (string, error) {
+	raw, err := Get[string](c, key)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := expandPath(raw)
+	if err != nil {
+		section, subkey, _ := parseConfigKey(key)
+		return "", &ConfigError{
+			Op:      "get",
+			Key:     subkey,
+			Section: section,
+			Err:     err,
+		}
+	}
+	return path, nil
+}
+
+// GetBytes returns an integer key that may carry git's k, m or g suffix, such
+// as http.postBuffer = 512m, scaled to a number of bytes.
+func (c *Config) GetBytes(key string) (int64, error) {
+	raw, err := Get[string](c, key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := parseGitInt(raw)
+	if err != nil {
+		return 0, fieldError(key, err)
+	}
+	return n, nil
+}
+
+// GetDuration returns a key given in seconds, as most git timeouts are, or
+// as a Go duration such as 1m30s.
+func (c *Config) GetDuration(key string) (time.Duration, error) {
+	raw, err := Get[string](c, key)
+	if err != nil {
+		return 0, err
+	}
+
+	d, err := parseDuration(raw)
+	if err != nil {
+		return 0, fieldError(key, err)
+	}
+	return d, nil
+}
+
+// readPathField is readField for path-valued keys, expanded like GetPath.
+func readPathField(r *fieldReader, key string, dst *string) {
+	if r.err != nil {
+		return
+	}
+
+	path, err := r.c.GetPath(key)
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+			r.err = err
+		}
+		return
+	}
+	*dst = path
+}
+
+// GetSigningConfig returns the commit and tag signing settings. Absent keys
+// keep git's defaults; use SigningConfig.IsSet to tell them apart from
+// explicitly configured values.
+func (c *Config) GetSigningConfig() (*SigningConfig, error) {
+	cfg := &SigningConfig{
+		Format:     "openpgp",
+		Program:    "gpg",
+		SSHProgram: "ssh-keygen",
+	}
+
+	r := &fieldReader{c: c}
+	readField(r, CommitGPGSign, &cfg.CommitGPGSign)
+	readField(r, TagGPGSign, &cfg.TagGPGSign)
+	readField(r, GPGFormat, &cfg.Format)
+	readField(r, GPGProgram, &cfg.Program)
+	readField(r, GPGSSHProgram, &cfg.SSHProgram)
+	readPathField(r, GPGSSHAllowedSignersFile, &cfg.SSHAllowedSignersFile)
+	readField(r, GPGSSHDefaultKeyCommand, &cfg.SSHDefaultKeyCommand)
+	readField(r, UserSigningKey, &cfg.SigningKey)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	switch cfg.Format {
+	case "openpgp", "x509", "ssh":
+	default:
+		return nil, &ConfigError{
+			Op:      "get",
+			Key:     "format",
+			Section: "gpg",
+			Err:     fmt.Errorf("%w: unsupported signing format %q", ErrInvalidValue, cfg.Format),
+		}
+	}
+
+	for field, key := range map[SigningField]string{
+		SigningCommitGPGSign:         CommitGPGSign,
+		SigningTagGPGSign:            TagGPGSign,
+		SigningFormat:                GPGFormat,
+		SigningProgram:               GPGProgram,
+		SigningSSHProgram:            GPGSSHProgram,
+		SigningSSHAllowedSignersFile: GPGSSHAllowedSignersFile,
+		SigningSSHDefaultKeyCommand:  GPGSSHDefaultKeyCommand,
+		SigningKey:                   UserSigningKey,
+	} {
+		if c.HasExplicit(key) {
+			cfg.Set |= field
+		}
+	}
+
+	return cfg, nil
+}
+
+// GetCredentialConfig returns the credential settings that apply to rawURL.
+// The generic credential.* keys are combined with every matching
+// credential.<url>.* section, selected with the same rules as http.<url>.*,
+// where more specific URLs take precedence. Helpers from all of those
+// sections are collected in the order they were loaded, as git applies
+// them, and an empty helper value clears the list gathered so far. Values
+// loaded with WithGitCommand carry no line numbers, so within one file they
+// are taken in order of specificity instead. An empty rawURL returns only
+// the generic settings.
+func (c *Config) GetCredentialConfig(rawURL string) (*CredentialConfig, error) {
+	sections := []string{"credential"}
+	if rawURL != "" {
+		sections = append(sections, c.matchingURLSections("credential", rawURL)...)
+	}
+
+	cfg := &CredentialConfig{Helpers: make([]string, 0)}
+
+	r := &fieldReader{c: c}
+	var helpers []ValueWithSource
+	for _, section := range sections {
+		readMultiSourceField(r, section+".helper", &helpers)
+		readField(r, section+".usehttppath", &cfg.UseHTTPPath)
+		readField(r, section+".username", &cfg.Username)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	sortByLoadOrder(helpers, c.GetSources())
+	for _, helper := range helpers {
+		if helper.Value == "" {
+			cfg.Helpers = cfg.Helpers[:0]
+			continue
+		}
+		cfg.Helpers = append(cfg.Helpers, helper.Value)
+	}
+
+	return cfg, nil
+}
+
+// readMultiSourceField appends every value of key, with its provenance, to
+// dst.
+func readMultiSourceField(r *fieldReader, key string, dst *[]ValueWithSource) {
+	if r.err != nil {
+		return
+	}
+
+	values, err := r.c.GetMultiValueWithSources(key)
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+			r.err = err
+		}
+		return
+	}
+	*dst = append(*dst, values...)
+}
+
+// sortByLoadOrder stably sorts values into the order their sources were
+// loaded in, and by line within a source. Values from sources not in
+// sources, such as those set in memory, sort last.
+func sortByLoadOrder(values []ValueWithSource, sources []ConfigSource) {
+	index := make(map[ConfigSource]int, len(sources))
+	for i, source := range sources {
+		if _, ok := index[source]; !ok {
+			index[source] = i
+		}
+	}
+	position := func(source ConfigSource) int {
+		if i, ok := index[source]; ok {
+			return i
+		}
+		return len(sources)
+	}
+
+	slices.SortStableFunc(values, func(a, b ValueWithSource) int {
+		if n := position(a.Source) - position(b.Source); n != 0 {
+			return n
+		}
+		return a.Line - b.Line
+	})
+}
+
+// GetHTTPForURL returns the http.* settings that apply to rawURL: the
+// generic http.* keys, overridden by every http.<url>.* section whose URL
+// matches, from the least to the most specific match. An empty rawURL
+// returns just the generic settings.
+func (c *Config) GetHTTPForURL(rawURL string) (*HTTPConfig, error) {
+	sections := []string{"http"}
+	if rawURL != "" {
+		if _, err := url.Parse(rawURL); err != nil {
+			return nil, &ConfigError{
+				Op:      "get",
+				Section: "http",
+				Err:     fmt.Errorf("invalid URL %q: %w", rawURL, ErrInvalidValue),
+			}
+		}
+		sections = append(sections, c.matchingURLSections("http", rawURL)...)
+	}
+
+	cfg := &HTTPConfig{
+		SSLVerify:    true,
+		ExtraHeaders: make([]string, 0),
+	}
+
+	r := &fieldReader{c: c}
+	for _, section := range sections {
+		var headers []string
+		readField(r, section+".sslverify", &cfg.SSLVerify)
+		readPathField(r, section+".sslcainfo", &cfg.SSLCAInfo)
+		readPathField(r, section+".sslcert", &cfg.SSLCert)
+		readPathField(r, section+".sslkey", &cfg.SSLKey)
+		readField(r, section+".proxy", &cfg.Proxy)
+		readIntField(r, section+".postbuffer", &cfg.PostBuffer)
+		readIntField(r, section+".lowspeedlimit", &cfg.LowSpeedLimit)
+		readIntField(r, section+".lowspeedtime", &cfg.LowSpeedTime)
+		readMultiField(r, section+".extraheader", &headers)
+		readPathField(r, section+".cookiefile", &cfg.CookieFile)
+		readField(r, section+".useragent", &cfg.UserAgent)
+
+		for _, header := range headers {
+			if header == "" {
+				cfg.ExtraHeaders = cfg.ExtraHeaders[:0]
+				continue
+			}
+			cfg.ExtraHeaders = append(cfg.ExtraHeaders, header)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetFilterDriver returns the filter.<name> driver, or an error wrapping
+// ErrSectionNotFound if it is not configured.
+func (c *Config) GetFilterDriver(name string) (*FilterDriver, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	section := "filter." + name
+	if !c.HasSection(section) {
+		return nil, &ConfigError{
+			Op:      "get",
+			Section: section,
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	driver := &FilterDriver{Name: name}
+
+	r := &fieldReader{c: c}
+	readField(r, section+".clean", &driver.Clean)
+	readField(r, section+".smudge", &driver.Smudge)
+	readField(r, section+".process", &driver.Process)
+	readField(r, section+".required", &driver.Required)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return driver, nil
+}
+
+// GetLFSConfig returns the Git LFS setup. It fails with an error wrapping
+// ErrSectionNotFound when the lfs filter driver is not configured, i.e. LFS
+// is not installed for this config.
+func (c *Config) GetLFSConfig() (*LFSConfig, error) {
+	driver, err := c.GetFilterDriver("lfs")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &LFSConfig{
+		Filter:              *driver,
+		Batch:               true,
+		ConcurrentTransfers: 8,
+		DialTimeout:         30 * time.Second,
+		KeepAlive:           30 * time.Minute,
+		TLSTimeout:          30 * time.Second,
+		ActivityTimeout:     30 * time.Second,
+	}
+
+	r := &fieldReader{c: c}
+	readField(r, LFSURL, &cfg.URL)
+	readField(r, LFSBatch, &cfg.Batch)
+	readIntField(r, LFSConcurrentTransfers, &cfg.ConcurrentTransfers)
+	readField(r, LFSTLS, &cfg.TLS)
+	readField(r, LFSLocksVerify, &cfg.LocksVerify)
+	readDurationField(r, LFSDialTimeout, &cfg.DialTimeout)
+	readDurationField(r, LFSKeepAlive, &cfg.KeepAlive)
+	readDurationField(r, LFSTLSTimeout, &cfg.TLSTimeout)
+	readDurationField(r, LFSActivityTimeout, &cfg.ActivityTimeout)
+	readField(r, LFSSSLNoVerify, &cfg.SSLNoVerify)
+	readField(r, LFSObjectStorageURL, &cfg.ObjectStorageURL)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetAdviceConfig returns the advice.* hints. Hints default to enabled and
+// are parsed with git's boolean rules, so an empty value means true.
+func (c *Config) GetAdviceConfig() (*AdviceConfig, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	cfg := &AdviceConfig{
+		PushUpdateRejected: true,
+		StatusHints:        true,
+		CommitBeforeMerge:  true,
+		ResolveConflict:    true,
+		ImplicitIdentity:   true,
+		DetachedHead:       true,
+		AddIgnoredFile:     true,
+		SkippedCherryPicks: true,
+		Extra:              make(map[string]bool),
+	}
+
+	known := map[string]*bool{
+		"pushupdaterejected": &cfg.PushUpdateRejected,
+		"statushints":        &cfg.StatusHints,
+		"commitbeforemerge":  &cfg.CommitBeforeMerge,
+		"resolveconflict":    &cfg.ResolveConflict,
+		"implicitidentity":   &cfg.ImplicitIdentity,
+		"detachedhead":       &cfg.DetachedHead,
+		"addignoredfile":     &cfg.AddIgnoredFile,
+		"skippedcherrypicks": &cfg.SkippedCherryPicks,
+	}
+
+	for key := range c.GetSection(AdviceSection) {
+		enabled, err := Get[bool](c, AdviceSection+"."+key)
+		if err != nil {
+			return nil, err
+		}
+		if dst, ok := known[key]; ok {
+			*dst = enabled
+		} else {
+			cfg.Extra[key] = enabled
+		}
+	}
+
+	return cfg, nil
+}
+
+// GetMaintenanceConfig returns the maintenance.* settings. Repos lists every
+// maintenance.repo value across scopes.
+func (c *Config) GetMaintenanceConfig() (*MaintenanceConfig, error) {
+	cfg := &MaintenanceConfig{
+		Auto:  true,
+		Repos: make([]string, 0),
+	}
+
+	r := &fieldReader{c: c}
+	readField(r, MaintenanceAuto, &cfg.Auto)
+	readField(r, MaintenanceStrategy, &cfg.Strategy)
+	readMultiField(r, MaintenanceRepo, &cfg.Repos)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+var (
+	conflictStyles = []string{"merge", "diff3", "zdiff3"}
+	diffAlgorithms = []string{"default", "myers", "minimal", "patience", "histogram"}
+)
+
+// GetSendEmailConfig returns the git send-email settings for identity, or
+// for sendemail.identity when identity is empty. Each key set in
+// sendemail.<identity> replaces the base sendemail value, including the
+// whole of a multi-valued To, Cc or Bcc list. A named identity without a
+// section is reported as ErrSectionNotFound.
+func (c *Config) GetSendEmailConfig(identity string) (*SendEmailConfig, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	if identity == "" {
+		if err := lookupOptional(c, SendEmailIdentity, &identity); err != nil {
+			return nil, err
+		}
+	}
+
+	sections := []string{"sendemail"}
+	if identity != "" {
+		section := "sendemail." + identity
+		if !c.HasSection(section) {
+			return nil, &ConfigError{
+				Op:      "get",
+				Section: section,
+				Err:     ErrSectionNotFound,
+			}
+		}
+		sections = append(sections, section)
+	}
+
+	cfg := &SendEmailConfig{
+		Identity: identity,
+		To:       make([]string, 0),
+		Cc:       make([]string, 0),
+		Bcc:      make([]string, 0),
+	}
+
+	r := &fieldReader{c: c}
+	for _, section := range sections {
+		readField(r, section+".smtpserver", &cfg.SMTPServer)
+		readField(r, section+".smtpuser", &cfg.SMTPUser)
+		readField(r, section+".smtpencryption", &cfg.SMTPEncryption)
+		readField(r, section+".smtpserverport", &cfg.SMTPServerPort)
+		readField(r, section+".from", &cfg.From)
+		readMultiField(r, section+".to", &cfg.To)
+		readMultiField(r, section+".cc", &cfg.Cc)
+		readMultiField(r, section+".bcc", &cfg.Bcc)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// validateChoice returns an ErrInvalidValue ConfigError if a non-empty value
+// is not one of choices.
+func validateChoice(key, value string, choices []string) error {
+	if value == "" || slices.Contains(choices, strings.ToLower(value)) {
+		return nil
+	}
+
+	section, subkey, _ := parseConfigKey(key)
+	return &ConfigError{
+		Op:      "get",
+		Key:     subkey,
+		Section: section,
+		Err:     fmt.Errorf("%w: %q is not one of %s", ErrInvalidValue, value, strings.Join(choices, ", ")),
+	}
+}
+
+// GetMergeConfig returns the merge.* settings, zero-valued when none are set.
+func (c *Config) GetMergeConfig() (*MergeConfig, error) {
+	cfg := &MergeConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, MergeTool, &cfg.Tool)
+	readField(r, MergeConflictStyle, &cfg.ConflictStyle)
+	readField(r, MergeFF, &cfg.FF)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := validateChoice(MergeConflictStyle, cfg.ConflictStyle, conflictStyles); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// GetDiffConfig returns the diff.* settings, zero-valued when none are set.
+func (c *Config) GetDiffConfig() (*DiffConfig, error) {
+	cfg := &DiffConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, DiffTool, &cfg.Tool)
+	readField(r, DiffAlgorithm, &cfg.Algorithm)
+	readField(r, DiffRenames, &cfg.Renames)
+	readField(r, DiffColorMoved, &cfg.ColorMoved)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := validateChoice(DiffAlgorithm, cfg.Algorithm, diffAlgorithms); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// GetToolCommand returns the configuration of a merge or diff tool, where
+// kind is "merge" or "diff". Tools that git supports natively, like vimdiff,
+// need no configuration and are returned with an empty Cmd.
+func (c *Config) GetToolCommand(kind, name string) (*ToolCommand, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	if kind != "merge" && kind != "diff" {
+		return nil, &ConfigError{
+			Op:  "get",
+			Key: kind,
+			Err: fmt.Errorf("%w: tool kind must be merge or diff", ErrInvalidValue),
+		}
+	}
+
+	section := kind + "tool"
+	tool := &ToolCommand{Name: name, Prompt: true}
+
+	r := &fieldReader{c: c}
+	readField(r, section+".prompt", &tool.Prompt)
+	if kind == "diff" {
+		// difftool.trustExitCode is a global default; mergetool has none
+		readField(r, section+".trustexitcode", &tool.TrustExitCode)
+	}
+	readField(r, section+"."+name+".cmd", &tool.Cmd)
+	readField(r, section+"."+name+".trustexitcode", &tool.TrustExitCode)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return tool, nil
+}
+
+// GetFetchConfig returns the fetch.* settings. Absent keys keep git's
+// defaults.
+func (c *Config) GetFetchConfig() (*FetchConfig, error) {
+	cfg := &FetchConfig{Parallel: 1}
+
+	r := &fieldReader{c: c}
+	readField(r, FetchPrune, &cfg.Prune)
+	readField(r, FetchPruneTags, &cfg.PruneTags)
+	readField(r, FetchParallel, &cfg.Parallel)
+	readField(r, FetchRecurseSubmodules, &cfg.RecurseSubmodules)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetPullConfig returns the pull.* settings, zero-valued when none are set.
+func (c *Config) GetPullConfig() (*PullConfig, error) {
+	cfg := &PullConfig{}
+
+	var rebase string
+	r := &fieldReader{c: c}
+	readField(r, PullRebase, &rebase)
+	readField(r, PullFF, &cfg.FF)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if c.Has(PullRebase) {
+		mode, err := parseRebaseMode(rebase)
+		if err != nil {
+			return nil, &ConfigError{
+				Op:      "get",
+				Key:     "rebase",
+				Section: "pull",
+				Err:     err,
+			}
+		}
+		cfg.Rebase = mode
+	}
+
+	return cfg, nil
+}
+
+func parseRebaseMode(value string) (RebaseMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "merges", "m":
+		return RebaseMerges, nil
+	case "interactive", "i":
+		return RebaseInteractive, nil
+	}
+
+	rebase, err := parseBool(value)
+	if err != nil {
+		return RebaseUnset, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+	if rebase {
+		return RebaseTrue, nil
+	}
+	return RebaseFalse, nil
+}
+
+// GetPushConfig returns the push.* settings. Absent keys keep git's defaults.
+func (c *Config) GetPushConfig() (*PushConfig, error) {
+	cfg := &PushConfig{Default: "simple"}
+
+	r := &fieldReader{c: c}
+	readField(r, PushDefault, &cfg.Default)
+	readField(r, PushAutoSetupRemote, &cfg.AutoSetupRemote)
+	readField(r, PushFollowTags, &cfg.FollowTags)
+	readField(r, PushGPGSign, &cfg.GPGSign)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetCommitGraphConfig returns the commit-graph settings. Absent keys keep
+// git's defaults.
+func (c *Config) GetCommitGraphConfig() (*CommitGraphConfig, error) {
+	cfg := &CommitGraphConfig{
+		GenerationVersion: 2,
+		ReadChangedPaths:  true,
+		WriteDuringGC:     true,
+	}
+
+	r := &fieldReader{c: c}
+	readField(r, CommitGraphGenerationVersion, &cfg.GenerationVersion)
+	readField(r, CommitGraphReadChangedPaths, &cfg.ReadChangedPaths)
+	readField(r, FetchWriteCommitGraph, &cfg.WriteDuringFetch)
+	readField(r, GCWriteCommitGraph, &cfg.WriteDuringGC)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// ShouldPrune reports whether fetching from remote prunes stale
+// remote-tracking branches: remote.<name>.prune overrides fetch.prune, and
+// pruning is off when neither is set. Invalid values count as unset.
+func (c *Config) ShouldPrune(remote string) bool {
+	if prune, err := Get[bool](c, "remote."+remote+".prune"); err == nil {
+		return prune
+	}
+	return GetWithDefault(c, FetchPrune, false)
+}
+
+// GetInitConfig returns the init.* settings. Absent keys keep git's
+// defaults.
+func (c *Config) GetInitConfig() (*InitConfig, error) {
+	cfg := &InitConfig{DefaultBranch: DefaultBranchName}
+
+	r := &fieldReader{c: c}
+	readField(r, InitDefaultBranch, &cfg.DefaultBranch)
+	readPathField(r, InitTemplateDir, &cfg.TemplateDir)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	cfg.DefaultBranchExplicit = c.HasExplicit(InitDefaultBranch)
+
+	return cfg, nil
+}
+
+// GetDefaultBranch returns init.defaultBranch, falling back to "master". Use
+// GetInitConfig to tell whether the value was configured.
+func (c *Config) GetDefaultBranch() (string, error) {
+	cfg, err := c.GetInitConfig()
+	if err != nil {
+		return "", err
+	}
+	return cfg.DefaultBranch, nil
+}
+
+// GetGitFlowConfig returns the git-flow branch names and prefixes, or an
+// error wrapping ErrSectionNotFound if git flow init has not been run, that
+// is, neither gitflow subsection exists.
+func (c *Config) GetGitFlowConfig() (*GitFlowConfig, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	if !c.HasSection(GitFlowBranchSection) && !c.HasSection(GitFlowPrefixSection) {
+		return nil, &ConfigError{
+			Op:      "get",
+			Section: "gitflow",
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	cfg := &GitFlowConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, GitFlowBranchMaster, &cfg.MasterBranch)
+	readField(r, GitFlowBranchDevelop, &cfg.DevelopBranch)
+	readField(r, GitFlowPrefixFeature, &cfg.FeaturePrefix)
+	readField(r, GitFlowPrefixBugfix, &cfg.BugfixPrefix)
+	readField(r, GitFlowPrefixRelease, &cfg.ReleasePrefix)
+	readField(r, GitFlowPrefixHotfix, &cfg.HotfixPrefix)
+	readField(r, GitFlowPrefixSupport, &cfg.SupportPrefix)
+	readField(r, GitFlowPrefixVersionTag, &cfg.VersionTagPrefix)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetNamespaceConfig returns the namespace.* settings.
+func (c *Config) GetNamespaceConfig() (*NamespaceConfig, error) {
+	cfg := &NamespaceConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, NamespaceGit, &cfg.Namespace)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetEnvironmentOverrides returns the environment variables to set when
+// invoking git for this config: GIT_NAMESPACE from namespace.git,
+// GIT_OBJECT_DIRECTORY from core.objectsDirectory and
+// GIT_ALTERNATE_OBJECT_DIRECTORIES from every objects.alternates value,
+// joined with the path list separator. These keys are a convention of
+// server setups rather than settings git reads itself. Unset or empty keys
+// are left out, so a config without them yields an empty map.
+func (c *Config) GetEnvironmentOverrides() map[string]string {
+	env := make(map[string]string)
+
+	if namespace, ok := c.GetStringOK(NamespaceGit); ok && namespace != "" {
+		env["GIT_NAMESPACE"] = namespace
+	}
+	if objects, ok := c.GetStringOK(CoreObjectsDirectory); ok && objects != "" {
+		env["GIT_OBJECT_DIRECTORY"] = objects
+	}
+
+	var alternates []string
+	if values, err := c.GetMultiValue(ObjectsAlternates); err == nil {
+		for _, value := range values {
+			if value != "" {
+				alternates = append(alternates, value)
+			}
+		}
+	}
+	if len(alternates) > 0 {
+		env["GIT_ALTERNATE_OBJECT_DIRECTORIES"] = strings.Join(alternates, string(os.PathListSeparator))
+	}
+
+	return env
+}
@@ -0,0 +1,1292 @@
+package gitcfg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseTestConfig(t *testing.T, data string) *Config {
+	t.Helper()
+
+	config := &Config{
+		sections: make(map[string]map[string]string),
+	}
+	if err := newParser().parseConfigReader(strings.NewReader(data), config, ConfigSource{Path: "test"}); err != nil {
+		t.Fatalf("parseConfigReader failed: %v", err)
+	}
+	return config
+}
+
+func TestGetCommitConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := parseTestConfig(t, `[commit]
+    gpgSign = yes
+    template = ~/.gitmessage
+    cleanup = scissors
+    verbose = true
+`)
+
+	cfg, err := config.GetCommitConfig()
+	if err != nil {
+		t.Fatalf("GetCommitConfig failed: %v", err)
+	}
+	if !cfg.GPGSign {
+		t.Error("Expected GPGSign to be true")
+	}
+	if expected := filepath.Join(home, ".gitmessage"); cfg.Template != expected {
+		t.Errorf("Expected template '%s', got '%s'", expected, cfg.Template)
+	}
+	if cfg.Cleanup != "scissors" {
+		t.Errorf("Expected cleanup 'scissors', got '%s'", cfg.Cleanup)
+	}
+	if !cfg.Status {
+		t.Error("Expected Status to default to true")
+	}
+	if !cfg.Verbose {
+		t.Error("Expected Verbose to be true")
+	}
+}
+
+func TestGetCommitConfigDefaults(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+
+	cfg, err := config.GetCommitConfig()
+	if err != nil {
+		t.Fatalf("GetCommitConfig failed: %v", err)
+	}
+	if cfg.GPGSign {
+		t.Error("Expected GPGSign to default to false")
+	}
+	if cfg.Template != "" {
+		t.Errorf("Expected empty template, got '%s'", cfg.Template)
+	}
+}
+
+func TestGetCommitConfigInvalid(t *testing.T) {
+	config := parseTestConfig(t, "[commit]\n    gpgSign = maybe\n")
+
+	if _, err := config.GetCommitConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestGetCommitTemplate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	template := filepath.Join(home, ".gitmessage")
+	if err := os.WriteFile(template, []byte("Subject\n"), 0644); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	config := parseTestConfig(t, "[commit]\n    template = ~/.gitmessage\n")
+
+	path, err := config.GetCommitTemplate()
+	if err != nil {
+		t.Fatalf("GetCommitTemplate failed: %v", err)
+	}
+	if path != template {
+		t.Errorf("Expected '%s', got '%s'", template, path)
+	}
+}
+
+func TestGetCommitTemplateMissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	config := parseTestConfig(t, "[commit]\n    template = ~/missing-template\n")
+
+	_, err := config.GetCommitTemplate()
+	if err == nil {
+		t.Fatal("Expected error for missing template file")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected wrapped fs.ErrNotExist, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "missing-template") {
+		t.Errorf("Expected error to name the template path, got '%s'", err.Error())
+	}
+
+	empty := parseTestConfig(t, "[user]\n    name = Test User\n")
+	if _, err := empty.GetCommitTemplate(); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound for unset template, got %v", err)
+	}
+}
+
+func TestGetStatusConfig(t *testing.T) {
+	config := parseTestConfig(t, `[status]
+    short = true
+    branch = yes
+    aheadBehind = false
+    showStash = on
+    showUntrackedFiles = all
+    submoduleSummary = 5
+    renameLimit = 200
+`)
+
+	cfg, err := config.GetStatusConfig()
+	if err != nil {
+		t.Fatalf("GetStatusConfig failed: %v", err)
+	}
+
+	expected := StatusConfig{
+		Short:              true,
+		Branch:             true,
+		AheadBehind:        false,
+		ShowStash:          true,
+		ShowUntrackedFiles: "all",
+		SubmoduleSummary:   5,
+		RenameLimit:        200,
+	}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+}
+
+func TestGetStatusConfigDefaults(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+
+	cfg, err := config.GetStatusConfig()
+	if err != nil {
+		t.Fatalf("GetStatusConfig failed: %v", err)
+	}
+	if !cfg.AheadBehind {
+		t.Error("Expected AheadBehind to default to true")
+	}
+	if cfg.ShowUntrackedFiles != "normal" {
+		t.Errorf("Expected ShowUntrackedFiles to default to 'normal', got '%s'", cfg.ShowUntrackedFiles)
+	}
+	if cfg.SubmoduleSummary != 0 {
+		t.Errorf("Expected SubmoduleSummary to default to 0, got %d", cfg.SubmoduleSummary)
+	}
+}
+
+func TestGetStatusConfigSubmoduleSummary(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+		hasError bool
+	}{
+		{"true", -1, false},
+		{"yes", -1, false},
+		{"false", 0, false},
+		{"off", 0, false},
+		{"0", 0, false},
+		{"1", 1, false},
+		{"-1", -1, false},
+		{"10", 10, false},
+		{"", -1, false},
+		{"lots", 0, true},
+	}
+
+	for _, test := range tests {
+		config := parseTestConfig(t, "[status]\n    submoduleSummary = "+test.value+"\n")
+
+		cfg, err := config.GetStatusConfig()
+		if test.hasError {
+			if !errors.Is(err, ErrInvalidValue) {
+				t.Errorf("Expected ErrInvalidValue for %q, got %v", test.value, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.value, err)
+			continue
+		}
+		if cfg.SubmoduleSummary != test.expected {
+			t.Errorf("Expected %d for %q, got %d", test.expected, test.value, cfg.SubmoduleSummary)
+		}
+	}
+}
+
+func TestGetTagConfig(t *testing.T) {
+	config := parseTestConfig(t, `[tag]
+    gpgSign = true
+    sort = version:refname
+    forceSignAnnotated = false
+`)
+
+	cfg, err := config.GetTagConfig()
+	if err != nil {
+		t.Fatalf("GetTagConfig failed: %v", err)
+	}
+
+	expected := TagConfig{GPGSign: true, Sort: "version:refname"}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+}
+
+func TestGetNoteConfig(t *testing.T) {
+	config := parseTestConfig(t, `[notes]
+    displayRef = refs/notes/commits
+    displayRef = refs/notes/review
+    displayRef = refs/notes/ci/*
+    rewriteRef = refs/notes/commits
+[notes "rewrite"]
+    amend = true
+    rebase = false
+`)
+
+	cfg, err := config.GetNoteConfig()
+	if err != nil {
+		t.Fatalf("GetNoteConfig failed: %v", err)
+	}
+
+	expectedRefs := []string{"refs/notes/commits", "refs/notes/review", "refs/notes/ci/*"}
+	if !reflect.DeepEqual(cfg.DisplayRef, expectedRefs) {
+		t.Errorf("Expected display refs %v, got %v", expectedRefs, cfg.DisplayRef)
+	}
+	if cfg.RewriteRef != "refs/notes/commits" {
+		t.Errorf("Expected rewriteRef 'refs/notes/commits', got '%s'", cfg.RewriteRef)
+	}
+
+	expectedRewrite := map[string]string{"amend": "true", "rebase": "false"}
+	if !reflect.DeepEqual(cfg.Rewrite, expectedRewrite) {
+		t.Errorf("Expected rewrite %v, got %v", expectedRewrite, cfg.Rewrite)
+	}
+}
+
+func TestGetNoteConfigEmpty(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+
+	cfg, err := config.GetNoteConfig()
+	if err != nil {
+		t.Fatalf("GetNoteConfig failed: %v", err)
+	}
+	if len(cfg.DisplayRef) != 0 || len(cfg.Rewrite) != 0 || cfg.RewriteRef != "" {
+		t.Errorf("Expected empty NoteConfig, got %+v", cfg)
+	}
+}
+
+func TestGetAMConfig(t *testing.T) {
+	config := parseTestConfig(t, "[am]\n    keepcr = true\n    threeWay = yes\n")
+
+	cfg, err := config.GetAMConfig()
+	if err != nil {
+		t.Fatalf("GetAMConfig failed: %v", err)
+	}
+
+	expected := AMConfig{KeepCR: true, ThreeWay: true}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+}
+
+func TestGetAMAndApplyConfigAbsent(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+
+	am, err := config.GetAMConfig()
+	if err != nil {
+		t.Fatalf("GetAMConfig failed: %v", err)
+	}
+	if *am != (AMConfig{}) {
+		t.Errorf("Expected zero AMConfig, got %+v", *am)
+	}
+
+	apply, err := config.GetApplyConfig()
+	if err != nil {
+		t.Fatalf("GetApplyConfig failed: %v", err)
+	}
+	if *apply != (ApplyConfig{}) {
+		t.Errorf("Expected zero ApplyConfig, got %+v", *apply)
+	}
+	if apply.WhitespaceAction() != WhitespaceUnset {
+		t.Errorf("Expected WhitespaceUnset, got %v", apply.WhitespaceAction())
+	}
+}
+
+func TestGetApplyConfigWhitespace(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected WhitespaceAction
+	}{
+		{"nowarn", WhitespaceNoWarn},
+		{"warn", WhitespaceWarn},
+		{"fix", WhitespaceFix},
+		{"strip", WhitespaceFix},
+		{"error", WhitespaceError},
+		{"error-all", WhitespaceErrorAll},
+		{"Error-All", WhitespaceErrorAll},
+		{"bogus", WhitespaceUnknown},
+	}
+
+	for _, test := range tests {
+		config := parseTestConfig(t, "[apply]\n    whitespace = "+test.value+"\n    ignoreWhitespace = true\n    stat = true\n")
+
+		cfg, err := config.GetApplyConfig()
+		if err != nil {
+			t.Fatalf("GetApplyConfig failed: %v", err)
+		}
+		if cfg.Whitespace != test.value || !cfg.IgnoreWhitespace || !cfg.Stat {
+			t.Errorf("Unexpected ApplyConfig for %q: %+v", test.value, cfg)
+		}
+		if action := cfg.WhitespaceAction(); action != test.expected {
+			t.Errorf("Expected %v for %q, got %v", test.expected, test.value, action)
+		}
+	}
+}
+
+func TestGetSigningConfigDefaults(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+
+	cfg, err := config.GetSigningConfig()
+	if err != nil {
+		t.Fatalf("GetSigningConfig failed: %v", err)
+	}
+
+	expected := SigningConfig{Format: "openpgp", Program: "gpg", SSHProgram: "ssh-keygen"}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+	if cfg.IsSet(SigningCommitGPGSign) {
+		t.Error("Expected commit.gpgSign to be reported as absent")
+	}
+}
+
+func TestGetSigningConfigSSH(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory: %v", err)
+	}
+
+	config := parseTestConfig(t, `[commit]
+    gpgSign = off
+[tag]
+    gpgSign = yes
+[gpg]
+    format = ssh
+[gpg "ssh"]
+    program = /usr/local/bin/ssh-keygen
+    allowedSignersFile = ~/.ssh/allowed_signers
+    defaultKeyCommand = ssh-add -L
+[user]
+    signingKey = ~/.ssh/id_ed25519.pub
+`)
+
+	cfg, err := config.GetSigningConfig()
+	if err != nil {
+		t.Fatalf("GetSigningConfig failed: %v", err)
+	}
+
+	expected := SigningConfig{
+		CommitGPGSign:         false,
+		TagGPGSign:            true,
+		Format:                "ssh",
+		Program:               "gpg",
+		SSHProgram:            "/usr/local/bin/ssh-keygen",
+		SSHAllowedSignersFile: filepath.Join(home, ".ssh", "allowed_signers"),
+		SSHDefaultKeyCommand:  "ssh-add -L",
+		SigningKey:            "~/.ssh/id_ed25519.pub",
+		Set: SigningCommitGPGSign | SigningTagGPGSign | SigningFormat | SigningSSHProgram |
+			SigningSSHAllowedSignersFile | SigningSSHDefaultKeyCommand | SigningKey,
+	}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+
+	// An explicit false is distinguishable from an absent key
+	if !cfg.IsSet(SigningCommitGPGSign) || cfg.IsSet(SigningProgram) {
+		t.Errorf("Unexpected Set mask %b", cfg.Set)
+	}
+}
+
+func TestGetSigningConfigInvalid(t *testing.T) {
+	config := parseTestConfig(t, "[gpg]\n    format = pgp\n")
+	if _, err := config.GetSigningConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for unknown format, got %v", err)
+	}
+
+	config = parseTestConfig(t, "[commit]\n    gpgSign = maybe\n")
+	if _, err := config.GetSigningConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for invalid boolean, got %v", err)
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory: %v", err)
+	}
+	t.Setenv("GITCFG_TEST_DIR", "/tmp/gitcfg")
+
+	config := parseTestConfig(t, `[core]
+    excludesFile = ~/.gitignore_global
+    hooksPath = $GITCFG_TEST_DIR/hooks
+`)
+
+	path, err := config.GetPath("core.excludesfile")
+	if err != nil {
+		t.Fatalf("GetPath failed: %v", err)
+	}
+	if path != filepath.Join(home, ".gitignore_global") {
+		t.Errorf("Unexpected path %s", path)
+	}
+
+	path, err = config.GetPath("core.hookspath")
+	if err != nil {
+		t.Fatalf("GetPath failed: %v", err)
+	}
+	if path != "/tmp/gitcfg/hooks" {
+		t.Errorf("Unexpected path %s", path)
+	}
+
+	if _, err := config.GetPath("core.attributesfile"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestGetCredentialConfig(t *testing.T) {
+	config := parseTestConfig(t, `[credential]
+    helper = cache --timeout=3600
+    username = default-user
+[credential "https://github.com"]
+    helper =
+    helper = store
+    username = gh-user
+[credential "https://github.com/org"]
+    helper = manager
+    useHttpPath = true
+[credential "https://gitlab.com"]
+    helper = gitlab-helper
+`)
+
+	tests := []struct {
+		url      string
+		expected CredentialConfig
+	}{
+		{"", CredentialConfig{Helpers: []string{"cache --timeout=3600"}, Username: "default-user"}},
+		{"https://example.com/repo.git", CredentialConfig{Helpers: []string{"cache --timeout=3600"}, Username: "default-user"}},
+		{"https://github.com/user/repo.git", CredentialConfig{Helpers: []string{"store"}, Username: "gh-user"}},
+		{"https://github.com/org/repo.git", CredentialConfig{Helpers: []string{"store", "manager"}, Username: "gh-user", UseHTTPPath: true}},
+		{"https://gitlab.com/repo.git", CredentialConfig{Helpers: []string{"cache --timeout=3600", "gitlab-helper"}, Username: "default-user"}},
+	}
+
+	for _, test := range tests {
+		cfg, err := config.GetCredentialConfig(test.url)
+		if err != nil {
+			t.Fatalf("GetCredentialConfig(%q) failed: %v", test.url, err)
+		}
+		if !reflect.DeepEqual(*cfg, test.expected) {
+			t.Errorf("GetCredentialConfig(%q) = %+v, expected %+v", test.url, *cfg, test.expected)
+		}
+	}
+}
+
+func TestGetCredentialConfigLoadOrder(t *testing.T) {
+	global := ConfigSource{Type: SourceTypeGlobal, Path: "global"}
+	local := ConfigSource{Type: SourceTypeLocal, Path: "local"}
+
+	config := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  []ConfigSource{global, local},
+	}
+	scopes := []struct {
+		source ConfigSource
+		data   string
+	}{
+		{global, "[credential \"https://github.com\"]\n    helper = gh\n[credential]\n    helper = osxkeychain\n"},
+		{local, "[credential]\n    helper =\n    helper = store\n"},
+	}
+	for _, scope := range scopes {
+		if err := newParser().parseConfigReader(strings.NewReader(scope.data), config, scope.source); err != nil {
+			t.Fatalf("parseConfigReader failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		url     string
+		helpers []string
+	}{
+		{"https://github.com/org/repo.git", []string{"store"}},
+		{"https://example.com/repo.git", []string{"store"}},
+	}
+	for _, test := range tests {
+		cfg, err := config.GetCredentialConfig(test.url)
+		if err != nil {
+			t.Fatalf("GetCredentialConfig(%q) failed: %v", test.url, err)
+		}
+		if !reflect.DeepEqual(cfg.Helpers, test.helpers) {
+			t.Errorf("GetCredentialConfig(%q).Helpers = %q, expected %q", test.url, cfg.Helpers, test.helpers)
+		}
+	}
+
+	// Within a file the generic helper after the URL section still comes last
+	config = parseTestConfig(t, "[credential \"https://github.com\"]\n    helper = gh\n[credential]\n    helper = cache\n")
+	cfg, err := config.GetCredentialConfig("https://github.com/repo.git")
+	if err != nil {
+		t.Fatalf("GetCredentialConfig failed: %v", err)
+	}
+	if expected := []string{"gh", "cache"}; !reflect.DeepEqual(cfg.Helpers, expected) {
+		t.Errorf("Helpers = %q, expected %q", cfg.Helpers, expected)
+	}
+}
+
+func TestGetCredentialConfigInvalid(t *testing.T) {
+	config := parseTestConfig(t, "[credential \"https://github.com\"]\n    useHttpPath = sometimes\n")
+	if _, err := config.GetCredentialConfig("https://github.com/repo.git"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestGetFilterDriver(t *testing.T) {
+	config := parseTestConfig(t, `[filter "lfs"]
+    clean = git-lfs clean -- %f
+    smudge = git-lfs smudge -- %f
+    process = git-lfs filter-process
+    required = true
+[filter "crypt"]
+    clean = crypt encrypt
+`)
+
+	lfs, err := config.GetFilterDriver("lfs")
+	if err != nil {
+		t.Fatalf("GetFilterDriver failed: %v", err)
+	}
+	expected := FilterDriver{
+		Name:     "lfs",
+		Clean:    "git-lfs clean -- %f",
+		Smudge:   "git-lfs smudge -- %f",
+		Process:  "git-lfs filter-process",
+		Required: true,
+	}
+	if *lfs != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *lfs)
+	}
+
+	crypt, err := config.GetFilterDriver("crypt")
+	if err != nil {
+		t.Fatalf("GetFilterDriver failed: %v", err)
+	}
+	if crypt.Required || crypt.Smudge != "" {
+		t.Errorf("Unexpected driver %+v", crypt)
+	}
+
+	if _, err := config.GetFilterDriver("missing"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+}
+
+func TestGetLFSConfig(t *testing.T) {
+	config := parseTestConfig(t, `[filter "lfs"]
+    clean = git-lfs clean -- %f
+    smudge = git-lfs smudge -- %f
+    required = yes
+[lfs]
+    url = https://lfs.example.com/repo
+    concurrenttransfers = 3
+    locksverify = true
+`)
+
+	cfg, err := config.GetLFSConfig()
+	if err != nil {
+		t.Fatalf("GetLFSConfig failed: %v", err)
+	}
+	if !cfg.Filter.Required || cfg.Filter.Clean != "git-lfs clean -- %f" {
+		t.Errorf("Unexpected filter %+v", cfg.Filter)
+	}
+	if cfg.URL != "https://lfs.example.com/repo" || cfg.ConcurrentTransfers != 3 || !cfg.LocksVerify {
+		t.Errorf("Unexpected LFS config %+v", cfg)
+	}
+
+	defaults, err := parseTestConfig(t, "[filter \"lfs\"]\n    process = git-lfs filter-process\n").GetLFSConfig()
+	if err != nil {
+		t.Fatalf("GetLFSConfig failed: %v", err)
+	}
+	if defaults.ConcurrentTransfers != 8 {
+		t.Errorf("Expected default of 8 transfers, got %d", defaults.ConcurrentTransfers)
+	}
+
+	if _, err := parseTestConfig(t, "[lfs]\n    url = https://lfs.example.com\n").GetLFSConfig(); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound without filter.lfs, got %v", err)
+	}
+
+	if _, err := parseTestConfig(t, "[filter \"lfs\"]\n    required = maybe\n").GetLFSConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestGetLFSConfigExtensions(t *testing.T) {
+	config := parseTestConfig(t, `[filter "lfs"]
+    process = git-lfs filter-process
+[lfs]
+    batch = false
+    concurrenttransfers = 1k
+    tls = true
+    dialtimeout = 10
+    tlstimeout = 1m30s
+    activitytimeout = 45
+    sslnoverify = yes
+[lfs "objectstorageurl"]
+    url = https://objects.example.com/bucket
+`)
+
+	cfg, err := config.GetLFSConfig()
+	if err != nil {
+		t.Fatalf("GetLFSConfig failed: %v", err)
+	}
+	if cfg.ConcurrentTransfers != 1024 {
+		t.Errorf("Expected 1k to mean 1024 transfers, got %d", cfg.ConcurrentTransfers)
+	}
+	if cfg.TLSTimeout != 90*time.Second {
+		t.Errorf("Expected TLSTimeout of 1m30s, got %v", cfg.TLSTimeout)
+	}
+	if cfg.DialTimeout != 10*time.Second || cfg.ActivityTimeout != 45*time.Second || cfg.KeepAlive != 30*time.Minute {
+		t.Errorf("Unexpected timeouts %+v", cfg)
+	}
+	if cfg.Batch || !cfg.TLS || !cfg.SSLNoVerify {
+		t.Errorf("Unexpected flags %+v", cfg)
+	}
+	if cfg.ObjectStorageURL != "https://objects.example.com/bucket" {
+		t.Errorf("Unexpected object storage URL %q", cfg.ObjectStorageURL)
+	}
+
+	for _, bad := range []string{"concurrenttransfers = lots", "tlstimeout = soon"} {
+		config := parseTestConfig(t, "[filter \"lfs\"]\n    process = git-lfs filter-process\n[lfs]\n    "+bad+"\n")
+		if _, err := config.GetLFSConfig(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("%s: expected ErrInvalidValue, got %v", bad, err)
+		}
+	}
+}
+
+func TestGetMergeAndDiffConfig(t *testing.T) {
+	config := parseTestConfig(t, `[merge]
+    tool = vimdiff
+    conflictStyle = zdiff3
+    ff = only
+[diff]
+    tool = meld
+    algorithm = histogram
+    renames = copies
+    colorMoved = zebra
+`)
+
+	merge, err := config.GetMergeConfig()
+	if err != nil {
+		t.Fatalf("GetMergeConfig failed: %v", err)
+	}
+	if expected := (MergeConfig{Tool: "vimdiff", ConflictStyle: "zdiff3", FF: "only"}); *merge != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *merge)
+	}
+
+	diff, err := config.GetDiffConfig()
+	if err != nil {
+		t.Fatalf("GetDiffConfig failed: %v", err)
+	}
+	if expected := (DiffConfig{Tool: "meld", Algorithm: "histogram", Renames: "copies", ColorMoved: "zebra"}); *diff != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *diff)
+	}
+}
+
+func TestGetMergeAndDiffConfigAbsent(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+
+	merge, err := config.GetMergeConfig()
+	if err != nil || *merge != (MergeConfig{}) {
+		t.Errorf("Expected zero MergeConfig, got %+v (%v)", merge, err)
+	}
+	diff, err := config.GetDiffConfig()
+	if err != nil || *diff != (DiffConfig{}) {
+		t.Errorf("Expected zero DiffConfig, got %+v (%v)", diff, err)
+	}
+}
+
+func TestGetMergeAndDiffConfigInvalid(t *testing.T) {
+	if _, err := parseTestConfig(t, "[merge]\n    conflictStyle = diff4\n").GetMergeConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for conflictStyle, got %v", err)
+	}
+	if _, err := parseTestConfig(t, "[diff]\n    algorithm = fastest\n").GetDiffConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for algorithm, got %v", err)
+	}
+}
+
+func TestGetToolCommand(t *testing.T) {
+	config := parseTestConfig(t, `[mergetool "kdiff3"]
+    cmd = kdiff3 "$BASE" "$LOCAL" "$REMOTE" -o "$MERGED"
+    trustExitCode = true
+[mergetool]
+    prompt = false
+[difftool]
+    trustExitCode = true
+[difftool "meld"]
+    cmd = meld "$LOCAL" "$REMOTE"
+`)
+
+	merge, err := config.GetToolCommand("merge", "kdiff3")
+	if err != nil {
+		t.Fatalf("GetToolCommand failed: %v", err)
+	}
+	expected := ToolCommand{Name: "kdiff3", Cmd: `kdiff3 "$BASE" "$LOCAL" "$REMOTE" -o "$MERGED"`, TrustExitCode: true}
+	if *merge != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *merge)
+	}
+
+	diff, err := config.GetToolCommand("diff", "meld")
+	if err != nil {
+		t.Fatalf("GetToolCommand failed: %v", err)
+	}
+	if expected := (ToolCommand{Name: "meld", Cmd: `meld "$LOCAL" "$REMOTE"`, TrustExitCode: true, Prompt: true}); *diff != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *diff)
+	}
+
+	builtin, err := config.GetToolCommand("merge", "vimdiff")
+	if err != nil {
+		t.Fatalf("GetToolCommand failed: %v", err)
+	}
+	if builtin.Cmd != "" || builtin.TrustExitCode {
+		t.Errorf("Expected empty command for builtin tool, got %+v", builtin)
+	}
+
+	if _, err := config.GetToolCommand("rebase", "kdiff3"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for unknown kind, got %v", err)
+	}
+}
+
+func TestGetFetchConfig(t *testing.T) {
+	config := parseTestConfig(t, `[fetch]
+    prune = true
+    pruneTags = yes
+    parallel = 4
+    recurseSubmodules = on-demand
+`)
+
+	cfg, err := config.GetFetchConfig()
+	if err != nil {
+		t.Fatalf("GetFetchConfig failed: %v", err)
+	}
+	expected := FetchConfig{Prune: true, PruneTags: true, Parallel: 4, RecurseSubmodules: "on-demand"}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+
+	defaults, err := parseTestConfig(t, "[user]\n    name = Test User\n").GetFetchConfig()
+	if err != nil {
+		t.Fatalf("GetFetchConfig failed: %v", err)
+	}
+	if *defaults != (FetchConfig{Parallel: 1}) {
+		t.Errorf("Unexpected defaults %+v", *defaults)
+	}
+}
+
+func TestGetPullConfig(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected RebaseMode
+		hasError bool
+	}{
+		{"[user]\n    name = Test User\n", RebaseUnset, false},
+		{"[pull]\n    rebase = false\n", RebaseFalse, false},
+		{"[pull]\n    rebase = true\n", RebaseTrue, false},
+		{"[pull]\n    rebase = yes\n", RebaseTrue, false},
+		{"[pull]\n    rebase = merges\n", RebaseMerges, false},
+		{"[pull]\n    rebase = i\n", RebaseInteractive, false},
+		{"[pull]\n    ff = only\n", RebaseUnset, false},
+		{"[pull]\n    rebase = sometimes\n", RebaseUnset, true},
+	}
+
+	for _, test := range tests {
+		cfg, err := parseTestConfig(t, test.data).GetPullConfig()
+		if test.hasError {
+			if !errors.Is(err, ErrInvalidValue) {
+				t.Errorf("Expected ErrInvalidValue for %q, got %v", test.data, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("GetPullConfig failed for %q: %v", test.data, err)
+		}
+		if cfg.Rebase != test.expected {
+			t.Errorf("Expected %v for %q, got %v", test.expected, test.data, cfg.Rebase)
+		}
+	}
+}
+
+func TestGetPushConfig(t *testing.T) {
+	config := parseTestConfig(t, `[push]
+    default = current
+    autoSetupRemote = true
+    followTags = true
+    gpgSign = if-asked
+`)
+
+	cfg, err := config.GetPushConfig()
+	if err != nil {
+		t.Fatalf("GetPushConfig failed: %v", err)
+	}
+	expected := PushConfig{Default: "current", AutoSetupRemote: true, FollowTags: true, GPGSign: "if-asked"}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+
+	defaults, err := parseTestConfig(t, "[user]\n    name = Test User\n").GetPushConfig()
+	if err != nil {
+		t.Fatalf("GetPushConfig failed: %v", err)
+	}
+	if defaults.Default != "simple" {
+		t.Errorf("Expected push.default to default to 'simple', got '%s'", defaults.Default)
+	}
+}
+
+func TestShouldPrune(t *testing.T) {
+	config := parseTestConfig(t, `[fetch]
+    prune = true
+[remote "origin"]
+    url = https://github.com/user/repo.git
+[remote "keep"]
+    url = https://github.com/user/keep.git
+    prune = false
+`)
+
+	if !config.ShouldPrune("origin") {
+		t.Error("Expected origin to inherit fetch.prune")
+	}
+	if config.ShouldPrune("keep") {
+		t.Error("Expected remote.keep.prune to override fetch.prune")
+	}
+
+	empty := parseTestConfig(t, "[remote \"origin\"]\n    prune = true\n")
+	if !empty.ShouldPrune("origin") {
+		t.Error("Expected remote.origin.prune to enable pruning")
+	}
+	if empty.ShouldPrune("other") {
+		t.Error("Expected pruning to be off by default")
+	}
+}
+
+func TestGetInitConfig(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	config := parseTestConfig(t, `[init]
+    defaultBranch = main
+    templateDir = $XDG_CONFIG_HOME/git/template
+`)
+
+	cfg, err := config.GetInitConfig()
+	if err != nil {
+		t.Fatalf("GetInitConfig failed: %v", err)
+	}
+	expected := InitConfig{
+		DefaultBranch:         "main",
+		DefaultBranchExplicit: true,
+		TemplateDir:           filepath.Join(xdg, "git", "template"),
+	}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+
+	branch, err := config.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch failed: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("Expected 'main', got '%s'", branch)
+	}
+}
+
+func TestGetInitConfigUnset(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+
+	cfg, err := config.GetInitConfig()
+	if err != nil {
+		t.Fatalf("GetInitConfig failed: %v", err)
+	}
+	if *cfg != (InitConfig{DefaultBranch: "master"}) {
+		t.Errorf("Unexpected defaults %+v", *cfg)
+	}
+
+	branch, err := config.GetDefaultBranch()
+	if err != nil {
+		t.Fatalf("GetDefaultBranch failed: %v", err)
+	}
+	if branch != DefaultBranchName {
+		t.Errorf("Expected '%s', got '%s'", DefaultBranchName, branch)
+	}
+}
+
+func TestGetAdviceConfig(t *testing.T) {
+	config := parseTestConfig(t, `[advice]
+    detachedHead = false
+    statusHints = off
+    pushUpdateRejected =
+    someFutureHint = false`)
+
+	advice, err := config.GetAdviceConfig()
+	if err != nil {
+		t.Fatalf("GetAdviceConfig() error = %v", err)
+	}
+	if advice.DetachedHead || advice.StatusHints {
+		t.Errorf("expected detachedHead and statusHints disabled, got %+v", advice)
+	}
+	if !advice.PushUpdateRejected {
+		t.Error("an empty value should enable the hint")
+	}
+	if !advice.ResolveConflict {
+		t.Error("unconfigured hints should default to enabled")
+	}
+	if enabled, ok := advice.Extra["somefuturehint"]; !ok || enabled {
+		t.Errorf("Extra[somefuturehint] = %v, %v, want false, true", enabled, ok)
+	}
+	if advice.Enabled("someFutureHint") || advice.Enabled("detachedHead") {
+		t.Error("Enabled should report disabled hints")
+	}
+	if !advice.Enabled("waitingForEditor") {
+		t.Error("Enabled should default to true for unknown hints")
+	}
+
+	invalid := parseTestConfig(t, "[advice]\n    detachedHead = maybe\n")
+	if _, err := invalid.GetAdviceConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("GetAdviceConfig() error = %v, want ErrInvalidValue", err)
+	}
+}
+
+func TestGetMaintenanceConfig(t *testing.T) {
+	config := parseTestConfig(t, `[maintenance]
+    auto = false
+    strategy = incremental
+    repo = /src/one
+    repo = /src/two`)
+
+	cfg, err := config.GetMaintenanceConfig()
+	if err != nil {
+		t.Fatalf("GetMaintenanceConfig() error = %v", err)
+	}
+	if cfg.Auto || cfg.Strategy != "incremental" {
+		t.Errorf("GetMaintenanceConfig() = %+v", cfg)
+	}
+	if want := []string{"/src/one", "/src/two"}; !reflect.DeepEqual(cfg.Repos, want) {
+		t.Errorf("Repos = %v, want %v", cfg.Repos, want)
+	}
+
+	defaults, err := parseTestConfig(t, "[user]\n    name = x\n").GetMaintenanceConfig()
+	if err != nil {
+		t.Fatalf("GetMaintenanceConfig() error = %v", err)
+	}
+	if !defaults.Auto || len(defaults.Repos) != 0 {
+		t.Errorf("defaults = %+v", defaults)
+	}
+}
+
+func TestGetHTTPForURL(t *testing.T) {
+	config := parseTestConfig(t, `[http]
+    sslVerify = true
+    postBuffer = 1048576
+    extraHeader = X-Global: 1
+[http "https://example.com/"]
+    sslVerify = false
+    extraHeader =
+    extraHeader = X-Example: 1
+[http "https://example.com/team/"]
+    proxy = http://proxy.example.com:3128
+[http "https://*.example.org"]
+    userAgent = custom`)
+
+	tests := []struct {
+		url       string
+		sslVerify bool
+		proxy     string
+		userAgent string
+		headers   []string
+	}{
+		{"https://example.com/repo.git", false, "", "", []string{"X-Example: 1"}},
+		{"https://example.com/team/repo.git", false, "http://proxy.example.com:3128", "", []string{"X-Example: 1"}},
+		{"https://other.com/repo.git", true, "", "", []string{"X-Global: 1"}},
+		{"http://example.com/repo.git", true, "", "", []string{"X-Global: 1"}},
+		{"https://git.example.org/repo.git", true, "", "custom", []string{"X-Global: 1"}},
+		{"", true, "", "", []string{"X-Global: 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			cfg, err := config.GetHTTPForURL(tt.url)
+			if err != nil {
+				t.Fatalf("GetHTTPForURL() error = %v", err)
+			}
+			if cfg.SSLVerify != tt.sslVerify {
+				t.Errorf("SSLVerify = %v, want %v", cfg.SSLVerify, tt.sslVerify)
+			}
+			if cfg.Proxy != tt.proxy {
+				t.Errorf("Proxy = %q, want %q", cfg.Proxy, tt.proxy)
+			}
+			if cfg.UserAgent != tt.userAgent {
+				t.Errorf("UserAgent = %q, want %q", cfg.UserAgent, tt.userAgent)
+			}
+			if cfg.PostBuffer != 1048576 {
+				t.Errorf("PostBuffer = %d, want 1048576", cfg.PostBuffer)
+			}
+			if !reflect.DeepEqual(cfg.ExtraHeaders, tt.headers) {
+				t.Errorf("ExtraHeaders = %v, want %v", cfg.ExtraHeaders, tt.headers)
+			}
+		})
+	}
+
+	if _, err := config.GetHTTPForURL("https://exa mple.com/%zz"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("GetHTTPForURL() error = %v, want ErrInvalidValue", err)
+	}
+}
+
+func TestGetHTTPForURLByteSizes(t *testing.T) {
+	config := parseTestConfig(t, `[http]
+    postBuffer = 512m
+    lowSpeedLimit = 1k
+    lowSpeedTime = 60
+[http "https://example.com/"]
+    proxy = http://proxy.example.com:3128
+    postBuffer = 1g`)
+
+	cfg, err := config.GetHTTPForURL("https://other.com/repo.git")
+	if err != nil {
+		t.Fatalf("GetHTTPForURL() error = %v", err)
+	}
+	if cfg.PostBuffer != 512<<20 || cfg.LowSpeedLimit != 1024 || cfg.LowSpeedTime != 60 {
+		t.Errorf("GetHTTPForURL() = %d, %d, %d, want %d, 1024, 60", cfg.PostBuffer, cfg.LowSpeedLimit, cfg.LowSpeedTime, 512<<20)
+	}
+
+	cfg, err = config.GetHTTPForURL("https://example.com/repo.git")
+	if err != nil {
+		t.Fatalf("GetHTTPForURL() error = %v", err)
+	}
+	if cfg.PostBuffer != 1<<30 {
+		t.Errorf("PostBuffer = %d, want %d", cfg.PostBuffer, 1<<30)
+	}
+
+	if proxy, err := config.GetEffectiveProxy("https://example.com/repo.git"); err != nil || proxy != "http://proxy.example.com:3128" {
+		t.Errorf("GetEffectiveProxy() = %q, %v, want the configured proxy", proxy, err)
+	}
+}
+
+func TestGetCommitGraphConfig(t *testing.T) {
+	config := parseTestConfig(t, `[commitGraph]
+    generationVersion = 1
+[fetch]
+    writeCommitGraph = false
+[gc]
+    writeCommitGraph = true`)
+
+	cfg, err := config.GetCommitGraphConfig()
+	if err != nil {
+		t.Fatalf("GetCommitGraphConfig() error = %v", err)
+	}
+	want := CommitGraphConfig{
+		GenerationVersion: 1,
+		ReadChangedPaths:  true,
+		WriteDuringFetch:  false,
+		WriteDuringGC:     true,
+	}
+	if *cfg != want {
+		t.Errorf("GetCommitGraphConfig() = %+v, want %+v", *cfg, want)
+	}
+
+	config = parseTestConfig(t, "[fetch]\n    writeCommitGraph =\n[gc]\n    writeCommitGraph = false\n")
+	cfg, err = config.GetCommitGraphConfig()
+	if err != nil {
+		t.Fatalf("GetCommitGraphConfig() error = %v", err)
+	}
+	if !cfg.WriteDuringFetch || cfg.WriteDuringGC || cfg.GenerationVersion != 2 {
+		t.Errorf("GetCommitGraphConfig() = %+v", *cfg)
+	}
+}
+
+const sendEmailTestConfig = `[sendemail]
+    smtpServer = smtp.example.com
+    smtpUser = base
+    smtpServerPort = 587
+    smtpEncryption = tls
+    from = Base <base@example.com>
+    to = list@example.com
+    cc = one@example.com
+    cc = two@example.com
+    identity = work
+[sendemail "work"]
+    smtpUser = worker
+    from = Worker <worker@corp.example.com>
+    cc = team@corp.example.com
+[sendemail "home"]
+    smtpServer = mail.home.example
+    smtpServerPort = 465
+    smtpEncryption = ssl`
+
+func TestGetSendEmailConfig(t *testing.T) {
+	config := parseTestConfig(t, sendEmailTestConfig)
+
+	cfg, err := config.GetSendEmailConfig("home")
+	if err != nil {
+		t.Fatalf("GetSendEmailConfig() error = %v", err)
+	}
+	want := &SendEmailConfig{
+		Identity:       "home",
+		SMTPServer:     "mail.home.example",
+		SMTPUser:       "base",
+		SMTPEncryption: "ssl",
+		SMTPServerPort: 465,
+		From:           "Base <base@example.com>",
+		To:             []string{"list@example.com"},
+		Cc:             []string{"one@example.com", "two@example.com"},
+		Bcc:            []string{},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("GetSendEmailConfig(home) = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestGetSendEmailConfigIdentityPrecedence(t *testing.T) {
+	config := parseTestConfig(t, sendEmailTestConfig)
+
+	// sendemail.identity selects "work" when no identity is given
+	cfg, err := config.GetSendEmailConfig("")
+	if err != nil {
+		t.Fatalf("GetSendEmailConfig() error = %v", err)
+	}
+	if cfg.Identity != "work" {
+		t.Errorf("Identity = %q, want work", cfg.Identity)
+	}
+	if cfg.SMTPUser != "worker" || cfg.From != "Worker <worker@corp.example.com>" {
+		t.Errorf("identity values should override the base section, got %+v", cfg)
+	}
+	if cfg.SMTPServer != "smtp.example.com" || cfg.SMTPServerPort != 587 {
+		t.Errorf("unset identity values should fall back to the base section, got %+v", cfg)
+	}
+	if want := []string{"team@corp.example.com"}; !reflect.DeepEqual(cfg.Cc, want) {
+		t.Errorf("Cc = %v, want the identity list %v to replace the base list", cfg.Cc, want)
+	}
+	if want := []string{"list@example.com"}; !reflect.DeepEqual(cfg.To, want) {
+		t.Errorf("To = %v, want %v", cfg.To, want)
+	}
+
+	// An explicit identity wins over sendemail.identity
+	cfg, err = config.GetSendEmailConfig("home")
+	if err != nil {
+		t.Fatalf("GetSendEmailConfig() error = %v", err)
+	}
+	if cfg.SMTPUser != "base" {
+		t.Errorf("SMTPUser = %q, want base", cfg.SMTPUser)
+	}
+
+	if _, err := config.GetSendEmailConfig("missing"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("GetSendEmailConfig(missing) error = %v, want ErrSectionNotFound", err)
+	}
+}
+
+func TestGetSendEmailConfigInvalid(t *testing.T) {
+	config := parseTestConfig(t, "[sendemail]\n    smtpServerPort = submission\n")
+	_, err := config.GetSendEmailConfig("")
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("GetSendEmailConfig() error = %v, want ErrInvalidValue", err)
+	}
+	if !strings.Contains(err.Error(), "smtpserverport") {
+		t.Errorf("error %q should name the key", err)
+	}
+
+	// git send-email reads any other encryption as plain SMTP
+	config = parseTestConfig(t, "[sendemail]\n    smtpEncryption = none\n")
+	if cfg, err := config.GetSendEmailConfig(""); err != nil || cfg.SMTPEncryption != "none" {
+		t.Errorf("GetSendEmailConfig() = %+v, %v, want SMTPEncryption none", cfg, err)
+	}
+}
+
+func TestGetGitFlowConfig(t *testing.T) {
+	config := parseTestConfig(t, `[gitflow "branch"]
+    master = main
+    develop = develop
+[gitflow "prefix"]
+    feature = feature/
+    bugfix = bugfix/
+    release = release/
+    hotfix = hotfix/
+    support = support/
+    versiontag = v`)
+
+	cfg, err := config.GetGitFlowConfig()
+	if err != nil {
+		t.Fatalf("GetGitFlowConfig() error = %v", err)
+	}
+	want := GitFlowConfig{
+		MasterBranch:     "main",
+		DevelopBranch:    "develop",
+		FeaturePrefix:    "feature/",
+		BugfixPrefix:     "bugfix/",
+		ReleasePrefix:    "release/",
+		HotfixPrefix:     "hotfix/",
+		SupportPrefix:    "support/",
+		VersionTagPrefix: "v",
+	}
+	if *cfg != want {
+		t.Errorf("GetGitFlowConfig() = %+v, want %+v", *cfg, want)
+	}
+
+	config = parseTestConfig(t, "[core]\n    editor = vim\n")
+	if _, err := config.GetGitFlowConfig(); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+}
+
+func TestGetBytesAndDuration(t *testing.T) {
+	config := parseTestConfig(t, "[http]\n    postBuffer = 512m\n    lowSpeedTime = 30\n[lfs]\n    dialTimeout = 1m30s\n    batch = yes\n")
+
+	if n, err := config.GetBytes("http.postBuffer"); err != nil || n != 512<<20 {
+		t.Errorf("GetBytes(http.postBuffer) = %d, %v, want %d", n, err, 512<<20)
+	}
+	if d, err := config.GetDuration("http.lowSpeedTime"); err != nil || d != 30*time.Second {
+		t.Errorf("GetDuration(http.lowSpeedTime) = %v, %v, want 30s", d, err)
+	}
+	if d, err := config.GetDuration("lfs.dialTimeout"); err != nil || d != 90*time.Second {
+		t.Errorf("GetDuration(lfs.dialTimeout) = %v, %v, want 1m30s", d, err)
+	}
+	if _, err := config.GetBytes("lfs.batch"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("GetBytes(lfs.batch) error = %v, want ErrInvalidValue", err)
+	}
+	if _, err := config.GetDuration("http.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetDuration(http.missing) error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestGetNamespaceConfig(t *testing.T) {
+	config := parseTestConfig(t, "[namespace]\n    git = tenant-a\n")
+
+	cfg, err := config.GetNamespaceConfig()
+	if err != nil {
+		t.Fatalf("GetNamespaceConfig failed: %v", err)
+	}
+	if cfg.Namespace != "tenant-a" {
+		t.Errorf("Expected 'tenant-a', got '%s'", cfg.Namespace)
+	}
+
+	cfg, err = parseTestConfig(t, "").GetNamespaceConfig()
+	if err != nil || cfg.Namespace != "" {
+		t.Errorf("Expected an empty namespace, got %+v, %v", cfg, err)
+	}
+}
+
+func TestGetEnvironmentOverrides(t *testing.T) {
+	config := parseTestConfig(t, "[core]\n    objectsDirectory = /objects\n")
+	expected := map[string]string{"GIT_OBJECT_DIRECTORY": "/objects"}
+	if env := config.GetEnvironmentOverrides(); !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+
+	config = parseTestConfig(t, `[namespace]
+    git = tenant-a
+[objects]
+    alternates = /shared/objects
+    alternates =
+    alternates = /cache/objects
+`)
+	expected = map[string]string{
+		"GIT_NAMESPACE":                    "tenant-a",
+		"GIT_ALTERNATE_OBJECT_DIRECTORIES": "/shared/objects" + string(os.PathListSeparator) + "/cache/objects",
+	}
+	if env := config.GetEnvironmentOverrides(); !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+
+	if env := parseTestConfig(t, "").GetEnvironmentOverrides(); env == nil || len(env) != 0 {
+		t.Errorf("Expected an empty map, got %v", env)
+	}
+}
@@ -0,0 +1,82 @@
+package gitcfg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GetAlternatesConfig returns the alternate object directories: those of
+// every objects.alternates value, each a list separated like PATH, followed
+// by the lines of objects/info/alternates in the git directory when a local
+// config is loaded. Relative paths in that file are resolved against the
+// objects directory, as git does. Paths are returned in order, each once; a
+// missing file contributes none.
+func (c *Config) GetAlternatesConfig() (*AlternatesConfig, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	cfg := &AlternatesConfig{Paths: make([]string, 0)}
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			cfg.Paths = append(cfg.Paths, path)
+		}
+	}
+
+	values, err := c.GetMultiValue(ObjectsAlternates)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+		return nil, err
+	}
+	for _, value := range values {
+		for _, path := range filepath.SplitList(value) {
+			add(path)
+		}
+	}
+
+	gitDir := c.gitDir()
+	if gitDir == "" {
+		return cfg, nil
+	}
+
+	objectsDir := filepath.Join(gitDir, "objects")
+	path := filepath.Join(objectsDir, "info", "alternates")
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    err,
+		}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(objectsDir, line)
+		}
+		add(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    fmt.Errorf("failed to read alternates: %w", err),
+		}
+	}
+
+	return cfg, nil
+}
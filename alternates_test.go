@@ -0,0 +1,60 @@
+package gitcfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetAlternatesConfig(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	config := parseTestConfig(t, `[objects]
+    alternates = /cache/a`+sep+`/cache/b
+    alternates = /shared/objects
+    alternates = /cache/a
+`)
+
+	cfg, err := config.GetAlternatesConfig()
+	if err != nil {
+		t.Fatalf("GetAlternatesConfig failed: %v", err)
+	}
+	expected := []string{"/cache/a", "/cache/b", "/shared/objects"}
+	if !reflect.DeepEqual(cfg.Paths, expected) {
+		t.Errorf("Expected %q, got %q", expected, cfg.Paths)
+	}
+
+	// With a local config the info file is read too
+	gitDir := t.TempDir()
+	config.sources = []ConfigSource{{Type: SourceTypeLocal, Path: filepath.Join(gitDir, "config")}}
+	if cfg, err := config.GetAlternatesConfig(); err != nil || !reflect.DeepEqual(cfg.Paths, expected) {
+		t.Errorf("Without an alternates file expected %q, got %v, %v", expected, cfg, err)
+	}
+
+	infoDir := filepath.Join(gitDir, "objects", "info")
+	if err := os.MkdirAll(infoDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "# reference repository\n/shared/objects\n\n../../reference/.git/objects\r\n/mirror/objects\n"
+	if err := os.WriteFile(filepath.Join(infoDir, "alternates"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = config.GetAlternatesConfig()
+	if err != nil {
+		t.Fatalf("GetAlternatesConfig failed: %v", err)
+	}
+	expected = []string{
+		"/cache/a", "/cache/b", "/shared/objects",
+		filepath.Join(gitDir, "objects", "../../reference/.git/objects"),
+		"/mirror/objects",
+	}
+	if !reflect.DeepEqual(cfg.Paths, expected) {
+		t.Errorf("Expected %q, got %q", expected, cfg.Paths)
+	}
+
+	cfg, err = parseTestConfig(t, "").GetAlternatesConfig()
+	if err != nil || cfg.Paths == nil || len(cfg.Paths) != 0 {
+		t.Errorf("Expected no paths, got %v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,103 @@
+package gitcfg
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// scriptGit writes a shell script to use with WithGitBinary.
+func scriptGit(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "git")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGetBlobConfig(t *testing.T) {
+	git := scriptGit(t, `case "$*" in
+*"cat-file blob HEAD:.gitconfig") printf '[user]\n\tname = Blob User\n[remote "origin"]\n\tfetch = a\n\tfetch = b\n' ;;
+*) echo "fatal: path 'missing' does not exist in 'HEAD'" >&2; exit 128 ;;
+esac`)
+
+	base := &Config{
+		sections: make(map[string]map[string]string),
+		load:     &configOptions{gitBinary: git, repoPath: t.TempDir()},
+	}
+
+	config, err := base.GetBlobConfig("HEAD:.gitconfig")
+	if err != nil {
+		t.Fatalf("GetBlobConfig failed: %v", err)
+	}
+	if name, _ := Get[string](config, "user.name"); name != "Blob User" {
+		t.Errorf("Expected 'Blob User', got '%s'", name)
+	}
+	if fetch, _ := config.GetMultiValue("remote.origin.fetch"); !reflect.DeepEqual(fetch, []string{"a", "b"}) {
+		t.Errorf("Expected both fetch values, got %v", fetch)
+	}
+	expected := []ConfigSource{{Type: SourceTypeCustom, Path: "HEAD:.gitconfig"}}
+	if sources := config.GetSources(); !reflect.DeepEqual(sources, expected) {
+		t.Errorf("Expected sources %v, got %v", expected, sources)
+	}
+	if _, source, _ := config.GetWithSource("user.name"); source == nil || source.Path != "HEAD:.gitconfig" {
+		t.Errorf("Expected user.name from the blob, got %v", source)
+	}
+
+	_, err = base.GetBlobConfig("HEAD:missing")
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Source != "HEAD:missing" {
+		t.Errorf("Expected an error naming the blob, got %v", err)
+	}
+	if _, err := base.GetBlobConfig("--output=/tmp/x"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for an option-like ref, got %v", err)
+	}
+}
+
+func TestLoadWithBlobRef(t *testing.T) {
+	repo, _ := setupScopeRepo(t)
+	writeTestFile(t, filepath.Join(repo, ".gitconfig"), "[shared]\n\tscope = blob\n[blob]\n\tkey = value\n")
+	for _, args := range [][]string{
+		{"add", ".gitconfig"},
+		{"-c", "user.email=test@example.com", "commit", "-q", "-m", "add config"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, output)
+		}
+	}
+
+	opts := []ConfigOption{WithLocal(), WithRepoPath(repo), WithBlobRef("HEAD:.gitconfig")}
+	fromFiles, err := Load(opts...)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	fromGit, err := Load(append(opts, WithGitCommand())...)
+	if err != nil {
+		t.Fatalf("Load with git command failed: %v", err)
+	}
+
+	for _, config := range []*Config{fromFiles, fromGit} {
+		if scope, _ := Get[string](config, "shared.scope"); scope != "blob" {
+			t.Errorf("Expected the blob to be read last, got %q", scope)
+		}
+	}
+	if got, want := fromGit.GetSources(), fromFiles.GetSources(); !reflect.DeepEqual(got, want) {
+		t.Errorf("git command sources = %v, file sources = %v", got, want)
+	}
+	if sources := fromFiles.GetSources(); sources[len(sources)-1] != (ConfigSource{Type: SourceTypeCustom, Path: "HEAD:.gitconfig"}) {
+		t.Errorf("Expected the blob as the last source, got %v", sources)
+	}
+
+	// Reload reads the blob again rather than a file named after it
+	if err := fromFiles.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if value, _ := Get[string](fromFiles, "blob.key"); value != "value" {
+		t.Errorf("Expected blob.key after Reload, got %q", value)
+	}
+}
@@ -0,0 +1,147 @@
+package gitcfg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GetBranch returns the configuration of the named branch, or an error
+// wrapping ErrSectionNotFound if the branch has no configuration.
+func (c *Config) GetBranch(name string) (*Branch, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	section := "branch." + name
+	if !c.HasSection(section) {
+		return nil, &ConfigError{
+			Op:      "get",
+			Section: section,
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	branch := &Branch{Name: name}
+
+	var rebase string
+	r := &fieldReader{c: c}
+	readField(r, section+".remote", &branch.Remote)
+	readField(r, section+".pushremote", &branch.PushRemote)
+	readField(r, section+".merge", &branch.Merge)
+	readField(r, section+".rebase", &rebase)
+	readField(r, section+".description", &branch.Description)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if rebase != "" {
+		mode, err := parseRebaseMode(rebase)
+		if err != nil {
+			return nil, &ConfigError{
+				Op:      "get",
+				Key:     "rebase",
+				Section: section,
+				Err:     err,
+			}
+		}
+		branch.Rebase = mode
+	}
+
+	return branch, nil
+}
+
+// GetAllBranches returns every configured branch sorted by name.
+func (c *Config) GetAllBranches() ([]*Branch, error) {
+	return c.GetAllBranchesWithContext(context.Background())
+}
+
+// GetAllBranchesWithContext is like GetAllBranches but stops with ctx.Err()
+// once ctx is done, checking every ContextCheckInterval branches.
+func (c *Config) GetAllBranchesWithContext(ctx context.Context) ([]*Branch, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	names := c.SubsectionNames("branch")
+
+	branches := make([]*Branch, 0, len(names))
+	for i, name := range names {
+		if err := checkContext(ctx, i); err != nil {
+			return nil, err
+		}
+		branch, err := c.GetBranch(name)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// GetBranchesForRemote returns the branches, sorted by name, whose
+// branch.<name>.remote is remoteName. The result is empty, not an error, when
+// no branch tracks the remote.
+func (c *Config) GetBranchesForRemote(remoteName string) ([]*Branch, error) {
+	return c.GetBranchesForRemoteWithContext(context.Background(), remoteName)
+}
+
+// GetBranchesForRemoteWithContext is like GetBranchesForRemote but stops with
+// ctx.Err() once ctx is done.
+func (c *Config) GetBranchesForRemoteWithContext(ctx context.Context, remoteName string) ([]*Branch, error) {
+	branches, err := c.GetAllBranchesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tracking := make([]*Branch, 0)
+	for _, branch := range branches {
+		if branch.Remote == remoteName {
+			tracking = append(tracking, branch)
+		}
+	}
+	return tracking, nil
+}
+
+// GetHEAD reads the HEAD file next to the local config and returns the ref
+// it points to, such as refs/heads/main, or the commit hash when HEAD is
+// detached. It fails with an error wrapping ErrSectionNotFound when no local
+// config is loaded.
+func (c *Config) GetHEAD() (string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return "", err
+	}
+
+	gitDir := c.gitDir()
+	if gitDir == "" {
+		return "", &ConfigError{
+			Op:  "get",
+			Err: fmt.Errorf("%w: no local config loaded", ErrSectionNotFound),
+		}
+	}
+
+	path := filepath.Join(gitDir, "HEAD")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    err,
+		}
+	}
+
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref:"); ok {
+		head = strings.TrimSpace(ref)
+	}
+	if head == "" {
+		return "", &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    fmt.Errorf("%w: empty HEAD", ErrInvalidValue),
+		}
+	}
+	return head, nil
+}
@@ -0,0 +1,135 @@
+package gitcfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const branchesTestConfig = `[branch "main"]
+    remote = origin
+    merge = refs/heads/main
+[branch "feature/login"]
+    remote = origin
+    merge = refs/heads/feature/login
+    rebase = true
+[branch "develop"]
+    remote = upstream
+    merge = refs/heads/develop
+[branch "release"]
+    remote = upstream
+    merge = refs/heads/release
+    description = Release branch
+[branch "hotfix"]
+    remote = origin
+    merge = refs/heads/hotfix
+    pushRemote = fork
+`
+
+func branchNames(branches []*Branch) []string {
+	names := make([]string, len(branches))
+	for i, branch := range branches {
+		names[i] = branch.Name
+	}
+	return names
+}
+
+func TestGetBranch(t *testing.T) {
+	config := parseTestConfig(t, branchesTestConfig)
+
+	branch, err := config.GetBranch("feature/login")
+	if err != nil {
+		t.Fatalf("GetBranch failed: %v", err)
+	}
+	expected := &Branch{Name: "feature/login", Remote: "origin", Merge: "refs/heads/feature/login", Rebase: RebaseTrue}
+	if !reflect.DeepEqual(branch, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, branch)
+	}
+
+	if _, err := config.GetBranch("missing"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+
+	invalid := parseTestConfig(t, "[branch \"main\"]\n    rebase = sometimes\n")
+	if _, err := invalid.GetBranch("main"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestGetBranchesForRemote(t *testing.T) {
+	config := parseTestConfig(t, branchesTestConfig)
+
+	all, err := config.GetAllBranches()
+	if err != nil {
+		t.Fatalf("GetAllBranches failed: %v", err)
+	}
+	if names := branchNames(all); !reflect.DeepEqual(names, []string{"develop", "feature/login", "hotfix", "main", "release"}) {
+		t.Errorf("Unexpected branches %v", names)
+	}
+
+	origin, err := config.GetBranchesForRemote("origin")
+	if err != nil {
+		t.Fatalf("GetBranchesForRemote failed: %v", err)
+	}
+	if names := branchNames(origin); !reflect.DeepEqual(names, []string{"feature/login", "hotfix", "main"}) {
+		t.Errorf("Unexpected origin branches %v", names)
+	}
+
+	upstream, err := config.GetBranchesForRemote("upstream")
+	if err != nil {
+		t.Fatalf("GetBranchesForRemote failed: %v", err)
+	}
+	if names := branchNames(upstream); !reflect.DeepEqual(names, []string{"develop", "release"}) {
+		t.Errorf("Unexpected upstream branches %v", names)
+	}
+
+	none, err := config.GetBranchesForRemote("fork")
+	if err != nil {
+		t.Fatalf("GetBranchesForRemote failed: %v", err)
+	}
+	if none == nil || len(none) != 0 {
+		t.Errorf("Expected an empty slice, got %#v", none)
+	}
+}
+
+func TestGetHEAD(t *testing.T) {
+	gitDir := t.TempDir()
+	source := ConfigSource{Type: SourceTypeLocal, Path: filepath.Join(gitDir, "config")}
+	config := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  []ConfigSource{source},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"symbolic", "ref: refs/heads/main\n", "refs/heads/main"},
+		{"detached", "3f786850e387550fdab836ed7e6dc881de23001b\n", "3f786850e387550fdab836ed7e6dc881de23001b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			head, err := config.GetHEAD()
+			if err != nil {
+				t.Fatalf("GetHEAD() error = %v", err)
+			}
+			if head != tt.want {
+				t.Errorf("GetHEAD() = %q, want %q", head, tt.want)
+			}
+		})
+	}
+
+	t.Run("no local source", func(t *testing.T) {
+		config := parseTestConfig(t, "[core]\n\tbare = false\n")
+		if _, err := config.GetHEAD(); !errors.Is(err, ErrSectionNotFound) {
+			t.Errorf("GetHEAD() error = %v, want ErrSectionNotFound", err)
+		}
+	})
+}
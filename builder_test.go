@@ -0,0 +1,90 @@
+package gitcfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfigBuilder(t *testing.T) {
+	config, err := NewConfigBuilder().
+		Section("user").Set("name", "Test User").Set("email", "test@example.com").
+		Subsection("remote", "Origin").
+		Set("url", "https://example.com/repo.git").
+		SetMulti("fetch", "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*").
+		AddSource(SourceTypeLocal, "/repo/.git/config").
+		Section("core").Set("bare", "false").
+		Section("alias").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if name, _ := Get[string](config, "user.name"); name != "Test User" {
+		t.Errorf("Expected 'Test User', got '%s'", name)
+	}
+	fetch, _ := config.GetMultiValue("remote.Origin.fetch")
+	if !reflect.DeepEqual(fetch, []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"}) {
+		t.Errorf("Expected both refspecs, got %v", fetch)
+	}
+	if !config.HasSection("alias") {
+		t.Error("Expected the empty alias section to exist")
+	}
+
+	expectedSources := []ConfigSource{BuilderSource, {Type: SourceTypeLocal, Path: "/repo/.git/config"}}
+	if sources := config.GetSources(); !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("Expected sources %v, got %v", expectedSources, sources)
+	}
+	if _, source, _ := config.GetWithSource("user.name"); source == nil || *source != BuilderSource {
+		t.Errorf("Expected user.name from the builder source, got %v", source)
+	}
+	if path, _ := config.WhichFile("core.bare"); path != "/repo/.git/config" {
+		t.Errorf("Expected core.bare from the added source, got '%s'", path)
+	}
+}
+
+func TestConfigBuilderFullKeys(t *testing.T) {
+	builder := NewConfigBuilder().Set("user.name", "First")
+	first, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	second, err := builder.Set("user.name", "Second").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if name, _ := Get[string](first, "user.name"); name != "First" {
+		t.Errorf("Expected an earlier Build to be unaffected, got '%s'", name)
+	}
+	if name, _ := Get[string](second, "user.name"); name != "Second" {
+		t.Errorf("Expected 'Second', got '%s'", name)
+	}
+}
+
+func TestConfigBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *ConfigBuilder
+		err     error
+	}{
+		{"invalid section", NewConfigBuilder().Section("bad section").Set("key", "value"), ErrInvalidKeyFormat},
+		{"invalid key", NewConfigBuilder().Section("user").Set("bad key", "value"), ErrInvalidKeyFormat},
+		{"key without section", NewConfigBuilder().Set("name", "value"), ErrInvalidKeyFormat},
+		{"no values", NewConfigBuilder().Section("remote.origin").SetMulti("fetch"), ErrInvalidValue},
+		{"first error wins", NewConfigBuilder().Set("bad", "value").SetMulti("user.name"), ErrInvalidKeyFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := test.builder.Build()
+			if config != nil || !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v, %v", test.err, config, err)
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) || configErr.Op != "build" {
+				t.Errorf("Expected a build ConfigError, got %v", err)
+			}
+		})
+	}
+}
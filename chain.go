@@ -0,0 +1,230 @@
+package gitcfg
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ConfigChain is an ordered list of configs consulted from highest to lowest
+// priority, the way git checks worktree, local, global and then system
+// config when resolving a key.
+type ConfigChain struct {
+	mu     sync.RWMutex
+	layers []*Config
+}
+
+// NewConfigChain returns a chain over configs given in priority order, the
+// first being the highest. Nil configs are ignored.
+func NewConfigChain(configs ...*Config) *ConfigChain {
+	chain := &ConfigChain{}
+	for _, c := range configs {
+		if c != nil {
+			chain.layers = append(chain.layers, c)
+		}
+	}
+	return chain
+}
+
+// AddLayer pushes c as the new highest-priority layer.
+func (ch *ConfigChain) AddLayer(c *Config) {
+	if c == nil {
+		return
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.layers = append([]*Config{c}, ch.layers...)
+}
+
+// Layers returns the configs in the chain, highest priority first.
+func (ch *ConfigChain) Layers() []*Config {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return append([]*Config(nil), ch.layers...)
+}
+
+// layer returns the highest-priority config that sets key. A missing key or
+// section in a layer falls through to the next; any other error stops the
+// search.
+func (ch *ConfigChain) layer(key string) (*Config, error) {
+	section, subkey, err := parseConfigKey(key)
+	if err != nil {
+		return nil, &ConfigError{
+			Op:  "get",
+			Key: key,
+			Err: err,
+		}
+	}
+
+	for _, c := range ch.Layers() {
+		_, _, err := c.GetWithSource(key)
+		if err == nil {
+			return c, nil
+		}
+		if !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, &ConfigError{
+		Op:      "get",
+		Key:     subkey,
+		Section: section,
+		Err:     ErrKeyNotFound,
+	}
+}
+
+// Get returns the raw value of key from the highest-priority layer that
+// sets it.
+func (ch *ConfigChain) Get(key string) (string, error) {
+	value, _, err := ch.GetWithSource(key)
+	return value, err
+}
+
+// GetWithSource is like Get but also returns the source that set the value.
+func (ch *ConfigChain) GetWithSource(key string) (string, *ConfigSource, error) {
+	return ChainGetWithSource[string](ch, key)
+}
+
+// ChainGet retrieves a value from the chain with type conversion. A value
+// that fails to convert is an error; lower layers are not consulted.
+func ChainGet[T Constraint](ch *ConfigChain, key string) (T, error) {
+	value, _, err := ChainGetWithSource[T](ch, key)
+	return value, err
+}
+
+// ChainGetWithSource is like ChainGet but also returns the source that set
+// the value.
+func ChainGetWithSource[T Constraint](ch *ConfigChain, key string) (T, *ConfigSource, error) {
+	var zero T
+
+	c, err := ch.layer(key)
+	if err != nil {
+		return zero, nil, err
+	}
+	return GetWithSource[T](c, key)
+}
+
+// Has reports whether any layer sets key.
+func (ch *ConfigChain) Has(key string) bool {
+	for _, c := range ch.Layers() {
+		if c.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSection reports whether any layer has section.
+func (ch *ConfigChain) HasSection(section string) bool {
+	for _, c := range ch.Layers() {
+		if c.HasSection(section) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSection merges section across all layers, with keys from higher layers
+// shadowing the same keys below them.
+func (ch *ConfigChain) GetSection(section string) map[string]string {
+	layers := ch.Layers()
+
+	result := make(map[string]string)
+	for i := len(layers) - 1; i >= 0; i-- {
+		for k, v := range layers[i].GetSection(section) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// GetSections returns the sorted union of section names across all layers.
+func (ch *ConfigChain) GetSections() []string {
+	seen := make(map[string]bool)
+	for _, c := range ch.Layers() {
+		for _, section := range c.GetSections() {
+			seen[section] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// GetKeys returns the sorted union of dotted key names across all layers.
+func (ch *ConfigChain) GetKeys() []string {
+	seen := make(map[string]bool)
+	for _, c := range ch.Layers() {
+		for _, key := range c.GetKeys() {
+			seen[key] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// GetAll merges every layer into one map, with values from higher layers
+// shadowing the same keys below them.
+func (ch *ConfigChain) GetAll() map[string]map[string]string {
+	layers := ch.Layers()
+
+	result := make(map[string]map[string]string)
+	for i := len(layers) - 1; i >= 0; i-- {
+		for section, sectionMap := range layers[i].GetAll() {
+			if result[section] == nil {
+				result[section] = make(map[string]string, len(sectionMap))
+			}
+			for k, v := range sectionMap {
+				result[section][k] = v
+			}
+		}
+	}
+	return result
+}
+
+// GetMultiValue returns the values of a multi-valued key from every layer,
+// lowest priority first, matching the order git reads them in.
+func (ch *ConfigChain) GetMultiValue(key string) ([]string, error) {
+	section, subkey, err := parseConfigKey(key)
+	if err != nil {
+		return nil, &ConfigError{
+			Op:  "get",
+			Key: key,
+			Err: err,
+		}
+	}
+
+	layers := ch.Layers()
+
+	var result []string
+	for i := len(layers) - 1; i >= 0; i-- {
+		values, err := layers[i].GetMultiValue(key)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrSectionNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, values...)
+	}
+
+	if len(result) == 0 {
+		return nil, &ConfigError{
+			Op:      "get",
+			Key:     subkey,
+			Section: section,
+			Err:     ErrKeyNotFound,
+		}
+	}
+	return result, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package gitcfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfigChainShadowing(t *testing.T) {
+	system := parseTestConfig(t, `[core]
+    editor = nano
+    autocrlf = false
+[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*`)
+	global := parseTestConfig(t, `[core]
+    editor = vim
+[user]
+    name = Global User`)
+	local := parseTestConfig(t, `[user]
+    name = Local User
+[remote "origin"]
+    fetch = +refs/tags/*:refs/tags/*`)
+
+	chain := NewConfigChain(local, global, system)
+
+	tests := map[string]string{
+		"user.name":     "Local User",
+		"core.editor":   "vim",
+		"core.autocrlf": "false",
+	}
+	for key, expected := range tests {
+		value, err := chain.Get(key)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", key, err)
+		}
+		if value != expected {
+			t.Errorf("Get(%q) = %q, expected %q", key, value, expected)
+		}
+	}
+
+	autocrlf, err := ChainGet[bool](chain, "core.autocrlf")
+	if err != nil || autocrlf {
+		t.Errorf("ChainGet[bool] = %v, %v", autocrlf, err)
+	}
+
+	expectedCore := map[string]string{"editor": "vim", "autocrlf": "false"}
+	if core := chain.GetSection("core"); !reflect.DeepEqual(core, expectedCore) {
+		t.Errorf("GetSection(core) = %v, expected %v", core, expectedCore)
+	}
+
+	all := chain.GetAll()
+	if all["user"]["name"] != "Local User" || all["core"]["editor"] != "vim" || all["core"]["autocrlf"] != "false" {
+		t.Errorf("Unexpected merged config: %v", all)
+	}
+
+	fetch, err := chain.GetMultiValue("remote.origin.fetch")
+	if err != nil {
+		t.Fatalf("GetMultiValue failed: %v", err)
+	}
+	expectedFetch := []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"}
+	if !reflect.DeepEqual(fetch, expectedFetch) {
+		t.Errorf("GetMultiValue = %v, expected %v", fetch, expectedFetch)
+	}
+
+	expectedSections := []string{"core", "remote.origin", "user"}
+	if sections := chain.GetSections(); !reflect.DeepEqual(sections, expectedSections) {
+		t.Errorf("GetSections = %v, expected %v", sections, expectedSections)
+	}
+}
+
+func TestConfigChainAddLayer(t *testing.T) {
+	chain := NewConfigChain(parseTestConfig(t, "[core]\n    editor = vim"))
+
+	chain.AddLayer(parseTestConfig(t, "[core]\n    editor = emacs"))
+
+	value, err := chain.Get("core.editor")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if value != "emacs" {
+		t.Errorf("Expected new layer to shadow, got %q", value)
+	}
+	if len(chain.Layers()) != 2 {
+		t.Errorf("Expected 2 layers, got %d", len(chain.Layers()))
+	}
+}
+
+func TestConfigChainMissingKey(t *testing.T) {
+	chain := NewConfigChain(
+		parseTestConfig(t, "[core]\n    editor = vim"),
+		parseTestConfig(t, "[user]\n    name = Test"),
+	)
+
+	for _, key := range []string{"core.pager", "http.proxy"} {
+		if _, err := chain.Get(key); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("Get(%q): expected ErrKeyNotFound, got %v", key, err)
+		}
+		if chain.Has(key) {
+			t.Errorf("Has(%q) = true, expected false", key)
+		}
+	}
+
+	if _, err := chain.GetMultiValue("http.proxy"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+
+	if _, err := NewConfigChain().Get("core.editor"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound from empty chain, got %v", err)
+	}
+}
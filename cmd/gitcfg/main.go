@@ -0,0 +1,287 @@
+// Command gitcfg inspects git configuration the way the gitcfg library reads
+// it, which makes it handy for debugging issue reports.
+//
+// Usage:
+//
+//	gitcfg get [--all] <key> [flags]
+//	gitcfg list [--json] [flags]
+//	gitcfg sources [flags]
+//	gitcfg diff <fileA> <fileB>
+//	gitcfg verify [flags]
+//
+// The flags select what is loaded and map to the library's ConfigOptions:
+// --system, --global, --local, --worktree, --file, --repo, --git-command and
+// --timeout. Without a scope flag or --file every scope is read, with local
+// and worktree only when --repo (default ".") is a repository; otherwise only
+// the selected scopes and files are, as with git config.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/unkn0wn-root/gitcfg"
+)
+
+const usage = `usage: gitcfg <command> [arguments] [flags]
+
+commands:
+  get [--all] <key>   print the effective value of key, or all of its values
+  list [--json]       print every key and value
+  sources             print the files that were read
+  diff <fileA> <fileB>
+                      compare two config files; exits 1 if they differ
+  verify              compare the file parser with git config; exits 1 on a mismatch
+
+flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// loadFlags are the flags that select what is loaded.
+type loadFlags struct {
+	system, global, local, worktree bool
+	gitCommand                      bool
+	repo                            string
+	files                           stringList
+	timeout                         time.Duration
+}
+
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+func (f *loadFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&f.system, "system", false, "read the system config")
+	fs.BoolVar(&f.global, "global", false, "read the global config")
+	fs.BoolVar(&f.local, "local", false, "read the repository's .git/config")
+	fs.BoolVar(&f.worktree, "worktree", false, "read the repository's .git/config.worktree")
+	fs.Var(&f.files, "file", "read an additional config file (repeatable)")
+	fs.StringVar(&f.repo, "repo", ".", "repository for --local and --worktree")
+	fs.BoolVar(&f.gitCommand, "git-command", false, "read through the git binary instead of parsing files")
+	fs.DurationVar(&f.timeout, "timeout", gitcfg.DefaultTimeout, "timeout for the git binary")
+}
+
+// options translates the flags into ConfigOptions.
+func (f *loadFlags) options() []gitcfg.ConfigOption {
+	system, global, local, worktree := f.system, f.global, f.local, f.worktree
+	if !f.system && !f.global && !f.local && !f.worktree && len(f.files) == 0 {
+		_, err := os.Stat(filepath.Join(f.repo, ".git"))
+		system, global, local, worktree = true, true, err == nil, err == nil
+	}
+
+	opts := []gitcfg.ConfigOption{gitcfg.WithRepoPath(f.repo), gitcfg.WithTimeout(f.timeout)}
+	if system {
+		opts = append(opts, gitcfg.WithSystem())
+	}
+	if !global {
+		opts = append(opts, gitcfg.WithNoGlobal())
+	}
+	if local {
+		opts = append(opts, gitcfg.WithLocal())
+	}
+	if worktree {
+		opts = append(opts, gitcfg.WithWorktree())
+	}
+	for _, file := range f.files {
+		opts = append(opts, gitcfg.WithFile(file))
+	}
+	if f.gitCommand {
+		opts = append(opts, gitcfg.WithGitCommand())
+	}
+	return opts
+}
+
+// run executes the command line args and returns the exit status: 0 on
+// success, 1 for a missing key, a difference or an error, and 2 for a usage
+// error.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	command := args[0]
+	fs := flag.NewFlagSet("gitcfg "+command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	var load loadFlags
+	var all, jsonOutput bool
+	switch command {
+	case "get":
+		fs.BoolVar(&all, "all", false, "print every value of a multi-valued key")
+		load.register(fs)
+	case "list":
+		fs.BoolVar(&jsonOutput, "json", false, "print JSON instead of key=value lines")
+		load.register(fs)
+	case "sources", "verify":
+		load.register(fs)
+	case "diff":
+	case "help", "-h", "--help":
+		fs.Usage()
+		return 0
+	default:
+		fmt.Fprintf(stderr, "gitcfg: unknown command %q\n", command)
+		fs.Usage()
+		return 2
+	}
+
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	want := map[string]int{"get": 1, "diff": 2}[command]
+	if len(positional) != want {
+		fmt.Fprintf(stderr, "gitcfg %s: expected %d arguments, got %d\n", command, want, len(positional))
+		fs.Usage()
+		return 2
+	}
+
+	if command == "diff" {
+		return runDiff(positional[0], positional[1], stdout, stderr)
+	}
+
+	config, err := gitcfg.LoadWithContext(context.Background(), load.options()...)
+	if err != nil {
+		fmt.Fprintf(stderr, "gitcfg: %v\n", err)
+		return 1
+	}
+
+	switch command {
+	case "get":
+		return runGet(config, positional[0], all, stdout, stderr)
+	case "list":
+		format := gitcfg.OutputFormatFlat
+		if jsonOutput {
+			format = gitcfg.OutputFormatJSON
+		}
+		if err := config.PrintTo(stdout, format); err != nil {
+			fmt.Fprintf(stderr, "gitcfg: %v\n", err)
+			return 1
+		}
+	case "sources":
+		for _, source := range config.GetSources() {
+			fmt.Fprintf(stdout, "%s\t%s\n", source.Type, source.Path)
+		}
+	case "verify":
+		return runVerify(config, stdout, stderr)
+	}
+	return 0
+}
+
+// parseInterspersed parses flags that may come before, between or after the
+// positional arguments, as in "gitcfg get user.email --repo .".
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func runGet(config *gitcfg.Config, key string, all bool, stdout, stderr io.Writer) int {
+	var values []string
+	var err error
+	if all {
+		values, err = config.GetMultiValue(key)
+	} else {
+		var value string
+		value, err = gitcfg.Get[string](config, key)
+		values = []string{value}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gitcfg: %v\n", err)
+		return 1
+	}
+
+	for _, value := range values {
+		fmt.Fprintln(stdout, value)
+	}
+	return 0
+}
+
+func runDiff(pathA, pathB string, stdout, stderr io.Writer) int {
+	a, err := parseFile(pathA)
+	if err != nil {
+		fmt.Fprintf(stderr, "gitcfg: %v\n", err)
+		return 1
+	}
+	b, err := parseFile(pathB)
+	if err != nil {
+		fmt.Fprintf(stderr, "gitcfg: %v\n", err)
+		return 1
+	}
+
+	diff := gitcfg.Diff(a, b)
+	for _, entry := range diff.Removed {
+		fmt.Fprintf(stdout, "- %s=%s\n", entry.Key, entry.OldValue())
+	}
+	for _, entry := range diff.Added {
+		fmt.Fprintf(stdout, "+ %s=%s\n", entry.Key, entry.NewValue())
+	}
+	for _, entry := range diff.Changed {
+		fmt.Fprintf(stdout, "~ %s: %q -> %q\n", entry.Key, entry.OldValues, entry.NewValues)
+	}
+	if !diff.IsEmpty() {
+		return 1
+	}
+	return 0
+}
+
+// parseFile reads a single config file, without the global config Load
+// always adds.
+func parseFile(path string) (*gitcfg.Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, err := gitcfg.ParseFromReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+func runVerify(config *gitcfg.Config, stdout, stderr io.Writer) int {
+	diff, err := config.VerifyAgainstGit(context.Background())
+	if err != nil {
+		fmt.Fprintf(stderr, "gitcfg: %v\n", err)
+		return 1
+	}
+
+	for _, entry := range diff.Entries {
+		fmt.Fprintf(stdout, "%s: %s (files %q, git %q)\n", entry.Key, entry.Kind, entry.FileValues, entry.GitValues)
+	}
+	if !diff.IsEmpty() {
+		return 1
+	}
+	fmt.Fprintln(stdout, "file parser agrees with git")
+	return 0
+}
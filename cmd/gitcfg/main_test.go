@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/gitcfg/gitcfgtest"
+)
+
+func runCommand(t *testing.T, args ...string) (stdout, stderr string, status int) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	status = run(args, &out, &errOut)
+	return out.String(), errOut.String(), status
+}
+
+func TestGetAndList(t *testing.T) {
+	repo := gitcfgtest.NewRepo(t, map[string]string{
+		gitcfgtest.Global: "[user]\n\temail = global@example.com\n",
+		gitcfgtest.Local:  "[user]\n\temail = local@example.com\n[remote \"origin\"]\n\tfetch = a\n\tfetch = b\n",
+	})
+
+	if out, _, status := runCommand(t, "get", "user.email", "--repo", repo); status != 0 || out != "local@example.com\n" {
+		t.Errorf("get = %q, %d", out, status)
+	}
+	if out, _, status := runCommand(t, "get", "--global", "user.email", "--repo", repo); status != 0 || out != "global@example.com\n" {
+		t.Errorf("get --global = %q, %d", out, status)
+	}
+	if out, _, status := runCommand(t, "get", "--all", "remote.origin.fetch", "--repo", repo); status != 0 || out != "a\nb\n" {
+		t.Errorf("get --all = %q, %d", out, status)
+	}
+	if _, stderr, status := runCommand(t, "get", "core.editor", "--repo", repo); status != 1 || !strings.Contains(stderr, "not found") {
+		t.Errorf("get of a missing key = %q, %d", stderr, status)
+	}
+
+	out, _, status := runCommand(t, "list", "--repo", repo)
+	if status != 0 || !strings.Contains(out, "user.email=local@example.com\n") {
+		t.Errorf("list = %q, %d", out, status)
+	}
+	out, _, status = runCommand(t, "list", "--json", "--repo", repo)
+	if status != 0 || !json.Valid([]byte(out)) {
+		t.Errorf("list --json = %q, %d", out, status)
+	}
+
+	out, _, status = runCommand(t, "sources", "--local", "--repo", repo)
+	if status != 0 || out != "local\t"+filepath.Join(repo, ".git", "config")+"\n" {
+		t.Errorf("sources --local = %q, %d", out, status)
+	}
+}
+
+func TestScopeFlags(t *testing.T) {
+	repo := gitcfgtest.NewRepo(t, map[string]string{
+		gitcfgtest.Global: "[user]\n\tname = Global User\n",
+		gitcfgtest.Local:  "[user]\n\temail = local@example.com\n",
+	})
+
+	if out, _, status := runCommand(t, "list", "--local", "--repo", repo); status != 0 || out != "user.email=local@example.com\n" {
+		t.Errorf("list --local = %q, %d", out, status)
+	}
+	if _, _, status := runCommand(t, "get", "--local", "user.name", "--repo", repo); status != 1 {
+		t.Errorf("get --local of a global key = %d, expected 1", status)
+	}
+
+	out, _, status := runCommand(t, "list", "--global", "--local", "--repo", repo)
+	if status != 0 || out != "user.email=local@example.com\nuser.name=Global User\n" {
+		t.Errorf("list --global --local = %q, %d", out, status)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	os.WriteFile(a, []byte("[core]\n\teditor = vim\n\tbare = false\n"), 0o644)
+	os.WriteFile(b, []byte("[core]\n\teditor = nano\n[user]\n\tname = Test\n"), 0o644)
+
+	out, _, status := runCommand(t, "diff", a, b)
+	expected := "- core.bare=false\n+ user.name=Test\n~ core.editor: [\"vim\"] -> [\"nano\"]\n"
+	if status != 1 || out != expected {
+		t.Errorf("diff = %q, %d, expected %q", out, status, expected)
+	}
+	if out, _, status := runCommand(t, "diff", a, a); status != 0 || out != "" {
+		t.Errorf("diff of a file with itself = %q, %d", out, status)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := gitcfgtest.NewRepo(t, map[string]string{gitcfgtest.Local: "[core]\n\tbare = false\n"})
+
+	if out, stderr, status := runCommand(t, "verify", "--local", "--repo", repo); status != 0 {
+		t.Errorf("verify = %q, %q, %d", out, stderr, status)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"bogus"}, {"get"}, {"diff", "a"}, {"list", "--bogus"}} {
+		if _, _, status := runCommand(t, args...); status != 2 {
+			t.Errorf("%q: expected status 2, got %d", args, status)
+		}
+	}
+}
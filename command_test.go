@@ -0,0 +1,493 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setupScopeRepo creates a global config, a repository with local and
+// worktree config and a standalone file, each setting distinct keys plus a
+// shared key so precedence is observable.
+func setupScopeRepo(t *testing.T) (repo, file string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	writeTestFile(t, filepath.Join(home, ".gitconfig"), "[user]\n\tname = Global User\n[shared]\n\tscope = global\n")
+
+	repo = t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, output)
+	}
+	writeTestFile(t, filepath.Join(repo, ".git", "config"), `[core]
+	repositoryformatversion = 1
+	bare = false
+[extensions]
+	worktreeConfig = true
+[remote "origin"]
+	url = https://example.com/repo.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+	fetch = +refs/tags/*:refs/tags/*
+[shared]
+	scope = local
+[url "https://github.com/"]
+	insteadOf = gh:
+`)
+	writeTestFile(t, filepath.Join(repo, ".git", "config.worktree"), "[core]\n\tsparseCheckout = true\n[shared]\n\tscope = worktree\n")
+
+	file = filepath.Join(t.TempDir(), "extra.cfg")
+	writeTestFile(t, file, "[extra]\n\tflag\n[shared]\n\tscope = file\n")
+
+	return repo, file
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestGitCommandScopesMatchFileMode(t *testing.T) {
+	repo, file := setupScopeRepo(t)
+
+	tests := []struct {
+		name string
+		opts []ConfigOption
+	}{
+		{"global", []ConfigOption{WithGlobal()}},
+		{"local", []ConfigOption{WithLocal(), WithRepoPath(repo)}},
+		{"local and worktree", []ConfigOption{WithLocal(), WithWorktree(), WithRepoPath(repo)}},
+		{"all", []ConfigOption{WithGlobal(), WithLocal(), WithWorktree(), WithRepoPath(repo)}},
+		{"file", []ConfigOption{WithFile(file)}},
+		{"all and file", []ConfigOption{WithLocal(), WithWorktree(), WithRepoPath(repo), WithFile(file)}},
+		{"git dir", []ConfigOption{WithLocal(), WithWorktree(), WithGitDir(filepath.Join(repo, ".git"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fromFiles, err := Load(tt.opts...)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			fromGit, err := Load(append(tt.opts, WithGitCommand())...)
+			if err != nil {
+				t.Fatalf("Load with git command failed: %v", err)
+			}
+
+			if got, want := fromGit.GetAll(), fromFiles.GetAll(); !reflect.DeepEqual(got, want) {
+				t.Errorf("git command mode = %v, file mode = %v", got, want)
+			}
+			if got, want := fromGit.GetSources(), fromFiles.GetSources(); !reflect.DeepEqual(got, want) {
+				t.Errorf("git command sources = %v, file sources = %v", got, want)
+			}
+
+			for _, key := range fromFiles.GetKeys() {
+				want, _ := fromFiles.GetMultiValue(key)
+				got, _ := fromGit.GetMultiValue(key)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: git command mode = %v, file mode = %v", key, got, want)
+				}
+
+				_, wantSource, _ := fromFiles.GetWithSource(key)
+				_, gotSource, _ := fromGit.GetWithSource(key)
+				if gotSource == nil || wantSource == nil || gotSource.Type != wantSource.Type {
+					t.Errorf("%s: git command source %v, file source %v", key, gotSource, wantSource)
+				}
+			}
+		})
+	}
+}
+
+func TestGitCommandMissingFile(t *testing.T) {
+	setupScopeRepo(t)
+
+	if _, err := Load(WithGlobal(), WithFile(filepath.Join(t.TempDir(), "missing")), WithGitCommand()); err == nil {
+		t.Error("Expected an error for a missing WithFile path")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	config, err := Load(WithGlobal(), WithGitCommand())
+	if err != nil {
+		t.Fatalf("Expected a missing global config to be skipped, got %v", err)
+	}
+	if len(config.GetSources()) != 0 {
+		t.Errorf("Expected no sources, got %v", config.GetSources())
+	}
+}
+
+func TestParseGitConfigOutputWithScope(t *testing.T) {
+	output := "global\x00file:/home/user/.gitconfig\x00user.name\nTest User\x00" +
+		"local\x00file:.git/config\x00core.bare\nfalse\x00" +
+		"command\x00file:/tmp/extra.cfg\x00extra.flag\x00"
+
+	config := &Config{sections: make(map[string]map[string]string)}
+	if err := newParser().parseGitConfigOutput(output, config, SourceTypeCustom, "/repo", make(map[string]bool)); err != nil {
+		t.Fatalf("parseGitConfigOutput failed: %v", err)
+	}
+
+	expected := []ConfigSource{
+		{Type: SourceTypeGlobal, Path: "/home/user/.gitconfig"},
+		{Type: SourceTypeLocal, Path: filepath.Join("/repo", ".git/config")},
+		{Type: SourceTypeCustom, Path: "/tmp/extra.cfg"},
+	}
+	if got := config.GetSources(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("GetSources() = %v, want %v", got, expected)
+	}
+
+	_, source, err := config.GetWithSource("core.bare")
+	if err != nil || source.Type != SourceTypeLocal {
+		t.Errorf("Expected core.bare from the local scope, got %v (%v)", source, err)
+	}
+	if !config.Has("extra.flag") {
+		t.Error("Expected value-less key to be recorded")
+	}
+}
+
+func TestParseGitConfigOutput(t *testing.T) {
+	output := "file:/home/user/.gitconfig\x00user.name\nTest User\x00" +
+		"file:.git/config\x00url.https://github.com/.insteadof\ngh:\x00" +
+		"file:.git/config\x00core.bare\x00" +
+		"file:.git/config\x00alias.lg\nlog --graph\n--oneline\x00"
+
+	config := &Config{sections: make(map[string]map[string]string)}
+	if err := newParser().parseGitConfigOutput(output, config, SourceTypeLocal, "/repo", make(map[string]bool)); err != nil {
+		t.Fatalf("parseGitConfigOutput failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"user.name":                         "Test User",
+		"url.https://github.com/.insteadof": "gh:",
+		"core.bare":                         "",
+		"alias.lg":                          "log --graph\n--oneline",
+	}
+	for key, want := range expected {
+		if got, err := Get[string](config, key); err != nil || got != want {
+			t.Errorf("%s = %q (%v), want %q", key, got, err, want)
+		}
+	}
+
+	wantSources := []ConfigSource{
+		{Type: SourceTypeLocal, Path: "/home/user/.gitconfig"},
+		{Type: SourceTypeLocal, Path: filepath.Join("/repo", ".git/config")},
+	}
+	if got := config.GetSources(); !reflect.DeepEqual(got, wantSources) {
+		t.Errorf("GetSources() = %v, want %v", got, wantSources)
+	}
+}
+
+// fakeGit puts a shell script named git first in PATH. $GIT refers to the
+// real git binary inside the script.
+func fakeGit(t *testing.T, script string) {
+	t.Helper()
+
+	realGit, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	bin := t.TempDir()
+	content := "#!/bin/sh\nGIT=" + realGit + "\n" + script + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGitCommandWithoutShowScope(t *testing.T) {
+	repo, _ := setupScopeRepo(t)
+
+	// Behave like git older than 2.26
+	fakeGit(t, "for arg in \"$@\"; do\n"+
+		"  if [ \"$arg\" = --show-scope ]; then echo \"error: unknown option \\`show-scope'\" >&2; exit 129; fi\n"+
+		"done\nexec $GIT \"$@\"")
+
+	opts := []ConfigOption{WithGlobal(), WithLocal(), WithWorktree(), WithRepoPath(repo)}
+	fromGit, err := Load(append(opts, WithGitCommand())...)
+	if err != nil {
+		t.Fatalf("Load with git command failed: %v", err)
+	}
+	fromFiles, err := Load(opts...)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if got, want := fromGit.GetSources(), fromFiles.GetSources(); !reflect.DeepEqual(got, want) {
+		t.Errorf("git command sources = %v, file sources = %v", got, want)
+	}
+	if scope, _ := Get[string](fromGit, "shared.scope"); scope != "worktree" {
+		t.Errorf("Expected worktree to take precedence, got %q", scope)
+	}
+
+	// With sources recorded, Reload can re-read what the git command loaded
+	if err := fromGit.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got, want := fromGit.GetAll(), fromFiles.GetAll(); !reflect.DeepEqual(got, want) {
+		t.Errorf("After reload = %v, want %v", got, want)
+	}
+}
+
+func TestGitCommandErrors(t *testing.T) {
+	setupScopeRepo(t)
+
+	t.Run("git not found", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		if _, err := Load(WithGlobal(), WithGitCommand()); !errors.Is(err, ErrGitNotFound) {
+			t.Errorf("Expected ErrGitNotFound, got %v", err)
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		fakeGit(t, "echo 'fatal: not a git repository (or any of the parent directories): .git' >&2; exit 128")
+		if _, err := Load(WithLocal(), WithGitCommand()); !errors.Is(err, ErrNotARepository) {
+			t.Errorf("Expected ErrNotARepository, got %v", err)
+		}
+	})
+
+	t.Run("invalid repo path", func(t *testing.T) {
+		if _, err := LoadLocal(t.TempDir()); !errors.Is(err, ErrNotARepository) {
+			t.Errorf("Expected ErrNotARepository, got %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		fakeGit(t, "exec sleep 5")
+		_, err := Load(WithGlobal(), WithGitCommand(), WithTimeout(50*time.Millisecond))
+		if !errors.Is(err, ErrGitTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected ErrGitTimeout, got %v", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		fakeGit(t, "exec sleep 5")
+		start := time.Now()
+		_, err := Load(WithGlobal(), WithGitCommand(), WithTimeout(10*time.Second), WithDeadline(time.Now().Add(100*time.Millisecond)))
+		if !errors.Is(err, ErrGitTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected ErrGitTimeout, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the earlier deadline to win, took %v", elapsed)
+		}
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		fakeGit(t, "exec sleep 5")
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := LoadWithContext(ctx, WithGlobal(), WithGitCommand(), WithNoTimeout())
+		if !errors.Is(err, ErrGitTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the parent deadline to apply, got %v", err)
+		}
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		fakeGit(t, "echo \"fatal: unable to read config file '/etc/gitconfig': Permission denied\" >&2; exit 128")
+		if _, err := Load(WithGlobal(), WithGitCommand()); !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("Expected fs.ErrPermission, got %v", err)
+		}
+	})
+}
+
+func TestGitCommandWarnings(t *testing.T) {
+	setupScopeRepo(t)
+
+	fakeGit(t, "echo 'warning: ignoring include.path: not found' >&2\nexec $GIT \"$@\"")
+
+	config, err := Load(WithGlobal(), WithGitCommand())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	warnings := config.Warnings()
+	if len(warnings) != 1 || warnings[0].Error() != "warning: ignoring include.path: not found" {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+	if name, _ := Get[string](config, "user.name"); name != "Global User" {
+		t.Errorf("Expected config to load despite warnings, got %q", name)
+	}
+
+	fromFiles, err := Load(WithGlobal())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(fromFiles.Warnings()) != 0 {
+		t.Errorf("Expected no warnings in file mode, got %v", fromFiles.Warnings())
+	}
+}
+
+func TestWithGitBinary(t *testing.T) {
+	setupScopeRepo(t)
+
+	realGit, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	// The configured binary is used even when git is not on PATH
+	t.Setenv("PATH", t.TempDir())
+	config, err := Load(WithGlobal(), WithGitCommand(), WithGitBinary(realGit))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if name, _ := Get[string](config, "user.name"); name != "Global User" {
+		t.Errorf("Expected global config via the configured binary, got %q", name)
+	}
+
+	missing := filepath.Join(t.TempDir(), "git")
+	if _, err := Load(WithGlobal(), WithGitCommand(), WithGitBinary(missing)); !errors.Is(err, ErrGitNotFound) {
+		t.Errorf("Expected ErrGitNotFound for a missing binary, got %v", err)
+	}
+}
+
+func TestWithGitEnv(t *testing.T) {
+	setupScopeRepo(t)
+
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "injected.key")
+	t.Setenv("GIT_CONFIG_VALUE_0", "yes")
+
+	// Without a scope flag git also lists command-line config, so run through
+	// a wrapper that drops the scope and reports what the environment injected
+	fakeGit(t, `cd "$HOME" && exec $GIT config --list --null --show-origin --show-scope`)
+
+	inherited, err := Load(WithGlobal(), WithGitCommand())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !inherited.Has("injected.key") {
+		t.Fatal("Expected the inherited environment to inject config")
+	}
+
+	sanitized, err := Load(WithGlobal(), WithGitCommand(), WithGitEnv(nil))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if sanitized.Has("injected.key") {
+		t.Error("Expected the sanitized environment to drop GIT_CONFIG_* injection")
+	}
+	if name, _ := Get[string](sanitized, "user.name"); name != "Global User" {
+		t.Errorf("Expected HOME to be kept, got %q", name)
+	}
+
+	explicit, err := Load(WithGlobal(), WithGitCommand(), WithGitEnv(append(os.Environ(), "GIT_CONFIG_PARAMETERS='explicit.key=1'")))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !explicit.Has("explicit.key") {
+		t.Error("Expected an explicit environment to be passed unchanged")
+	}
+}
+
+func TestSanitizedGitEnv(t *testing.T) {
+	env := sanitizedGitEnv([]string{
+		"HOME=/home/user",
+		"PATH=/usr/bin",
+		"XDG_CONFIG_HOME=/home/user/.config",
+		"GIT_DIR=/repo/.git",
+		"GIT_CONFIG_PARAMETERS='a.b=c'",
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=a.b",
+		"GIT_CONFIG_VALUE_0=c",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"EDITOR=vim",
+	})
+
+	expected := []string{
+		"HOME=/home/user",
+		"PATH=/usr/bin",
+		"XDG_CONFIG_HOME=/home/user/.config",
+		"GIT_DIR=/repo/.git",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("sanitizedGitEnv() = %v, want %v", env, expected)
+	}
+}
+
+func TestSkipUnreadableGitCommand(t *testing.T) {
+	repo, file := setupScopeRepo(t)
+
+	// Report the global config as unreadable, the way git does for EACCES
+	fakeGit(t, "for arg in \"$@\"; do\n"+
+		"  if [ \"$arg\" = --global ]; then echo \"fatal: unable to read config file '$HOME/.gitconfig': Permission denied\" >&2; exit 128; fi\n"+
+		"done\nexec $GIT \"$@\"")
+
+	opts := []ConfigOption{WithGlobal(), WithLocal(), WithRepoPath(repo), WithGitCommand()}
+	if _, err := Load(opts...); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("Expected fs.ErrPermission without WithSkipUnreadable, got %v", err)
+	}
+
+	config, err := Load(append(opts, WithSkipUnreadable())...)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config.Has("user.name") {
+		t.Error("Expected global config to be skipped")
+	}
+	if scope, _ := Get[string](config, "shared.scope"); scope != "local" {
+		t.Errorf("Expected local config to load, got %q", scope)
+	}
+	warnings := config.Warnings()
+	if len(warnings) != 1 || !errors.Is(warnings[0], os.ErrPermission) {
+		t.Errorf("Expected one permission warning, got %v", warnings)
+	}
+
+	// A WithFile source is never skipped
+	fakeGit(t, "for arg in \"$@\"; do\n"+
+		"  if [ \"$arg\" = --file ]; then echo \"fatal: unable to read config file: Permission denied\" >&2; exit 128; fi\n"+
+		"done\nexec $GIT \"$@\"")
+	if _, err := Load(WithFile(file), WithGitCommand(), WithSkipUnreadable()); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Expected WithFile source to fail, got %v", err)
+	}
+}
+
+func TestSkipUnreadableFiles(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	repo, file := setupScopeRepo(t)
+
+	global := filepath.Join(os.Getenv("HOME"), ".gitconfig")
+	if err := os.Chmod(global, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []ConfigOption{WithGlobal(), WithLocal(), WithRepoPath(repo)}
+	if _, err := Load(opts...); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("Expected fs.ErrPermission without WithSkipUnreadable, got %v", err)
+	}
+
+	config, err := Load(append(opts, WithSkipUnreadable())...)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	for _, source := range config.GetSources() {
+		if source.Path == global {
+			t.Errorf("Skipped source %s listed in GetSources", global)
+		}
+	}
+	warnings := config.Warnings()
+	if len(warnings) != 1 || !errors.Is(warnings[0], os.ErrPermission) {
+		t.Errorf("Expected one permission warning, got %v", warnings)
+	}
+
+	if err := os.Chmod(file, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(WithFile(file), WithSkipUnreadable()); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Expected WithFile source to fail, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package gitcfg
+
+import (
+	"bytes"
+	"io"
+)
+
+// CommentLine is a comment kept by WithPreserveComments.
+type CommentLine struct {
+	Text   string // the line as written, with its # or ; and without surrounding space
+	Before string // dotted section or key of the line that follows, empty at the end of a file
+}
+
+// Comments returns the comment lines kept by WithPreserveComments, in the
+// order they were read.
+func (c *Config) Comments() []CommentLine {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	return append([]CommentLine(nil), c.comments...)
+}
+
+// WriteTo writes the configuration to w as MarshalText encodes it, with the
+// comments kept by WithPreserveComments. It implements io.WriterTo.
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	text, err := c.MarshalText()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(text)
+	if err != nil {
+		return int64(n), &ConfigError{Op: "write", Err: err}
+	}
+	return int64(n), nil
+}
+
+// attachComments records pending comment lines as preceding before and
+// returns the emptied buffer.
+func (c *Config) attachComments(pending []string, before string) []string {
+	if len(pending) == 0 {
+		return pending
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	for _, text := range pending {
+		c.comments = append(c.comments, CommentLine{Text: text, Before: before})
+	}
+	return pending[:0]
+}
+
+// isCommentLine reports whether line is a # or ; comment.
+func isCommentLine(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	return len(trimmed) > 0 && (trimmed[0] == '#' || trimmed[0] == ';')
+}
@@ -0,0 +1,111 @@
+package gitcfg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPreserveComments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	path := filepath.Join(t.TempDir(), "config")
+	writeTestFile(t, path, `# Managed by the platform team
+[user]
+	name = Test User
+	; personal address
+	email = test@example.com
+# Remotes
+[remote "origin"]
+	url = https://example.com/repo.git
+	# keep tags in sync
+	fetch = +refs/tags/*:refs/tags/*
+# end of file
+`)
+
+	config, err := Load(WithFile(path), WithPreserveComments())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	expected := []CommentLine{
+		{Text: "# Managed by the platform team", Before: "user"},
+		{Text: "; personal address", Before: "user.email"},
+		{Text: "# Remotes", Before: "remote.origin"},
+		{Text: "# keep tags in sync", Before: "remote.origin.fetch"},
+		{Text: "# end of file", Before: ""},
+	}
+	if comments := config.Comments(); !reflect.DeepEqual(comments, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, comments)
+	}
+
+	var buf bytes.Buffer
+	n, err := config.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo reported %d bytes, wrote %d", n, buf.Len())
+	}
+
+	written := filepath.Join(t.TempDir(), "written")
+	if err := os.WriteFile(written, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := Load(WithFile(written), WithPreserveComments())
+	if err != nil {
+		t.Fatalf("Load of the written config failed: %v\n%s", err, buf.String())
+	}
+	if comments := reparsed.Comments(); !reflect.DeepEqual(comments, expected) {
+		t.Errorf("Expected the comments to survive the round trip, got %+v\n%s", comments, buf.String())
+	}
+	if !reflect.DeepEqual(reparsed.GetAll(), config.GetAll()) {
+		t.Errorf("Expected the values to survive the round trip, got %v", reparsed.GetAll())
+	}
+	if clone := config.Clone(); !reflect.DeepEqual(clone.Comments(), expected) {
+		t.Errorf("Expected Clone to keep the comments, got %+v", clone.Comments())
+	}
+
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if comments := config.Comments(); !reflect.DeepEqual(comments, expected) {
+		t.Errorf("Expected Reload to reread the comments, got %+v", comments)
+	}
+
+	// A removed key's comment is kept, at the end
+	config.SetMultiValue("user.email", nil)
+	text, err := config.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText failed: %v", err)
+	}
+	if !bytes.HasSuffix(text, []byte("\n; personal address\n# end of file\n")) {
+		t.Errorf("Expected orphaned comments at the end, got:\n%s", text)
+	}
+}
+
+func TestCommentsDiscardedByDefault(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	path := filepath.Join(t.TempDir(), "config")
+	writeTestFile(t, path, "# comment\n[user]\n\tname = Test User\n")
+
+	config, err := Load(WithFile(path))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if comments := config.Comments(); len(comments) != 0 {
+		t.Errorf("Expected no comments, got %+v", comments)
+	}
+	text, err := config.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText failed: %v", err)
+	}
+	if string(text) != "[user]\n\tname = Test User\n" {
+		t.Errorf("Unexpected text:\n%s", text)
+	}
+}
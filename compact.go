@@ -0,0 +1,94 @@
+package gitcfg
+
+// listKeys are keys git reads as lists, whose every value counts.
+var listKeys = newKeyPatterns(
+	"remote.*.url", "remote.*.pushurl", "remote.*.fetch", "remote.*.push",
+	"branch.*.merge",
+	CredentialHelper, "credential.*.helper",
+	HTTPExtraHeader, "http.*.extraheader",
+	SafeDirectory,
+	"url.*.insteadof", "url.*.pushinsteadof",
+	"include.path", "includeif.*.path",
+	ObjectsAlternates,
+)
+
+// textKeys are keys that take a name, path or command, for which an empty
+// value means the same as leaving the key unset. An empty value of any other
+// key may be a boolean, which git reads as false when written with "=" and as
+// true when written without, or a list reset, so Compact keeps it.
+var textKeys = newKeyPatterns(
+	UserName, UserEmail, UserSigningKey,
+	CoreEditor, CommitTemplate, GPGProgram, MergeTool, DiffTool, InitDefaultBranch,
+	"alias.*", "mergetool.*.cmd", "difftool.*.cmd",
+)
+
+// Compact returns a copy of the config without redundant entries. Empty
+// values of keys known to take text, such as user.name and alias.*, are
+// dropped; other empty values, which may be booleans or list resets, are
+// kept. A key set several times, as happens after
+// merging files, keeps only its effective last value, except for the keys git
+// reads as lists, such as remote.<name>.fetch and credential.helper, which
+// keep every value but repeated ones. Sections left without keys are removed.
+func (c *Config) Compact() *Config {
+	clone := c.Clone()
+	clone.CompactInPlace()
+	return clone
+}
+
+// CompactInPlace is like Compact but modifies the receiver.
+func (c *Config) CompactInPlace() {
+	c.lock()
+	defer c.mu.Unlock()
+
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			values := compactValues(section, key, c.rawValues(section, key))
+			if len(values) == 0 {
+				delete(sectionMap, key)
+				delete(c.values[section], key)
+				continue
+			}
+			c.putValues(section, key, values)
+		}
+
+		if len(sectionMap) == 0 {
+			delete(c.sections, section)
+			delete(c.values, section)
+		}
+	}
+}
+
+// compactValues returns the values of a key that Compact keeps, in order.
+func compactValues(section, key string, values []configValue) []configValue {
+	last := values[len(values)-1]
+	if !listKeys.match(section, key) {
+		if isUnsetValue(section, key, last) {
+			return nil
+		}
+		return []configValue{last}
+	}
+
+	// Keep the last occurrence of a repeated value, which is where it takes
+	// effect when an empty value resets the list
+	lastIndex := make(map[string]int, len(values))
+	for i, v := range values {
+		lastIndex[v.value] = i
+	}
+	compacted := make([]configValue, 0, len(lastIndex))
+	for i, v := range values {
+		if lastIndex[v.value] != i {
+			continue
+		}
+		if isUnsetValue(section, key, v) {
+			continue
+		}
+		compacted = append(compacted, v)
+	}
+	return compacted
+}
+
+// isUnsetValue reports whether v, a value of the key, has the same effect as
+// no value at all.
+func isUnsetValue(section, key string, v configValue) bool {
+	return v.value == "" && !v.bare && textKeys.match(section, key)
+}
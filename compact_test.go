@@ -0,0 +1,77 @@
+package gitcfg
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	system := parseTestConfig(t, "[core]\n    editor = nano\n[user]\n    email =\n[remote \"origin\"]\n    fetch = +refs/heads/*:refs/remotes/origin/*\n")
+	global := parseTestConfig(t, "[core]\n    editor = vim\n    bare\n[user]\n    name = Test User\n[credential]\n    helper = store\n[alias]\n    co =\n[rerere]\n    enabled\n")
+	local := parseTestConfig(t, "[core]\n    editor = code\n[remote \"origin\"]\n    fetch = +refs/heads/*:refs/remotes/origin/*\n    fetch = +refs/tags/*:refs/tags/*\n[credential]\n    helper =\n    helper = store\n[log]\n    showSignature =\n")
+
+	config := &Config{sections: make(map[string]map[string]string)}
+	for _, layer := range []*Config{system, global, local} {
+		if err := config.Merge(layer, true); err != nil {
+			t.Fatalf("Merge failed: %v", err)
+		}
+	}
+
+	if size := config.Size(); size != 9 {
+		t.Fatalf("Expected 9 keys before Compact, got %d", size)
+	}
+
+	compacted := config.Compact()
+
+	// user.email and alias.co are empty; alias is left without keys
+	if size := compacted.Size(); size != 7 {
+		t.Errorf("Expected 7 keys after Compact, got %d: %v", size, compacted.GetKeys())
+	}
+	if compacted.HasSection("alias") {
+		t.Error("Expected the emptied alias section to be removed")
+	}
+	if config.Size() != 9 {
+		t.Error("Compact modified the receiver")
+	}
+
+	tests := []struct {
+		key    string
+		values []string
+	}{
+		{"core.editor", []string{"code"}},
+		{"core.bare", []string{""}},
+		{"user.name", []string{"Test User"}},
+		{"remote.origin.fetch", []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"}},
+		{"credential.helper", []string{"", "store"}},
+		{"rerere.enabled", []string{""}},
+		{"log.showsignature", []string{""}},
+	}
+	for _, tt := range tests {
+		values, err := compacted.GetMultiValue(tt.key)
+		if err != nil {
+			t.Errorf("GetMultiValue(%s) failed: %v", tt.key, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, tt.values) {
+			t.Errorf("GetMultiValue(%s) = %q, want %q", tt.key, values, tt.values)
+		}
+	}
+
+	// git reads the bare key as true and the empty one as false
+	var buf bytes.Buffer
+	if err := compacted.PrintTo(&buf, OutputFormatINI); err != nil {
+		t.Fatalf("PrintTo failed: %v", err)
+	}
+	for _, line := range []string{"\tenabled\n", "\tshowsignature = \n"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Expected %q in the compacted config:\n%s", line, buf.String())
+		}
+	}
+
+	config.CompactInPlace()
+	if !config.Equal(compacted) {
+		t.Errorf("CompactInPlace result differs from Compact:\n%s\nvs\n%s", config, compacted)
+	}
+}
@@ -0,0 +1,159 @@
+package gitcfg
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+// GetCoreConfig returns the core.* settings. Absent keys keep git's
+// defaults, and the file and directory settings are resolved as by
+// GetExcludesFile, GetAttributesFile and GetHooksPath.
+func (c *Config) GetCoreConfig() (*CoreConfig, error) {
+	cfg := &CoreConfig{FileMode: true}
+
+	r := &fieldReader{c: c}
+	readField(r, CoreEditor, &cfg.Editor)
+	readField(r, CorePager, &cfg.Pager)
+	readField(r, CoreAutoCRLF, &cfg.AutoCRLF)
+	readField(r, CoreFileMode, &cfg.FileMode)
+	readField(r, CoreIgnoreCase, &cfg.IgnoreCase)
+	readField(r, CoreBare, &cfg.Bare)
+	readPathField(r, CoreWorktree, &cfg.Worktree)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var err error
+	if cfg.ExcludesFile, err = c.GetExcludesFile(); err != nil {
+		return nil, err
+	}
+	if cfg.AttributesFile, err = c.GetAttributesFile(); err != nil {
+		return nil, err
+	}
+	if cfg.HooksPath, err = c.GetHooksPath(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// GetExcludesFile returns core.excludesFile with ~ expanded, or git's
+// default of $XDG_CONFIG_HOME/git/ignore (~/.config/git/ignore when
+// XDG_CONFIG_HOME is unset) if the key is not configured. The file need not
+// exist.
+func (c *Config) GetExcludesFile() (string, error) {
+	return c.getXDGPath(CoreExcludesFile, "ignore")
+}
+
+// GetAttributesFile returns core.attributesFile with ~ expanded, or git's
+// default of $XDG_CONFIG_HOME/git/attributes if the key is not configured.
+func (c *Config) GetAttributesFile() (string, error) {
+	return c.getXDGPath(CoreAttributesFile, "attributes")
+}
+
+func (c *Config) getXDGPath(key, name string) (string, error) {
+	path, err := c.GetPath(key)
+	if !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+		return path, err
+	}
+
+	xdgConfigHome, _ := c.getenv("XDG_CONFIG_HOME")
+	path, err = xdgGitPath(xdgConfigHome, name)
+	if err != nil {
+		section, subkey, _ := parseConfigKey(key)
+		return "", &ConfigError{
+			Op:      "get",
+			Key:     subkey,
+			Section: section,
+			Err:     err,
+		}
+	}
+	return path, nil
+}
+
+// GetHooksPath returns the directory git runs hooks from: core.hooksPath
+// with ~ expanded, where a relative path is resolved against the git
+// directory, or <gitdir>/hooks when unset. The git directory is taken from
+// the local configuration source; without one, an unset hooksPath yields ""
+// and a relative one is resolved against the working directory.
+func (c *Config) GetHooksPath() (string, error) {
+	gitDir := c.gitDir()
+
+	raw, err := Get[string](c, CoreHooksPath)
+	if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrSectionNotFound) {
+		if gitDir == "" {
+			return "", nil
+		}
+		return filepath.Join(gitDir, "hooks"), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	path, err := expandPathRelativeTo(raw, gitDir)
+	if err != nil {
+		return "", &ConfigError{
+			Op:      "get",
+			Key:     "hookspath",
+			Section: "core",
+			Err:     err,
+		}
+	}
+	return path, nil
+}
+
+// gitDir returns the directory holding the local config file, if the config
+// was loaded from one.
+func (c *Config) gitDir() string {
+	for _, source := range c.GetSources() {
+		if source.Type == SourceTypeLocal && source.Path != "" {
+			return filepath.Dir(source.Path)
+		}
+	}
+	return ""
+}
+
+// GetCoreExcludesFile returns core.excludesFile with ~ expanded and made
+// absolute. Unlike GetExcludesFile it does not fall back to git's default,
+// and the file must exist; a missing file is reported with an error wrapping
+// fs.ErrNotExist.
+func (c *Config) GetCoreExcludesFile() (string, error) {
+	return c.getExistingPath(CoreExcludesFile, "")
+}
+
+// GetCoreAttributesFile is GetCoreExcludesFile for core.attributesFile.
+func (c *Config) GetCoreAttributesFile() (string, error) {
+	return c.getExistingPath(CoreAttributesFile, "")
+}
+
+// GetCoreHooksPath is GetCoreExcludesFile for core.hooksPath. A relative
+// path is resolved against the git directory, as by GetHooksPath.
+func (c *Config) GetCoreHooksPath() (string, error) {
+	return c.getExistingPath(CoreHooksPath, c.gitDir())
+}
+
+func (c *Config) getExistingPath(key, base string) (string, error) {
+	raw, err := Get[string](c, key)
+	if err != nil {
+		return "", err
+	}
+
+	var path string
+	if base != "" {
+		if path, err = expandPathRelativeTo(raw, base); err == nil {
+			path, err = expandAndValidatePath(path)
+		}
+	} else {
+		path, err = expandAndValidatePath(raw)
+	}
+	if err != nil {
+		section, subkey, _ := parseConfigKey(key)
+		return "", &ConfigError{
+			Op:      "get",
+			Key:     subkey,
+			Section: section,
+			Err:     err,
+		}
+	}
+	return path, nil
+}
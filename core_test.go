@@ -0,0 +1,189 @@
+package gitcfg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetExcludesFileXDGDefault(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Test User`)
+	config.lookupEnv = envMap(map[string]string{"XDG_CONFIG_HOME": "/xdg"})
+
+	path, err := config.GetExcludesFile()
+	if err != nil {
+		t.Fatalf("GetExcludesFile() error = %v", err)
+	}
+	if want := filepath.Join("/xdg", "git", "ignore"); path != want {
+		t.Errorf("GetExcludesFile() = %q, want %q", path, want)
+	}
+
+	path, err = config.GetAttributesFile()
+	if err != nil {
+		t.Fatalf("GetAttributesFile() error = %v", err)
+	}
+	if want := filepath.Join("/xdg", "git", "attributes"); path != want {
+		t.Errorf("GetAttributesFile() = %q, want %q", path, want)
+	}
+}
+
+func TestGetExcludesFileHomeDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := parseTestConfig(t, `[user]
+    name = Test User`)
+	config.lookupEnv = envMap(nil)
+
+	path, err := config.GetExcludesFile()
+	if err != nil {
+		t.Fatalf("GetExcludesFile() error = %v", err)
+	}
+	if want := filepath.Join(home, ".config", "git", "ignore"); path != want {
+		t.Errorf("GetExcludesFile() = %q, want %q", path, want)
+	}
+}
+
+func TestGetExcludesFileTilde(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := parseTestConfig(t, `[core]
+    excludesFile = ~/.gitignore_global
+    attributesFile = ~/.gitattributes`)
+	config.lookupEnv = envMap(map[string]string{"XDG_CONFIG_HOME": "/xdg"})
+
+	path, err := config.GetExcludesFile()
+	if err != nil {
+		t.Fatalf("GetExcludesFile() error = %v", err)
+	}
+	if want := filepath.Join(home, ".gitignore_global"); path != want {
+		t.Errorf("GetExcludesFile() = %q, want %q", path, want)
+	}
+
+	path, err = config.GetAttributesFile()
+	if err != nil {
+		t.Fatalf("GetAttributesFile() error = %v", err)
+	}
+	if want := filepath.Join(home, ".gitattributes"); path != want {
+		t.Errorf("GetAttributesFile() = %q, want %q", path, want)
+	}
+}
+
+func TestGetHooksPath(t *testing.T) {
+	gitDir := t.TempDir()
+	configPath := filepath.Join(gitDir, "config")
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unset", "[core]\n\tbare = false\n", filepath.Join(gitDir, "hooks")},
+		{"relative", "[core]\n\thooksPath = .githooks\n", filepath.Join(gitDir, ".githooks")},
+		{"absolute", "[core]\n\thooksPath = /opt/hooks\n", "/opt/hooks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configPath, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			source := ConfigSource{Type: SourceTypeLocal, Path: configPath}
+			config := &Config{
+				sections: make(map[string]map[string]string),
+				sources:  []ConfigSource{source},
+			}
+			if err := newParser().parseConfigFile(source, config); err != nil {
+				t.Fatalf("parseConfigFile() error = %v", err)
+			}
+
+			path, err := config.GetHooksPath()
+			if err != nil {
+				t.Fatalf("GetHooksPath() error = %v", err)
+			}
+			if path != tt.want {
+				t.Errorf("GetHooksPath() = %q, want %q", path, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCoreConfig(t *testing.T) {
+	config := parseTestConfig(t, `[core]
+    editor = vim
+    autocrlf = input
+    ignoreCase = true
+    excludesFile = /etc/gitignore`)
+
+	core, err := config.GetCoreConfig()
+	if err != nil {
+		t.Fatalf("GetCoreConfig() error = %v", err)
+	}
+	if core.Editor != "vim" || core.AutoCRLF != "input" || !core.IgnoreCase {
+		t.Errorf("GetCoreConfig() = %+v", core)
+	}
+	if !core.FileMode {
+		t.Error("FileMode should default to true")
+	}
+	if core.ExcludesFile != "/etc/gitignore" {
+		t.Errorf("ExcludesFile = %q, want /etc/gitignore", core.ExcludesFile)
+	}
+	if core.HooksPath != "" {
+		t.Errorf("HooksPath = %q, want empty without a local source", core.HooksPath)
+	}
+}
+
+func TestGetCoreExcludesFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config := parseTestConfig(t, `[core]
+    excludesFile = ~/.gitignore_global
+    attributesFile = ~/missing_attributes`)
+
+	if _, err := config.GetCoreExcludesFile(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("GetCoreExcludesFile() error = %v, want fs.ErrNotExist", err)
+	}
+
+	ignore := filepath.Join(home, ".gitignore_global")
+	if err := os.WriteFile(ignore, []byte("*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := config.GetCoreExcludesFile()
+	if err != nil {
+		t.Fatalf("GetCoreExcludesFile() error = %v", err)
+	}
+	if path != ignore {
+		t.Errorf("GetCoreExcludesFile() = %q, want %q", path, ignore)
+	}
+
+	if _, err := config.GetCoreAttributesFile(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("GetCoreAttributesFile() error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := config.GetCoreHooksPath(); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetCoreHooksPath() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestGetCoreHooksPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	hooks := filepath.Join(home, "hooks")
+	if err := os.Mkdir(hooks, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	config := parseTestConfig(t, "[core]\n    hooksPath = ~/hooks\n")
+	path, err := config.GetCoreHooksPath()
+	if err != nil {
+		t.Fatalf("GetCoreHooksPath() error = %v", err)
+	}
+	if path != hooks {
+		t.Errorf("GetCoreHooksPath() = %q, want %q", path, hooks)
+	}
+}
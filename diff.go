@@ -0,0 +1,288 @@
+package gitcfg
+
+import (
+	"cmp"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DiffEntry describes a single key that differs between two configurations.
+// Values hold every value of the key in load order, so changes to multi-valued
+// keys are reported as well.
+type DiffEntry struct {
+	Key       string
+	OldValues []string
+	NewValues []string
+}
+
+// OldValue returns the effective (last) old value, or "" if the key was added.
+func (e DiffEntry) OldValue() string {
+	return lastValue(e.OldValues)
+}
+
+// NewValue returns the effective (last) new value, or "" if the key was removed.
+func (e DiffEntry) NewValue() string {
+	return lastValue(e.NewValues)
+}
+
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// ConfigDiff is the set of differences between two configurations, each list
+// sorted by key. It is the canonical change representation used throughout
+// the package.
+type ConfigDiff struct {
+	Added   []DiffEntry
+	Removed []DiffEntry
+	Changed []DiffEntry
+}
+
+// IsEmpty reports whether the two configurations were equal.
+func (d ConfigDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares two configurations by key and value, ignoring sources.
+func Diff(old, new *Config) ConfigDiff {
+	return diffEntries(old.flatValues(), new.flatValues())
+}
+
+// ChangeOp is the kind of change IterateChanges reports for a key.
+type ChangeOp int
+
+const (
+	// The key is set in the receiver but not in the baseline.
+	ChangeAdded ChangeOp = iota
+	// The key is set in the baseline but not in the receiver.
+	ChangeRemoved
+	// Both set the key, with different values.
+	ChangeModified
+)
+
+func (op ChangeOp) String() string {
+	switch op {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// IterateChanges calls fn for every key that differs between baseline and
+// the receiver, in sorted key order, with the effective old and new values;
+// a key is modified when any of its values differ, as in Diff. It is the
+// streaming counterpart of Diff(baseline, c): it sorts an index of each
+// config's keys but copies no values and builds no list of changes.
+// Iteration stops at the first error fn returns, which IterateChanges
+// returns.
+//
+// The baseline's index is taken under its read lock before iteration
+// starts, so only the receiver's read lock is held while fn runs and
+// baseline may be the receiver itself. fn must not modify the receiver.
+func (c *Config) IterateChanges(baseline *Config, fn func(op ChangeOp, key, oldVal, newVal string) error) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	var old []indexedKey
+	if baseline != nil {
+		if err := baseline.EnsureLoaded(); err != nil {
+			return err
+		}
+		baseline.rlock()
+		old = baseline.keyIndex()
+		baseline.mu.RUnlock()
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	cur := c.keyIndex()
+	i, j := 0, 0
+	for i < len(old) || j < len(cur) {
+		order := 0
+		switch {
+		case j == len(cur):
+			order = -1
+		case i == len(old):
+			order = 1
+		default:
+			order = compareIndexedKeys(old[i], cur[j])
+		}
+
+		switch {
+		case order < 0:
+			if err := fn(ChangeRemoved, old[i].String(), old[i].last(), ""); err != nil {
+				return err
+			}
+			i++
+		case order > 0:
+			if err := fn(ChangeAdded, cur[j].String(), "", cur[j].last()); err != nil {
+				return err
+			}
+			j++
+		default:
+			equal := slices.EqualFunc(old[i].values, cur[j].values, func(o, n configValue) bool { return o.value == n.value })
+			if !equal {
+				if err := fn(ChangeModified, cur[j].String(), old[i].last(), cur[j].last()); err != nil {
+					return err
+				}
+			}
+			i++
+			j++
+		}
+	}
+	return nil
+}
+
+// indexedKey is a key in the index IterateChanges walks. values refers to
+// the config's own slice, which is replaced rather than modified on update.
+type indexedKey struct {
+	section, key string
+	values       []configValue
+}
+
+func (k indexedKey) String() string {
+	return k.section + "." + k.key
+}
+
+func (k indexedKey) last() string {
+	return k.values[len(k.values)-1].value
+}
+
+// keyIndex returns every key sorted as its dotted form. Callers must hold
+// the lock.
+func (c *Config) keyIndex() []indexedKey {
+	index := make([]indexedKey, 0, len(c.sections))
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			index = append(index, indexedKey{section, key, c.rawValues(section, key)})
+		}
+	}
+	sort.Slice(index, func(i, j int) bool { return compareIndexedKeys(index[i], index[j]) < 0 })
+	return index
+}
+
+// compareIndexedKeys compares the dotted forms of a and b without building
+// them.
+func compareIndexedKeys(a, b indexedKey) int {
+	la, lb := len(a.section)+1+len(a.key), len(b.section)+1+len(b.key)
+	for n := 0; n < la && n < lb; n++ {
+		if ca, cb := a.byteAt(n), b.byteAt(n); ca != cb {
+			if ca < cb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return cmp.Compare(la, lb)
+}
+
+// byteAt returns the nth byte of the key's dotted form.
+func (k indexedKey) byteAt(n int) byte {
+	switch {
+	case n < len(k.section):
+		return k.section[n]
+	case n == len(k.section):
+		return '.'
+	default:
+		return k.key[n-len(k.section)-1]
+	}
+}
+
+// Equal reports whether both configurations hold the same keys and values,
+// ignoring where they were loaded from.
+func (c *Config) Equal(other *Config) bool {
+	if c == other {
+		return true
+	}
+	return Diff(c, other).IsEmpty()
+}
+
+// Flatten returns the effective value of every key, fully qualified the way
+// git config --list prints it, e.g. "remote.origin.url". A multi-valued key
+// maps to its last value.
+func (c *Config) Flatten() map[string]string {
+	flat := make(map[string]string)
+	for key, values := range c.flatValues() {
+		flat[key] = lastValue(values)
+	}
+	return flat
+}
+
+// FlattenJoined is like Flatten but maps a multi-valued key to all of its
+// values in load order, joined with sep.
+func (c *Config) FlattenJoined(sep string) map[string]string {
+	flat := make(map[string]string)
+	for key, values := range c.flatValues() {
+		flat[key] = strings.Join(values, sep)
+	}
+	return flat
+}
+
+// Hash returns Checksum hex-encoded, for logs and cache keys. Two
+// configurations that are Equal have the same hash.
+func (c *Config) Hash() string {
+	sum := c.Checksum()
+	return hex.EncodeToString(sum[:])
+}
+
+// flatValues copies every key, fully qualified, with all of its values.
+func (c *Config) flatValues() map[string][]string {
+	if c == nil {
+		return nil
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	flat := make(map[string][]string)
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			flat[section+"."+key] = valueStrings(c.rawValues(section, key))
+		}
+	}
+	return flat
+}
+
+func diffEntries(old, new map[string][]string) ConfigDiff {
+	var diff ConfigDiff
+
+	for key, oldValues := range old {
+		newValues, exists := new[key]
+		switch {
+		case !exists:
+			diff.Removed = append(diff.Removed, DiffEntry{Key: key, OldValues: oldValues})
+		case !slices.Equal(oldValues, newValues):
+			diff.Changed = append(diff.Changed, DiffEntry{Key: key, OldValues: oldValues, NewValues: newValues})
+		}
+	}
+
+	for key, newValues := range new {
+		if _, exists := old[key]; !exists {
+			diff.Added = append(diff.Added, DiffEntry{Key: key, NewValues: newValues})
+		}
+	}
+
+	sortEntries(diff.Added)
+	sortEntries(diff.Removed)
+	sortEntries(diff.Changed)
+
+	return diff
+}
+
+func sortEntries(entries []DiffEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+}
@@ -0,0 +1,224 @@
+package gitcfg
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := parseTestConfig(t, `[user]
+    name = Test User
+    email = old@example.com
+[core]
+    editor = vim
+[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*
+`)
+	new := parseTestConfig(t, `[user]
+    name = Test User
+    email = new@example.com
+[init]
+    defaultBranch = main
+[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+`)
+
+	diff := Diff(old, new)
+	if diff.IsEmpty() {
+		t.Fatal("Expected non-empty diff")
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].Key != "init.defaultbranch" || diff.Added[0].NewValue() != "main" {
+		t.Errorf("Unexpected added entries: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "core.editor" || diff.Removed[0].OldValue() != "vim" {
+		t.Errorf("Unexpected removed entries: %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 2 {
+		t.Fatalf("Expected 2 changed entries, got %+v", diff.Changed)
+	}
+	if diff.Changed[0].Key != "remote.origin.fetch" || len(diff.Changed[0].NewValues) != 2 {
+		t.Errorf("Expected multi-value change for remote.origin.fetch, got %+v", diff.Changed[0])
+	}
+	if diff.Changed[1].Key != "user.email" || diff.Changed[1].OldValue() != "old@example.com" || diff.Changed[1].NewValue() != "new@example.com" {
+		t.Errorf("Unexpected change for user.email: %+v", diff.Changed[1])
+	}
+}
+
+func TestDiffIdentical(t *testing.T) {
+	data := "[user]\n    name = Test User\n"
+	a := parseTestConfig(t, data)
+	b := parseTestConfig(t, data)
+	b.sources = []ConfigSource{{Type: SourceTypeLocal, Path: "/repo/.git/config"}}
+
+	if diff := Diff(a, b); !diff.IsEmpty() {
+		t.Errorf("Expected empty diff, got %+v", diff)
+	}
+	if !a.Equal(b) {
+		t.Error("Expected configs to be equal ignoring sources")
+	}
+
+	b.Set("user.name", "Other User")
+	if a.Equal(b) {
+		t.Error("Expected configs to differ after Set")
+	}
+}
+
+func TestFlattenAndHash(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Test User
+[remote "origin"]
+    url = https://example.com/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+`)
+
+	expected := map[string]string{
+		"user.name":           "Test User",
+		"remote.origin.url":   "https://example.com/repo.git",
+		"remote.origin.fetch": "+refs/tags/*:refs/tags/*",
+	}
+	if flat := config.Flatten(); !reflect.DeepEqual(flat, expected) {
+		t.Errorf("Expected %v, got %v", expected, flat)
+	}
+
+	joined := config.FlattenJoined(",")
+	if fetch := joined["remote.origin.fetch"]; fetch != "+refs/heads/*:refs/remotes/origin/*,+refs/tags/*:refs/tags/*" {
+		t.Errorf("Expected joined fetch values, got %q", fetch)
+	}
+
+	hash := config.Hash()
+	if len(hash) != 64 {
+		t.Fatalf("Expected a hex SHA-256, got %q", hash)
+	}
+	if sum := config.Checksum(); hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected Hash to encode Checksum, got %s", hash)
+	}
+	if again := config.Clone().Hash(); again != hash {
+		t.Errorf("Expected a stable hash, got %s and %s", hash, again)
+	}
+
+	// Only the first fetch value differs, which Flatten cannot see
+	other := parseTestConfig(t, `[user]
+    name = Test User
+[remote "origin"]
+    url = https://example.com/repo.git
+    fetch = +refs/heads/main:refs/remotes/origin/main
+    fetch = +refs/tags/*:refs/tags/*
+`)
+	if other.Hash() == hash {
+		t.Error("Expected a change to a multi-valued key to change the hash")
+	}
+
+	// Moving bytes between a key and its value must not collide
+	a := parseTestConfig(t, "[a]\n    b = c\n")
+	b := parseTestConfig(t, "[a]\n    bc = \n")
+	if a.Hash() == b.Hash() {
+		t.Error("Expected distinct hashes for distinct configs")
+	}
+}
+
+func TestIterateChanges(t *testing.T) {
+	baseline := parseTestConfig(t, `[user]
+    name = Test User
+    email = old@example.com
+[core]
+    editor = vim
+[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*
+`)
+	config := parseTestConfig(t, `[user]
+    name = Test User
+    email = new@example.com
+[alias]
+    co = checkout
+[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+[zeta]
+    key = value
+`)
+
+	type change struct {
+		op            ChangeOp
+		key, old, new string
+	}
+	var changes []change
+	err := config.IterateChanges(baseline, func(op ChangeOp, key, oldVal, newVal string) error {
+		changes = append(changes, change{op, key, oldVal, newVal})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateChanges failed: %v", err)
+	}
+
+	expected := []change{
+		{ChangeAdded, "alias.co", "", "checkout"},
+		{ChangeRemoved, "core.editor", "vim", ""},
+		{ChangeModified, "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"},
+		{ChangeModified, "user.email", "old@example.com", "new@example.com"},
+		{ChangeAdded, "zeta.key", "", "value"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, changes)
+	}
+	if changes[2].op.String() != "modified" {
+		t.Errorf("Expected 'modified', got '%s'", changes[2].op)
+	}
+
+	// The same keys as Diff reports
+	diff := Diff(baseline, config)
+	if got, want := len(changes), len(diff.Added)+len(diff.Removed)+len(diff.Changed); got != want {
+		t.Errorf("Expected %d changes as in Diff, got %d", want, got)
+	}
+
+	stop := errors.New("stop")
+	var seen []string
+	err = config.IterateChanges(baseline, func(op ChangeOp, key, oldVal, newVal string) error {
+		seen = append(seen, key)
+		if len(seen) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the callback's error, got %v", err)
+	}
+	if !reflect.DeepEqual(seen, []string{"alias.co", "core.editor"}) {
+		t.Errorf("Expected iteration to stop after two keys, got %q", seen)
+	}
+
+	// Comparing a config with itself reports nothing and does not deadlock
+	err = config.IterateChanges(config, func(op ChangeOp, key, oldVal, newVal string) error {
+		t.Errorf("Unexpected change %s %s", op, key)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("IterateChanges failed: %v", err)
+	}
+}
+
+func TestIterateChangesOrder(t *testing.T) {
+	// "a-b.y" sorts before "a.x" although section "a" sorts before "a-b"
+	config := parseTestConfig(t, "[a]\n    x = 1\n[a-b]\n    y = 2\n[remote \"o\"]\n    url = u\n[remote \"o.x\"]\n    url = v\n")
+
+	var keys []string
+	err := config.IterateChanges(nil, func(op ChangeOp, key, oldVal, newVal string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IterateChanges failed: %v", err)
+	}
+
+	var expected []string
+	for _, entry := range Diff(&Config{}, config).Added {
+		expected = append(expected, entry.Key)
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected the order of Diff %q, got %q", expected, keys)
+	}
+}
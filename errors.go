@@ -3,21 +3,35 @@ package gitcfg
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"strings"
 )
 
 var (
-	ErrKeyNotFound      = errors.New("key not found")
-	ErrSectionNotFound  = errors.New("section not found")
-	ErrInvalidKeyFormat = errors.New("invalid key format")
-	ErrInvalidValue     = errors.New("invalid value")
+	ErrKeyNotFound           = errors.New("key not found")
+	ErrSectionNotFound       = errors.New("section not found")
+	ErrInvalidKeyFormat      = errors.New("invalid key format")
+	ErrInvalidValue          = errors.New("invalid value")
+	ErrNoSource              = errors.New("value has no source file")
+	ErrPartialConfig         = errors.New("config is a filtered subset of its sources")
+	ErrIdentityNotConfigured = errors.New("identity not configured")
+	ErrGitNotFound           = errors.New("git executable not found")
+	ErrNotARepository        = errors.New("not a git repository")
+	ErrGitTimeout            = errors.New("git command timed out")
+	ErrLimitExceeded         = errors.New("limit exceeded")
+	ErrUnknownKey            = errors.New("unknown key")
+	ErrNotLoaded             = errors.New("config was not produced by Load")
+	ErrSourceNotFound        = errors.New("source not found")
 )
 
 type ConfigError struct {
 	Op      string
 	Key     string
 	Section string
-	Source  string
+	Source  string // path of the file involved, if any
+	Line    int    // 1-based line in Source, or 0 when unknown
+	Column  int    // 1-based byte column in Line, or 0 when unknown
 	Err     error
 }
 
@@ -30,18 +44,99 @@ func (e *ConfigError) Error() string {
 		parts = append(parts, e.Key)
 	}
 
-	if e.Source != "" {
-		parts = append(parts, fmt.Sprintf("(source: %s)", e.Source))
+	if location := e.location(); location != "" {
+		parts = append(parts, fmt.Sprintf("(source: %s)", location))
 	}
 
 	parts = append(parts, e.Err.Error())
 	return strings.Join(parts, " ")
 }
 
+// location formats Source, Line and Column as path:line:column, leaving out
+// the parts that are unknown.
+func (e *ConfigError) location() string {
+	location := e.Source
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			location += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return location
+}
+
+// LogValue makes slog record a ConfigError as a group of its non-empty fields
+// rather than as one formatted string.
+func (e *ConfigError) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("op", e.Op)}
+	if e.Key != "" {
+		attrs = append(attrs, slog.String("key", e.Key))
+	}
+	if e.Section != "" {
+		attrs = append(attrs, slog.String("section", e.Section))
+	}
+	if e.Source != "" {
+		attrs = append(attrs, slog.String("source", e.Source))
+	}
+	if e.Line > 0 {
+		attrs = append(attrs, slog.Int("line", e.Line))
+	}
+	if e.Column > 0 {
+		attrs = append(attrs, slog.Int("column", e.Column))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
 func (e *ConfigError) Unwrap() error {
 	return e.Err
 }
 
+// ParseError is the ConfigError returned for a config file that cannot be
+// read, with the file and line split out for editors and linters. It
+// unwraps to its ConfigError, so errors.As with a *ConfigError target and
+// errors.Is with the sentinels keep working.
+type ParseError struct {
+	ConfigError
+	FileName   string // path of the file, as in Source
+	LineNumber int    // 1-based line, as in Line, or 0 when unknown
+}
+
+func newParseError(e ConfigError) *ParseError {
+	return &ParseError{ConfigError: e, FileName: e.Source, LineNumber: e.Line}
+}
+
+func (e *ParseError) Unwrap() error {
+	return &e.ConfigError
+}
+
+// ConversionError reports a value that cannot be read as the Go type asked
+// for, as returned inside a ConfigError by Get and its variants. It wraps
+// ErrInvalidValue.
+type ConversionError struct {
+	Value string // the raw value
+	Type  string // the requested type, e.g. int
+	Hint  string // a suggested accessor when the value looks like a size or duration
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	msg := fmt.Sprintf("cannot convert %q to %s", e.Value, e.Type)
+	if errors.Is(e.Err, strconv.ErrRange) {
+		msg += ": value out of range"
+	}
+	if e.Hint != "" {
+		msg += "; " + e.Hint
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
 func (e *ConfigError) Is(target error) bool {
 	var targetErr *ConfigError
 	if !errors.As(target, &targetErr) {
@@ -49,4 +144,3 @@ func (e *ConfigError) Is(target error) bool {
 	}
 	return e.Op == targetErr.Op && e.Key == targetErr.Key && e.Section == targetErr.Section
 }
-
@@ -0,0 +1,83 @@
+package gitcfg
+
+import (
+	"strings"
+)
+
+// Filter returns a new Config holding only the keys under the given section
+// or section.subsection prefixes. Filter("remote", "url") keeps every remote
+// and URL rewrite, while Filter("remote.origin") keeps just that remote.
+//
+// Per-value provenance is preserved and only sources that still contribute a
+// value are listed. The result is a deep copy independent of the receiver;
+// since it no longer reflects its sources in full, Reload on it fails with
+// ErrPartialConfig.
+func (c *Config) Filter(prefixes ...string) *Config {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	filtered := &Config{
+		sections:  make(map[string]map[string]string),
+		sources:   make([]ConfigSource, 0),
+		partial:   true,
+		redaction: c.redaction,
+	}
+
+	normalized := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if prefix = normalizeSectionPrefix(prefix); prefix != "" {
+			normalized = append(normalized, prefix)
+		}
+	}
+
+	used := make(map[ConfigSource]bool)
+	for section, sectionMap := range c.sections {
+		if !matchesSectionPrefix(section, normalized) {
+			continue
+		}
+
+		filtered.sections[section] = make(map[string]string, len(sectionMap))
+		for key := range sectionMap {
+			values := append([]configValue(nil), c.rawValues(section, key)...)
+			for _, value := range values {
+				if value.source != nil {
+					used[*value.source] = true
+				}
+			}
+			filtered.putValues(section, key, values)
+		}
+	}
+
+	for _, source := range c.sources {
+		if used[source] {
+			filtered.sources = append(filtered.sources, source)
+		}
+	}
+
+	return filtered
+}
+
+// normalizeSectionPrefix lowercases the section part of a prefix while
+// leaving any subsection untouched, matching how keys are stored.
+func normalizeSectionPrefix(prefix string) string {
+	prefix = strings.TrimSpace(prefix)
+	section, subsection, found := strings.Cut(prefix, ".")
+	section = strings.ToLower(section)
+	if !found {
+		return section
+	}
+	return section + "." + subsection
+}
+
+func matchesSectionPrefix(section string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if section == prefix {
+			return true
+		}
+		// A bare section name also matches all of its subsections
+		if !strings.Contains(prefix, ".") && strings.HasPrefix(section, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package gitcfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestConfigFilter(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Test User
+[remote "origin"]
+    url = https://github.com/user/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+[remote "upstream"]
+    url = https://github.com/upstream/repo.git
+[url "git@github.com:"]
+    insteadOf = https://github.com/
+[http]
+    proxy = http://proxy.example.com:8080
+`)
+
+	filtered := config.Filter("remote", "URL")
+
+	expected := []string{
+		"remote.origin.fetch",
+		"remote.origin.url",
+		"remote.upstream.url",
+		"url.git@github.com:.insteadof",
+	}
+	keys := filtered.GetKeys()
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+
+	fetch, err := filtered.GetMultiValue("remote.origin.fetch")
+	if err != nil {
+		t.Fatalf("GetMultiValue failed: %v", err)
+	}
+	if len(fetch) != 2 {
+		t.Errorf("Expected both fetch values, got %v", fetch)
+	}
+
+	origin := config.Filter("remote.origin")
+	if !origin.HasSection("remote.origin") || origin.HasSection("remote.upstream") {
+		t.Errorf("Expected only remote.origin, got %v", origin.GetSections())
+	}
+
+	if empty := config.Filter(); empty.Size() != 0 {
+		t.Errorf("Expected empty config, got %d keys", empty.Size())
+	}
+}
+
+func TestConfigFilterIndependent(t *testing.T) {
+	config := parseTestConfig(t, "[remote \"origin\"]\n    url = https://github.com/user/repo.git\n")
+
+	filtered := config.Filter("remote")
+	if err := filtered.Set("remote.origin.url", "changed"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if url, _ := Get[string](config, "remote.origin.url"); url != "https://github.com/user/repo.git" {
+		t.Errorf("Original config was modified: %s", url)
+	}
+}
+
+func TestConfigFilterProvenance(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalPath := filepath.Join(tmpDir, "global")
+	localPath := filepath.Join(tmpDir, "local")
+
+	if err := os.WriteFile(globalPath, []byte("[user]\n    name = Test User\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(localPath, []byte("[remote \"origin\"]\n    url = https://github.com/user/repo.git\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config := &Config{sections: make(map[string]map[string]string)}
+	parser := newParser()
+	for _, source := range []ConfigSource{
+		{Type: SourceTypeGlobal, Path: globalPath},
+		{Type: SourceTypeLocal, Path: localPath},
+	} {
+		if err := parser.parseConfigFile(source, config); err != nil {
+			t.Fatalf("Failed to parse %s: %v", source.Path, err)
+		}
+		config.sources = append(config.sources, source)
+	}
+
+	filtered := config.Filter("remote")
+
+	path, err := filtered.WhichFile("remote.origin.url")
+	if err != nil {
+		t.Fatalf("WhichFile failed: %v", err)
+	}
+	if path != localPath {
+		t.Errorf("Expected %s, got %s", localPath, path)
+	}
+
+	expected := []ConfigSource{{Type: SourceTypeLocal, Path: localPath}}
+	if sources := filtered.GetSources(); !reflect.DeepEqual(sources, expected) {
+		t.Errorf("Expected sources %v, got %v", expected, sources)
+	}
+
+	if err := filtered.Reload(); !errors.Is(err, ErrPartialConfig) {
+		t.Errorf("Expected ErrPartialConfig from Reload, got %v", err)
+	}
+	if err := filtered.Clone().Reload(); !errors.Is(err, ErrPartialConfig) {
+		t.Errorf("Expected ErrPartialConfig from Reload of clone, got %v", err)
+	}
+	if err := config.Reload(); err != nil {
+		t.Errorf("Reload of original failed: %v", err)
+	}
+}
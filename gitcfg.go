@@ -2,9 +2,14 @@ package gitcfg
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -16,6 +21,12 @@ const (
 	SourceTypeLocal
 	// Worktree-specific Git configuration (.git/config.worktree).
 	SourceTypeWorktree
+	// Fallback values supplied with WithDefaults.
+	SourceTypeDefault
+	// Submodule definitions tracked in the repository (.gitmodules).
+	SourceTypeGitmodules
+	// An explicit file added with WithFile.
+	SourceTypeCustom
 )
 
 type Constraint interface {
@@ -25,8 +36,10 @@ type Constraint interface {
 }
 
 type User struct {
-	Name  string
-	Email string
+	Name          string
+	Email         string
+	SigningKey    string // user.signingKey, filled by GetEffectiveUser and GetUserExtended
+	UseConfigOnly bool   // user.useConfigOnly, filled by GetUserExtended
 }
 
 type ConfigSource struct {
@@ -34,6 +47,15 @@ type ConfigSource struct {
 	Path string
 }
 
+// ValueWithSource is one occurrence of a multi-valued key together with where
+// it was set. Source is the zero ConfigSource and Line is 0 for values set
+// programmatically; Line is also 0 for values read through the git command.
+type ValueWithSource struct {
+	Value  string
+	Source ConfigSource
+	Line   int
+}
+
 type ConfigSourceType int
 
 func (t ConfigSourceType) String() string {
@@ -46,40 +68,122 @@ func (t ConfigSourceType) String() string {
 		return "local"
 	case SourceTypeWorktree:
 		return "worktree"
+	case SourceTypeDefault:
+		return "default"
+	case SourceTypeGitmodules:
+		return "gitmodules"
+	case SourceTypeCustom:
+		return "file"
 	default:
 		return "unknown"
 	}
 }
 
+// Config holds parsed git configuration and is safe for concurrent use.
+//
+// Read methods differ in what they hand back. Get, Has and the typed
+// accessors copy nothing beyond their result. GetSection, GetSectionMap,
+// GetAll and Clone return copies that the caller owns. ForEachInSection
+// visits a section in place without copying, under the read lock. The
+// iterators All and Section walk a sorted snapshot of the entries.
 type Config struct {
-	mu       sync.RWMutex
-	sections map[string]map[string]string
-	sources  []ConfigSource
+	mu        sync.RWMutex
+	sections  map[string]map[string]string
+	values    map[string]map[string][]configValue // every value of a key in load order
+	sources   []ConfigSource
+	defaults  map[string]string
+	partial   bool                        // a subset produced by Filter that cannot be reloaded
+	redaction *redactor                   // set by WithRedaction; masks credentials in String
+	lookupEnv func(string) (string, bool) // set by WithEnv; defaults to os.LookupEnv
+	lazy      *lazyState                  // pending load for WithLazyLoad, nil otherwise
+	warnings  []error                     // git stderr lines and skipped sources from loading
+	stats     []SourceStat                // per-source statistics captured by Load and Reload
+	comments  []CommentLine               // kept by WithPreserveComments
+
+	maxLineLength int          // set by WithMaxLineLength, reused by Reload
+	limits        Limits       // set by WithLimits, reused by Reload
+	strict        bool         // set by WithStrictMode, reused by Reload
+	logger        *slog.Logger // set by WithLogger, reused by Reload
+
+	load *configOptions // options of the Load that produced the config, reused by VerifyAgainstGit
 }
 
+// configValue is a single occurrence of a key together with the source that
+// set it. source is nil for values set programmatically; line is the 1-based
+// line in the source file, or 0 when unknown.
+type configValue struct {
+	value  string
+	source *ConfigSource
+	line   int
+	bare   bool // written as a key without "=", which git reads as true
+}
+
+// String renders the configuration in git config format. Configs loaded with
+// WithRedaction are rendered as by Redacted.
 func (c *Config) String() string {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 
+	return c.render(c.redaction)
+}
+
+// render formats the configuration, masking credentials when r is non-nil.
+// Callers must hold the lock.
+func (c *Config) render(r *redactor) string {
 	var sb strings.Builder
 
 	if len(c.sources) > 0 {
 		sb.WriteString("# Configuration sources:\n")
+		stats := statsBySource(c.stats)
 		for _, source := range c.sources {
+			if stat, ok := stats[source]; ok {
+				noun := "keys"
+				if stat.KeyCount == 1 {
+					noun = "key"
+				}
+				sb.WriteString(fmt.Sprintf("# %s: %s (%d %s)\n", source.Type, source.Path, stat.KeyCount, noun))
+				continue
+			}
 			sb.WriteString(fmt.Sprintf("# %s: %s\n", source.Type, source.Path))
 		}
 		sb.WriteString("\n")
 	}
 
+	// Size the buffer up front: "[section]\n", "  key = value\n" per key and
+	// a blank line per section, plus some slack for quoting
+	size := 0
 	for section, sectionMap := range c.sections {
-		sb.WriteString(fmt.Sprintf("[%s]\n", section))
+		size += len(section) + 4
 		for key, value := range sectionMap {
+			size += len(key) + len(value) + 6
+		}
+	}
+	sb.Grow(size + size/8)
+
+	for _, section := range c.sortedSections() {
+		sectionMap := c.sections[section]
+		name := section
+		if r != nil {
+			name = r.section(section)
+		}
+		sb.WriteString("[")
+		sb.WriteString(name)
+		sb.WriteString("]\n")
+		for _, key := range sortedSectionKeys(sectionMap) {
+			value := sectionMap[key]
+			if r != nil {
+				value = r.value(section, key, value)
+			}
+			sb.WriteString("  ")
+			sb.WriteString(key)
+			sb.WriteString(" = ")
 			// Quote values that contain spaces or special characters
 			if strings.ContainsAny(value, " \t\n\r\"\\") {
-				sb.WriteString(fmt.Sprintf("  %s = %q\n", key, value))
+				sb.WriteString(strconv.Quote(value))
 			} else {
-				sb.WriteString(fmt.Sprintf("  %s = %s\n", key, value))
+				sb.WriteString(value)
 			}
+			sb.WriteString("\n")
 		}
 		sb.WriteString("\n")
 	}
@@ -88,16 +192,14 @@ func (c *Config) String() string {
 }
 
 func (c *Config) Has(key string) bool {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 
-	parts := strings.SplitN(key, ".", 2)
-	if len(parts) != 2 {
+	section, subkey, err := parseConfigKey(key)
+	if err != nil {
 		return false
 	}
 
-	section, subkey := parts[0], parts[1]
-
 	sectionMap, exists := c.sections[section]
 	if !exists {
 		return false
@@ -107,8 +209,27 @@ func (c *Config) Has(key string) bool {
 	return exists
 }
 
+// HasExplicit is like Has but reports false for keys whose value comes only
+// from WithDefaults.
+func (c *Config) HasExplicit(key string) bool {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	values := c.lookupRawValues(key)
+	if len(values) == 0 {
+		return false
+	}
+
+	source := values[len(values)-1].source
+	return source == nil || source.Type != SourceTypeDefault
+}
+
+// GetSection returns a copy of the effective values of section, so the
+// result may be kept and modified freely. Use ForEachInSection to read a
+// section without copying it. section is one exact section, such as
+// remote.origin; use KeysUnder to reach across subsections.
 func (c *Config) GetSection(section string) map[string]string {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 
 	sectionMap, exists := c.sections[section]
@@ -123,19 +244,379 @@ func (c *Config) GetSection(section string) map[string]string {
 	return result
 }
 
+// ForEachInSection calls fn for each key of section with its effective
+// value, in no particular order, until fn returns false. Nothing is copied:
+// the config is read-locked while fn runs, so fn must not modify the config.
+func (c *Config) ForEachInSection(section string, fn func(key, value string) bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	for key, value := range c.sections[section] {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// GetSectionMap is like GetSection but also reports whether the section
+// exists, so an empty section can be told apart from a missing one.
+func (c *Config) GetSectionMap(section string) (map[string]string, bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	sectionMap, exists := c.sections[section]
+	if !exists {
+		return nil, false
+	}
+
+	result := make(map[string]string, len(sectionMap))
+	for k, v := range sectionMap {
+		result[k] = v
+	}
+	return result, true
+}
+
+// GetStringOK returns the effective value of key and whether it is set, like
+// indexing a map.
+func (c *Config) GetStringOK(key string) (string, bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	_, _, value, err := c.lookup(key)
+	if err != nil {
+		return "", false
+	}
+	return value.value, true
+}
+
 func (c *Config) GetSections() []string {
-	c.mu.RLock()
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	sections := make([]string, 0, len(c.sections))
+	for section := range c.sections {
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// GetKeys returns the fully-qualified dotted names of all keys.
+func (c *Config) GetKeys() []string {
+	c.rlock()
 	defer c.mu.RUnlock()
 
+	var keys []string
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			keys = append(keys, section+"."+key)
+		}
+	}
+	return keys
+}
+
+// GetSortedSections is like GetSections but returns the sections in a stable
+// order: core, user, remote and branch first, as git writes them, then the
+// rest alphabetically.
+func (c *Config) GetSortedSections() []string {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	return c.sortedSections()
+}
+
+// GetSortedKeys is like GetKeys but ordered by GetSortedSections, with the
+// keys of each section sorted alphabetically.
+func (c *Config) GetSortedKeys() []string {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	var keys []string
+	for _, section := range c.sortedSections() {
+		for _, key := range sortedSectionKeys(c.sections[section]) {
+			keys = append(keys, section+"."+key)
+		}
+	}
+	return keys
+}
+
+// standardSectionOrder ranks the sections git itself writes first.
+var standardSectionOrder = map[string]int{
+	"core":   0,
+	"user":   1,
+	"remote": 2,
+	"branch": 3,
+}
+
+// sortedSections returns the section names in GetSortedSections order.
+// Callers must hold the lock.
+func (c *Config) sortedSections() []string {
 	sections := make([]string, 0, len(c.sections))
 	for section := range c.sections {
 		sections = append(sections, section)
 	}
+	sort.Slice(sections, func(i, j int) bool {
+		return sectionLess(sections[i], sections[j])
+	})
+	return sections
+}
+
+// sectionLess orders sections by their standard rank, then by name, so
+// subsections stay grouped under their section.
+func sectionLess(a, b string) bool {
+	rankA, rankB := sectionRank(a), sectionRank(b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	return a < b
+}
+
+func sectionRank(section string) int {
+	name, _, _ := strings.Cut(section, ".")
+	if rank, ok := standardSectionOrder[name]; ok {
+		return rank
+	}
+	return len(standardSectionOrder)
+}
+
+func sortedSectionKeys(sectionMap map[string]string) []string {
+	keys := make([]string, 0, len(sectionMap))
+	for key := range sectionMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Size returns the total number of keys across all sections.
+func (c *Config) Size() int {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	size := 0
+	for _, sectionMap := range c.sections {
+		size += len(sectionMap)
+	}
+	return size
+}
+
+// SectionSize returns the number of keys in a section, using the dotted form
+// for subsections (e.g. remote.origin). SectionSize("remote") counts only a
+// plain [remote] section; CountUnder("remote") counts every remote too.
+func (c *Config) SectionSize(section string) int {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	return len(c.sections[section])
+}
+
+// KeysUnder returns the sorted, fully qualified keys in the dotted namespace
+// below prefix. Unlike GetSection and SectionSize, which take one exact
+// section such as remote.origin, it spans subsections: "remote" matches the
+// keys of a plain [remote] section as well as remote.origin.url and
+// remote.upstream.fetch, and "remote.origin" matches only that remote. A
+// prefix naming a key matches that key, and an empty prefix matches every
+// key. Matching is by whole components, so "remote.o" matches nothing, but
+// a subsection containing dots, like a URL, is split at them too.
+func (c *Config) KeysUnder(prefix string) []string {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	prefix = normalizeKeyPrefix(prefix)
+	keys := make([]string, 0)
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			if fullKey := section + "." + key; isUnderPrefix(fullKey, prefix) {
+				keys = append(keys, fullKey)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CountUnder returns the number of keys KeysUnder would return, without
+// building them.
+func (c *Config) CountUnder(prefix string) int {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	prefix = normalizeKeyPrefix(prefix)
+	count := 0
+	for section, sectionMap := range c.sections {
+		if isUnderPrefix(section, prefix) {
+			count += len(sectionMap)
+		} else if isUnderPrefix(prefix, section) {
+			// The prefix names a single key of this section
+			if _, exists := sectionMap[prefix[len(section)+1:]]; exists {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// CountValuesByType counts every value, including each value of a
+// multi-valued key, by the type it converts to: "bool", "int", "float",
+// "bytesize" (such as 512m), "duration" or "string". Types without values are
+// left out, so an empty config yields an empty map.
+func (c *Config) CountValuesByType() map[string]int {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			for _, v := range c.rawValues(section, key) {
+				counts[inferValueType(v.value)]++
+			}
+		}
+	}
+	return counts
+}
+
+// GetValueType returns the type the effective value of key converts to, as
+// counted by CountValuesByType. A plain integer is an "int" even for keys
+// read as booleans, since 0 and 1 are both.
+func (c *Config) GetValueType(key string) (string, error) {
+	value, err := Get[string](c, key)
+	if err != nil {
+		return "", err
+	}
+	return inferValueType(value), nil
+}
+
+// normalizeKeyPrefix lowercases the section name of a dotted prefix, which
+// is case-insensitive like the section names stored.
+func normalizeKeyPrefix(prefix string) string {
+	name, rest, found := strings.Cut(prefix, ".")
+	if !found {
+		return strings.ToLower(prefix)
+	}
+	return strings.ToLower(name) + "." + rest
+}
+
+// isUnderPrefix reports whether the dotted name equals prefix or lies below
+// it, one or more whole components further down.
+func isUnderPrefix(name, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	return len(name) == len(prefix) || name[len(prefix)] == '.'
+}
+
+// SectionCount returns the number of distinct top-level section names, so
+// remote.origin and remote.upstream are counted once as remote.
+func (c *Config) SectionCount() int {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	names := make(map[string]struct{}, len(c.sections))
+	for section := range c.sections {
+		name, _, _ := strings.Cut(section, ".")
+		names[name] = struct{}{}
+	}
+	return len(names)
+}
+
+// ListSections returns sorted section names. With an empty prefix it lists
+// the distinct top-level names, e.g. ["core", "remote", "user"]; otherwise it
+// lists the internal names whose top-level name is prefix, e.g.
+// ["remote.origin", "remote.upstream"] for "remote".
+func (c *Config) ListSections(prefix string) []string {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	prefix = strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	for section := range c.sections {
+		name, _, _ := strings.Cut(section, ".")
+		switch {
+		case prefix == "":
+			seen[name] = true
+		case name == prefix:
+			seen[section] = true
+		}
+	}
+
+	sections := make([]string, 0, len(seen))
+	for section := range seen {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
 	return sections
 }
 
+// ListSubsections is an alias of SubsectionNames.
+func (c *Config) ListSubsections(sectionName string) []string {
+	return c.SubsectionNames(sectionName)
+}
+
+// SubsectionNames returns the sorted subsection names of a top-level section,
+// e.g. ["origin", "upstream"] for "remote".
+func (c *Config) SubsectionNames(section string) []string {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0)
+	for name := range c.sections {
+		if sub, ok := strings.CutPrefix(name, section+"."); ok && sub != "" {
+			names = append(names, sub)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ContextCheckInterval is how many sections the WithContext variants of the
+// accessors process between checks for cancellation.
+const ContextCheckInterval = 1000
+
+// checkContext reports ctx.Err() on the first of every ContextCheckInterval
+// iterations, keeping the check off the hot path of large configs.
+func checkContext(ctx context.Context, i int) error {
+	if i%ContextCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// GetNumberOfRemotes returns the number of configured remotes.
+func (c *Config) GetNumberOfRemotes() int {
+	return c.countSubsections("remote")
+}
+
+// GetNumberOfBranches returns the number of branches with a branch.<name>
+// section.
+func (c *Config) GetNumberOfBranches() int {
+	return c.countSubsections("branch")
+}
+
+// GetNumberOfSubmodules returns the number of submodule.<name> sections.
+func (c *Config) GetNumberOfSubmodules() int {
+	return c.countSubsections("submodule")
+}
+
+// countSubsections is len(SubsectionNames(section)) without building and
+// sorting the list.
+func (c *Config) countSubsections(section string) int {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	for name := range c.sections {
+		if sub, ok := strings.CutPrefix(name, section+"."); ok && sub != "" {
+			count++
+		}
+	}
+	return count
+}
+
 func (c *Config) HasSection(section string) bool {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 
 	_, exists := c.sections[section]
@@ -143,8 +624,9 @@ func (c *Config) HasSection(section string) bool {
 }
 
 
+// GetAll returns a deep copy of the effective values of every section.
 func (c *Config) GetAll() map[string]map[string]string {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 
 	result := make(map[string]map[string]string, len(c.sections))
@@ -158,7 +640,7 @@ func (c *Config) GetAll() map[string]map[string]string {
 }
 
 func (c *Config) GetSources() []ConfigSource {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 
 	sources := make([]ConfigSource, len(c.sources))
@@ -171,11 +653,26 @@ func (c *Config) Reload() error {
 }
 
 func (c *Config) ReloadWithContext(ctx context.Context) error {
-	c.mu.Lock()
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	c.lock()
 	sources := make([]ConfigSource, len(c.sources))
 	copy(sources, c.sources)
+	defaults := c.defaults
+	partial := c.partial
+	maxLineLength := c.maxLineLength
+	limits := c.limits
+	strict := c.strict
+	logger := c.logger
+	load := c.load
 	c.mu.Unlock()
 
+	if partial {
+		return &ConfigError{Op: "reload", Err: ErrPartialConfig}
+	}
+
 	if len(sources) == 0 {
 		return nil
 	}
@@ -186,6 +683,12 @@ func (c *Config) ReloadWithContext(ctx context.Context) error {
 	}
 
 	parser := newParser()
+	parser.maxLineLength = maxLineLength
+	parser.limits = limits
+	parser.strict = strict
+	parser.logger = logger
+	parser.comments = load != nil && load.comments
+	var errs []error
 	for _, source := range sources {
 		select {
 		case <-ctx.Done():
@@ -193,22 +696,55 @@ func (c *Config) ReloadWithContext(ctx context.Context) error {
 		default:
 		}
 
-		if err := parser.parseConfigFile(source.Path, newConfig); err != nil {
-			return fmt.Errorf("failed to reload from %s: %w", source.Path, err)
+		if load.isBlobSource(source) {
+			if err := parser.parseBlob(ctx, load, source.Path, newConfig); err != nil {
+				if strict {
+					errs = append(errs, err)
+					continue
+				}
+				return err
+			}
+			continue
+		}
+
+		start := time.Now()
+		values, size := parser.values, parser.bytes
+		if err := parser.parseConfigFile(source, newConfig); err != nil {
+			err = fmt.Errorf("failed to reload from %s: %w", source.Path, err)
+			if strict {
+				// Report every broken file, as Load does in strict mode
+				errs = append(errs, err)
+				continue
+			}
+			return err
 		}
+		parser.debug("config source parsed", "type", source.Type, "path", source.Path,
+			"values", parser.values-values, "duration", time.Since(start))
 		newConfig.sources = append(newConfig.sources, source)
+		newConfig.stats = append(newConfig.stats, fileStat(source, parser.values-values, parser.bytes-size, start))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if err := newConfig.applyDefaults(defaults); err != nil {
+		return err
 	}
 
-	c.mu.Lock()
+	c.lock()
 	c.sections = newConfig.sections
+	c.values = newConfig.values
 	c.sources = newConfig.sources
+	c.stats = newConfig.stats
+	c.comments = newConfig.comments
 	c.mu.Unlock()
 
 	return nil
 }
 
+// Clone returns an independent deep copy of the config.
 func (c *Config) Clone() *Config {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 
 	clone := &Config{
@@ -224,11 +760,56 @@ func (c *Config) Clone() *Config {
 		}
 	}
 
+	if c.values != nil {
+		// Copy every value list into one backing array; the capacity of each
+		// sub-slice is capped so appending to one list cannot overwrite the next
+		total := 0
+		for _, valueMap := range c.values {
+			for _, v := range valueMap {
+				total += len(v)
+			}
+		}
+		backing := make([]configValue, 0, total)
+
+		clone.values = make(map[string]map[string][]configValue, len(c.values))
+		for section, valueMap := range c.values {
+			clone.values[section] = make(map[string][]configValue, len(valueMap))
+			for k, v := range valueMap {
+				start := len(backing)
+				backing = append(backing, v...)
+				clone.values[section][k] = backing[start:len(backing):len(backing)]
+			}
+		}
+	}
+
 	copy(clone.sources, c.sources)
+	clone.defaults = c.defaults
+	clone.partial = c.partial
+	clone.redaction = c.redaction
+	clone.lookupEnv = c.lookupEnv
+	clone.warnings = append([]error(nil), c.warnings...)
+	clone.stats = append([]SourceStat(nil), c.stats...)
+	clone.comments = append([]CommentLine(nil), c.comments...)
+	clone.maxLineLength = c.maxLineLength
+	clone.limits = c.limits
+	clone.strict = c.strict
+	clone.logger = c.logger
+	clone.load = c.load
 
 	return clone
 }
 
+// Warnings returns the problems that did not stop a load: the lines git
+// printed to stderr with WithGitCommand, such as notices about ignored
+// include files, and the sources skipped by WithSkipUnreadable, whose errors
+// wrap the underlying cause for errors.Is.
+func (c *Config) Warnings() []error {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	return append([]error(nil), c.warnings...)
+}
+
 
 func (c *Config) GetUser() (*User, error) {
 	name, err := Get[string](c, "user.name")
@@ -247,6 +828,30 @@ func (c *Config) GetUser() (*User, error) {
 	}, nil
 }
 
+// GetUserExtended is like GetUser but also fills SigningKey and
+// UseConfigOnly, which are optional.
+func (c *Config) GetUserExtended() (*User, error) {
+	u, err := c.GetUser()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &fieldReader{c: c}
+	readField(r, UserSigningKey, &u.SigningKey)
+	readField(r, UserUseConfigOnly, &u.UseConfigOnly)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return u, nil
+}
+
+// GetUserSigningKey returns user.signingKey, the GPG key ID or SSH key used
+// to sign commits and tags.
+func (c *Config) GetUserSigningKey() (string, error) {
+	return Get[string](c, UserSigningKey)
+}
+
 
 func (c *Config) GetRemoteURL(remote string) (string, error) {
 	if remote == "" {
@@ -255,53 +860,429 @@ func (c *Config) GetRemoteURL(remote string) (string, error) {
 	return Get[string](c, fmt.Sprintf("remote.%s.url", remote))
 }
 
+// Set a configuration value, replacing any existing value for the key.
+func (c *Config) Set(key, value string) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	if err := c.setRawValue(key, value); err != nil {
+		return &ConfigError{
+			Op:  "set",
+			Key: key,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// SetMultiValue replaces every value of key with values, in order, as for a
+// multi-valued key like remote.<name>.fetch. A single value is the same as
+// Set, and an empty or nil slice removes the key, along with its section if
+// no other key is left in it.
+func (c *Config) SetMultiValue(key string, values []string) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	section, subkey, err := splitValidKey(key)
+	if err != nil {
+		return &ConfigError{
+			Op:  "set",
+			Key: key,
+			Err: err,
+		}
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	if len(values) == 0 {
+		c.removeValues(section, subkey)
+		return nil
+	}
+
+	stored := make([]configValue, len(values))
+	for i, value := range values {
+		stored[i] = configValue{value: value}
+	}
+	c.putValues(section, subkey, stored)
+	return nil
+}
+
+// AppendValue adds value after the existing values of key, which becomes
+// its effective value.
+func (c *Config) AppendValue(key, value string) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	if err := c.addRawValue(key, value, nil, 0); err != nil {
+		return &ConfigError{
+			Op:  "set",
+			Key: key,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// SetSection replaces every key of section, given in dotted form such as
+// "user" or "remote.origin", with values. Keys not in values are removed.
+// Nothing is changed if the section name or any key is invalid.
+func (c *Config) SetSection(section string, values map[string]string) error {
+	return c.storeSection("set", section, values, true)
+}
+
+// MergeSection sets each key in values within section, leaving the section's
+// other keys in place. Nothing is changed if the section name or any key is
+// invalid.
+func (c *Config) MergeSection(section string, values map[string]string) error {
+	return c.storeSection("merge", section, values, false)
+}
+
+func (c *Config) storeSection(op, section string, values map[string]string, replace bool) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	section = normalizeSectionPrefix(section)
+	if !isValidStoredSection(section) {
+		return &ConfigError{
+			Op:      op,
+			Section: section,
+			Err:     fmt.Errorf("%w: invalid section name %s", ErrInvalidKeyFormat, section),
+		}
+	}
+
+	normalized := make(map[string]string, len(values))
+	for key, value := range values {
+		if !isValidKeyName(key) {
+			return &ConfigError{
+				Op:      op,
+				Key:     key,
+				Section: section,
+				Err:     fmt.Errorf("%w: invalid key name %s", ErrInvalidKeyFormat, key),
+			}
+		}
+		normalized[strings.ToLower(key)] = value
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	if replace {
+		if c.sections == nil {
+			c.sections = make(map[string]map[string]string)
+		}
+		c.sections[section] = make(map[string]string, len(normalized))
+		delete(c.values, section)
+	}
+	for key, value := range normalized {
+		c.putValues(section, key, []configValue{{value: value}})
+	}
+	return nil
+}
+
+// isValidStoredSection reports whether a normalized section name, such as
+// "user" or "remote.origin", may be stored.
+func isValidStoredSection(section string) bool {
+	if !strings.Contains(section, ".") {
+		// Sections are stored dotted, never in the quoted remote "origin" form
+		return isValidSectionName(section) && !strings.Contains(section, " ")
+	}
+	return isValidSubsectionName(section)
+}
+
+// Transaction runs fn against a clone of the config and commits the clone's
+// state only if fn returns nil. A panic inside fn is recovered and reported as
+// an error; in both cases the receiver is left untouched.
+//
+// The write lock is held only while committing, so concurrent writes made to
+// the receiver while fn runs are overwritten by the commit.
+func (c *Config) Transaction(fn func(*Config) error) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	tx := c.Clone()
+
+	if err := runTransaction(tx, fn); err != nil {
+		return err
+	}
+
+	c.lock()
+	c.sections = tx.sections
+	c.values = tx.values
+	c.sources = tx.sources
+	c.mu.Unlock()
+
+	return nil
+}
+
+func runTransaction(tx *Config, fn func(*Config) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &ConfigError{
+				Op:  "transaction",
+				Err: fmt.Errorf("panic: %v", r),
+			}
+		}
+	}()
+
+	return fn(tx)
+}
+
+// GetWithSource returns the raw value of a key and the source that last set
+// it, which is how git resolves a key defined in several files.
+func (c *Config) GetWithSource(key string) (string, *ConfigSource, error) {
+	return GetWithSource[string](c, key)
+}
+
+// WhichFile returns the path of the file that set the effective value of key.
+func (c *Config) WhichFile(key string) (string, error) {
+	_, source, err := c.GetWithSource(key)
+	if err != nil {
+		return "", err
+	}
+	if source == nil || source.Path == "" {
+		section, subkey, _ := parseConfigKey(key)
+		return "", &ConfigError{
+			Op:      "which",
+			Key:     subkey,
+			Section: section,
+			Err:     ErrNoSource,
+		}
+	}
+	return source.Path, nil
+}
+
 func (c *Config) setRawValue(key, value string) error {
+	return c.storeRawValue(key, configValue{value: value}, false)
+}
+
+// addRawValue appends value to the key's value list, keeping earlier values
+// so multi-valued keys like remote.<name>.fetch are preserved.
+func (c *Config) addRawValue(key, value string, source *ConfigSource, line int) error {
+	return c.storeRawValue(key, configValue{value: value, source: source, line: line}, true)
+}
+
+// appendParsedValue is addRawValue for a section and key name the parser has
+// already validated and normalized.
+func (c *Config) appendParsedValue(section, key string, value configValue) {
+	c.lock()
+	defer c.mu.Unlock()
+
+	c.putValues(section, key, append(c.rawValues(section, key), value))
+}
+
+func (c *Config) storeRawValue(key string, value configValue, appendValue bool) error {
+	section, remaining, err := splitValidKey(key)
+	if err != nil {
+		return err
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	if appendValue {
+		c.putValues(section, remaining, append(c.rawValues(section, remaining), value))
+	} else {
+		c.putValues(section, remaining, []configValue{value})
+	}
+	return nil
+}
+
+// splitValidKey validates a dotted key for storage and splits it into its
+// section and key name.
+func splitValidKey(key string) (section, remaining string, err error) {
 	if !isValidConfigKey(key) {
-		return fmt.Errorf("%w: %s", ErrInvalidKeyFormat, key)
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidKeyFormat, key)
 	}
 
-	section, remaining, err := parseConfigKey(key)
+	section, remaining, err = parseConfigKey(key)
 	if err != nil {
-		return fmt.Errorf("%w: %s", err, key)
+		return "", "", fmt.Errorf("%w: %s", err, key)
 	}
 
 	if !isValidSectionName(section) && !isValidSubsectionName(section) {
-		return fmt.Errorf("%w: invalid section name %s", ErrInvalidKeyFormat, section)
+		return "", "", fmt.Errorf("%w: invalid section name %s", ErrInvalidKeyFormat, section)
 	}
 	if !isValidKeyName(remaining) {
-		return fmt.Errorf("%w: invalid key name %s", ErrInvalidKeyFormat, remaining)
+		return "", "", fmt.Errorf("%w: invalid key name %s", ErrInvalidKeyFormat, remaining)
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return section, remaining, nil
+}
 
+// putValues replaces all values of a key; the last one becomes the effective
+// value. Callers must hold the write lock.
+func (c *Config) putValues(section, key string, values []configValue) {
+	if c.sections == nil {
+		c.sections = make(map[string]map[string]string)
+	}
 	if c.sections[section] == nil {
 		c.sections[section] = make(map[string]string)
 	}
+	if c.values == nil {
+		c.values = make(map[string]map[string][]configValue)
+	}
+	if c.values[section] == nil {
+		c.values[section] = make(map[string][]configValue)
+	}
+
+	c.values[section][key] = values
+	c.sections[section][key] = values[len(values)-1].value
+}
 
-	c.sections[section][remaining] = value
+// removeValues deletes a key, and its section once empty. Callers must hold
+// the write lock.
+func (c *Config) removeValues(section, key string) {
+	if _, exists := c.sections[section][key]; !exists {
+		return
+	}
+	delete(c.sections[section], key)
+	delete(c.values[section], key)
+	if len(c.sections[section]) == 0 {
+		delete(c.sections, section)
+		delete(c.values, section)
+	}
+}
+
+// rawValues returns every value stored for a key. Callers must hold the lock.
+func (c *Config) rawValues(section, key string) []configValue {
+	if values := c.values[section][key]; len(values) > 0 {
+		return values
+	}
+	if value, exists := c.sections[section][key]; exists {
+		return []configValue{{value: value}}
+	}
 	return nil
 }
 
-// Retrieve a configuration value with type conversion.
-func Get[T Constraint](c *Config, key string) (T, error) {
-	var zero T
+// lookupRawValues is rawValues for a dotted key. Callers must hold the lock.
+func (c *Config) lookupRawValues(key string) []configValue {
+	section, subkey, err := parseConfigKey(key)
+	if err != nil {
+		return nil
+	}
+	return c.rawValues(section, subkey)
+}
+
+func valueStrings(values []configValue) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = v.value
+	}
+	return result
+}
+
+// applyDefaults fills in keys that no source defined with the given fallback
+// values, recorded with a SourceTypeDefault provenance.
+func (c *Config) applyDefaults(defaults map[string]string) error {
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	source := &ConfigSource{Type: SourceTypeDefault}
+	for key, value := range defaults {
+		if c.Has(key) {
+			continue
+		}
+		if err := c.addRawValue(key, value, source, 0); err != nil {
+			return &ConfigError{
+				Op:  "defaults",
+				Key: key,
+				Err: err,
+			}
+		}
+	}
+
+	c.lock()
+	c.defaults = defaults
+	c.mu.Unlock()
+
+	return nil
+}
 
-	c.mu.RLock()
+// GetMultiValue returns all values of a multi-valued key in the order they
+// were loaded, e.g. every remote.origin.fetch refspec.
+func (c *Config) GetMultiValue(key string) ([]string, error) {
+	c.rlock()
 	defer c.mu.RUnlock()
 
+	values, err := c.multiValues(key)
+	if err != nil {
+		return nil, err
+	}
+	return valueStrings(values), nil
+}
+
+type multiValueOptions struct {
+	resetOnEmpty bool
+}
+
+type MultiValueOption func(*multiValueOptions)
+
+// ResetOnEmpty makes an empty value discard every value before it, the way
+// git treats credential.helper and similar lists.
+func ResetOnEmpty() MultiValueOption {
+	return func(opts *multiValueOptions) {
+		opts.resetOnEmpty = true
+	}
+}
+
+// GetMultiValueWithSources returns all values of a multi-valued key in the
+// order they were loaded, each with the source and line that set it. With
+// ResetOnEmpty the result starts after the last empty value, which may leave
+// it empty.
+func (c *Config) GetMultiValueWithSources(key string, opts ...MultiValueOption) ([]ValueWithSource, error) {
+	options := &multiValueOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	values, err := c.multiValues(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ValueWithSource, 0, len(values))
+	for _, v := range values {
+		if options.resetOnEmpty && v.value == "" {
+			result = result[:0]
+			continue
+		}
+
+		entry := ValueWithSource{Value: v.value, Line: v.line}
+		if v.source != nil {
+			entry.Source = *v.source
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// multiValues returns every value of a dotted key. Callers must hold the lock.
+func (c *Config) multiValues(key string) ([]configValue, error) {
+	if err := c.loadErr(); err != nil {
+		return nil, err
+	}
+
 	section, subkey, err := parseConfigKey(key)
 	if err != nil {
-		return zero, &ConfigError{
+		return nil, &ConfigError{
 			Op:  "get",
 			Key: key,
 			Err: err,
 		}
 	}
 
-	sectionMap, exists := c.sections[section]
-	if !exists {
-		return zero, &ConfigError{
+	if _, exists := c.sections[section]; !exists {
+		return nil, &ConfigError{
 			Op:      "get",
 			Key:     subkey,
 			Section: section,
@@ -309,9 +1290,9 @@ func Get[T Constraint](c *Config, key string) (T, error) {
 		}
 	}
 
-	value, exists := sectionMap[subkey]
-	if !exists {
-		return zero, &ConfigError{
+	values := c.rawValues(section, subkey)
+	if len(values) == 0 {
+		return nil, &ConfigError{
 			Op:      "get",
 			Key:     subkey,
 			Section: section,
@@ -319,19 +1300,110 @@ func Get[T Constraint](c *Config, key string) (T, error) {
 		}
 	}
 
+	return values, nil
+}
+
+// Retrieve a configuration value with type conversion.
+func Get[T Constraint](c *Config, key string) (T, error) {
+	value, _, err := getValue[T](c, key)
+	return value, err
+}
+
+// Retrieve a configuration value with type conversion together with the
+// source that set it. The source is nil for values set programmatically.
+func GetWithSource[T Constraint](c *Config, key string) (T, *ConfigSource, error) {
+	value, source, err := getValue[T](c, key)
+	if err != nil {
+		return value, nil, err
+	}
+	return value, copySource(source), nil
+}
+
+// getValue looks up and converts key, returning the shared source of the
+// value so that Get does not pay for a copy.
+func getValue[T Constraint](c *Config, key string) (T, *ConfigSource, error) {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	return lookupValue[T](c, key)
+}
+
+// lookupValue is getValue without locking, for callers that hold the lock
+// or read a Snapshot, which is never modified.
+func lookupValue[T Constraint](c *Config, key string) (T, *ConfigSource, error) {
+	var zero T
+
+	section, subkey, value, err := c.lookup(key)
+	if err != nil {
+		return zero, nil, err
+	}
+
+	converted, err := convertLookup[T](section, subkey, value.value)
+	if err != nil {
+		return zero, nil, err
+	}
+
+	return converted, value.source, nil
+}
+
+func convertLookup[T Constraint](section, subkey, value string) (T, error) {
 	converted, err := convertValue[T](value)
 	if err != nil {
-		return zero, &ConfigError{
+		return converted, &ConfigError{
 			Op:      "get",
 			Key:     subkey,
 			Section: section,
-			Err:     fmt.Errorf("type conversion failed: %w", err),
+			Err:     err,
 		}
 	}
-
 	return converted, nil
 }
 
+// lookup finds the effective (last) value of a key. Callers must hold the lock.
+func (c *Config) lookup(key string) (section, subkey string, value configValue, err error) {
+	if err := c.loadErr(); err != nil {
+		return "", "", configValue{}, err
+	}
+
+	section, subkey, err = parseConfigKey(key)
+	if err != nil {
+		return "", "", configValue{}, &ConfigError{
+			Op:  "get",
+			Key: key,
+			Err: err,
+		}
+	}
+
+	if _, exists := c.sections[section]; !exists {
+		return section, subkey, configValue{}, &ConfigError{
+			Op:      "get",
+			Key:     subkey,
+			Section: section,
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	values := c.rawValues(section, subkey)
+	if len(values) == 0 {
+		return section, subkey, configValue{}, &ConfigError{
+			Op:      "get",
+			Key:     subkey,
+			Section: section,
+			Err:     ErrKeyNotFound,
+		}
+	}
+
+	return section, subkey, values[len(values)-1], nil
+}
+
+func copySource(source *ConfigSource) *ConfigSource {
+	if source == nil {
+		return nil
+	}
+	s := *source
+	return &s
+}
+
 // Retrieve a configuration value with a default
 func GetWithDefault[T Constraint](c *Config, key string, defaultValue T) T {
 	value, err := Get[T](c, key)
@@ -340,3 +1412,71 @@ func GetWithDefault[T Constraint](c *Config, key string, defaultValue T) T {
 	}
 	return value
 }
+
+// GetWithFallback returns the value of the first of keys that can be read as
+// T, or defaultValue if none can. Use it for settings that moved between key
+// names or that fall back to a related key, e.g. sequence.editor then
+// core.editor.
+func GetWithFallback[T Constraint](c *Config, keys []string, defaultValue T) T {
+	for _, key := range keys {
+		if value, err := Get[T](c, key); err == nil {
+			return value
+		}
+	}
+	return defaultValue
+}
+
+// GetFirstOf returns the value of the first of keys that is set. A set key
+// whose value cannot be converted to T is an error rather than a reason to
+// try the next key. If no key is set the error wraps ErrKeyNotFound.
+func GetFirstOf[T Constraint](c *Config, keys ...string) (T, error) {
+	var zero T
+
+	for _, key := range keys {
+		value, err := Get[T](c, key)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+			return zero, err
+		}
+	}
+
+	return zero, &ConfigError{
+		Op:  "get",
+		Key: strings.Join(keys, ", "),
+		Err: ErrKeyNotFound,
+	}
+}
+
+// GetOrSet returns the value of key, storing defaultValue in the config first
+// if the section exists but the key does not. Other errors, including a
+// missing section or a failed conversion, are returned unchanged. The check
+// and the store happen under one write lock, so concurrent callers agree on
+// a single stored value.
+func GetOrSet[T Constraint](c *Config, key string, defaultValue T) (T, error) {
+	var zero T
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	section, subkey, value, err := c.lookup(key)
+	if err == nil {
+		return convertLookup[T](section, subkey, value.value)
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return zero, err
+	}
+
+	if _, _, err := splitValidKey(key); err != nil {
+		return zero, &ConfigError{
+			Op:      "set",
+			Key:     subkey,
+			Section: section,
+			Err:     err,
+		}
+	}
+
+	c.putValues(section, subkey, []configValue{{value: fmt.Sprint(defaultValue)}})
+	return defaultValue, nil
+}
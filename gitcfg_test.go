@@ -1,7 +1,16 @@
 package gitcfg
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -145,6 +154,34 @@ func TestConfigClone(t *testing.T) {
 	}
 }
 
+func TestConfigCloneMultiValues(t *testing.T) {
+	config := parseTestConfig(t, `[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    push = refs/heads/main
+[remote "fork"]
+    fetch = +refs/heads/*:refs/remotes/fork/*`)
+
+	clone := config.Clone()
+	for _, key := range []string{"remote.origin.fetch", "remote.origin.push", "remote.fork.fetch"} {
+		if err := clone.addRawValue(key, "extra", nil, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for key, expected := range map[string][]string{
+		"remote.origin.fetch": {"+refs/heads/*:refs/remotes/origin/*"},
+		"remote.origin.push":  {"refs/heads/main"},
+		"remote.fork.fetch":   {"+refs/heads/*:refs/remotes/fork/*"},
+	} {
+		if got, _ := config.GetMultiValue(key); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Original %s = %v, expected %v", key, got, expected)
+		}
+		if got, _ := clone.GetMultiValue(key); !reflect.DeepEqual(got, append(expected, "extra")) {
+			t.Errorf("Clone %s = %v", key, got)
+		}
+	}
+}
+
 
 func TestConfigGetUser(t *testing.T) {
 	config := &Config{
@@ -165,6 +202,46 @@ func TestConfigGetUser(t *testing.T) {
 	}
 }
 
+func TestConfigGetUserExtended(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Test User
+    email = test@example.com
+    signingKey = ~/.ssh/id_ed25519.pub
+    useConfigOnly = true`)
+
+	user, err := config.GetUserExtended()
+	if err != nil {
+		t.Fatalf("GetUserExtended failed: %v", err)
+	}
+	expected := User{Name: "Test User", Email: "test@example.com", SigningKey: "~/.ssh/id_ed25519.pub", UseConfigOnly: true}
+	if *user != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *user)
+	}
+
+	// GetUser keeps returning only name and email
+	user, err = config.GetUser()
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	if user.SigningKey != "" || user.UseConfigOnly {
+		t.Errorf("Expected GetUser to leave the extended fields empty, got %+v", *user)
+	}
+
+	noKey := parseTestConfig(t, "[user]\n    name = Test User\n    email = test@example.com\n")
+	if _, err := noKey.GetUser(); err != nil {
+		t.Errorf("GetUser failed without signingkey: %v", err)
+	}
+	if user, err := noKey.GetUserExtended(); err != nil || user.SigningKey != "" {
+		t.Errorf("GetUserExtended() = %+v, %v", user, err)
+	}
+	if _, err := noKey.GetUserSigningKey(); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+	if key, err := config.GetUserSigningKey(); err != nil || key != "~/.ssh/id_ed25519.pub" {
+		t.Errorf("GetUserSigningKey() = %q, %v", key, err)
+	}
+}
+
 func TestConfigError(t *testing.T) {
 	err := &ConfigError{
 		Op:      "test",
@@ -178,6 +255,58 @@ func TestConfigError(t *testing.T) {
 	if errStr == "" {
 		t.Error("Expected non-empty error string")
 	}
+
+	located := &ConfigError{Op: "parse", Key: "user.name", Source: "config", Line: 3, Column: 12, Err: ErrInvalidValue}
+	if want := "gitconfig: parse user.name (source: config:3:12) invalid value"; located.Error() != want {
+		t.Errorf("Expected %q, got %q", want, located.Error())
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("failed", "err", err)
+	want := `err.op=test err.key=test.key err.section=test err.source=test.config err.error="key not found"`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("Expected structured fields %q, got %q", want, buf.String())
+	}
+}
+
+type testLevel int
+
+func TestConversionError(t *testing.T) {
+	config := parseTestConfig(t, "[http]\n    postBuffer = 512m\n    lowSpeedTime = 30s\n[core]\n    compression = 300\n    bare = maybe\n")
+
+	tests := []struct {
+		name    string
+		get     func() error
+		typ     string
+		message string
+	}{
+		{"size", func() error { _, err := Get[int](config, "http.postbuffer"); return err },
+			"int", `gitconfig: get http.postbuffer cannot convert "512m" to int; use GetBytes for sizes with a k, m or g suffix`},
+		{"duration", func() error { _, err := Get[int64](config, "http.lowspeedtime"); return err },
+			"int64", `gitconfig: get http.lowspeedtime cannot convert "30s" to int64; use GetDuration for durations`},
+		{"out of range", func() error { _, err := Get[int8](config, "core.compression"); return err },
+			"int8", `gitconfig: get core.compression cannot convert "300" to int8: value out of range`},
+		{"bool", func() error { _, err := Get[bool](config, "core.bare"); return err },
+			"bool", `gitconfig: get core.bare cannot convert "maybe" to bool`},
+		{"named type", func() error { _, err := Get[testLevel](config, "http.postbuffer"); return err },
+			"gitcfg.testLevel", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.get()
+			if !errors.Is(err, ErrInvalidValue) {
+				t.Fatalf("Expected ErrInvalidValue, got %v", err)
+			}
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) || convErr.Type != tt.typ {
+				t.Fatalf("Expected ConversionError for %s, got %v", tt.typ, err)
+			}
+			if tt.message != "" && err.Error() != tt.message {
+				t.Errorf("Expected %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
 }
 
 func TestConfigSourceType(t *testing.T) {
@@ -197,3 +326,1095 @@ func TestConfigSourceType(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigSet(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{},
+	}
+
+	if err := config.Set("remote.origin.url", "https://example.com/repo.git"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if config.sections["remote.origin"]["url"] != "https://example.com/repo.git" {
+		t.Errorf("Expected URL to be set, got '%s'", config.sections["remote.origin"]["url"])
+	}
+
+	if err := config.Set("invalid", "value"); err == nil {
+		t.Error("Expected error for invalid key")
+	}
+}
+
+func TestConfigSetMultiValue(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{},
+	}
+
+	for _, refspec := range []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*", "+refs/notes/*:refs/notes/*"} {
+		if err := config.AppendValue("remote.origin.fetch", refspec); err != nil {
+			t.Fatalf("AppendValue failed: %v", err)
+		}
+	}
+	values, err := config.GetMultiValue("remote.origin.fetch")
+	if err != nil || len(values) != 3 {
+		t.Fatalf("Expected 3 values, got %v, %v", values, err)
+	}
+	if fetch, _ := Get[string](config, "remote.origin.fetch"); fetch != "+refs/notes/*:refs/notes/*" {
+		t.Errorf("Expected the last appended value to be effective, got '%s'", fetch)
+	}
+
+	if err := config.SetMultiValue("remote.origin.fetch", []string{"a", "b"}); err != nil {
+		t.Fatalf("SetMultiValue failed: %v", err)
+	}
+	if values, _ := config.GetMultiValue("remote.origin.fetch"); !reflect.DeepEqual(values, []string{"a", "b"}) {
+		t.Errorf("Expected SetMultiValue to replace the values, got %v", values)
+	}
+
+	if err := config.SetMultiValue("remote.origin.fetch", []string{"only"}); err != nil {
+		t.Fatalf("SetMultiValue failed: %v", err)
+	}
+	single := &Config{}
+	single.Set("remote.origin.fetch", "only")
+	if !config.Equal(single) {
+		t.Errorf("Expected a single value to match Set, got %v", config.Flatten())
+	}
+
+	if err := config.SetMultiValue("remote.origin.fetch", nil); err != nil {
+		t.Fatalf("SetMultiValue failed: %v", err)
+	}
+	if config.Has("remote.origin.fetch") || config.HasSection("remote.origin") {
+		t.Error("Expected SetMultiValue with no values to remove the key and its empty section")
+	}
+
+	if err := config.SetMultiValue("invalid", []string{"value"}); !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Errorf("Expected ErrInvalidKeyFormat, got %v", err)
+	}
+	if err := config.AppendValue("invalid", "value"); !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Errorf("Expected ErrInvalidKeyFormat, got %v", err)
+	}
+}
+
+func TestConfigTransaction(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"remote.origin": {"url": "https://example.com/old.git"},
+		},
+	}
+
+	err := config.Transaction(func(tx *Config) error {
+		if err := tx.Set("remote.origin.url", "https://example.com/new.git"); err != nil {
+			return err
+		}
+		return tx.Set("http.proxy", "http://proxy.example.com")
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if config.sections["remote.origin"]["url"] != "https://example.com/new.git" {
+		t.Errorf("Expected committed URL, got '%s'", config.sections["remote.origin"]["url"])
+	}
+	if config.sections["http"]["proxy"] != "http://proxy.example.com" {
+		t.Errorf("Expected committed proxy, got '%s'", config.sections["http"]["proxy"])
+	}
+}
+
+func TestConfigTransactionRollback(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"remote.origin": {"url": "https://example.com/old.git"},
+		},
+	}
+
+	err := config.Transaction(func(tx *Config) error {
+		if err := tx.Set("remote.origin.url", "https://example.com/new.git"); err != nil {
+			return err
+		}
+		return tx.Set("invalid", "value")
+	})
+	if err == nil {
+		t.Fatal("Expected transaction error")
+	}
+	if config.sections["remote.origin"]["url"] != "https://example.com/old.git" {
+		t.Errorf("Receiver was modified by failed transaction: '%s'", config.sections["remote.origin"]["url"])
+	}
+}
+
+func TestConfigTransactionPanic(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user": {"name": "Test User"},
+		},
+	}
+
+	err := config.Transaction(func(tx *Config) error {
+		tx.Set("user.name", "Changed")
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatal("Expected error from panicking transaction")
+	}
+	if config.sections["user"]["name"] != "Test User" {
+		t.Errorf("Receiver was modified by panicking transaction: '%s'", config.sections["user"]["name"])
+	}
+
+	// The receiver must still be usable after the recovered panic.
+	if err := config.Set("user.email", "test@example.com"); err != nil {
+		t.Errorf("Set after panicking transaction failed: %v", err)
+	}
+}
+
+func TestConfigGetWithSource(t *testing.T) {
+	parser := newParser()
+	config := &Config{
+		sections: make(map[string]map[string]string),
+	}
+
+	global := ConfigSource{Type: SourceTypeGlobal, Path: "/home/user/.gitconfig"}
+	local := ConfigSource{Type: SourceTypeLocal, Path: "/repo/.git/config"}
+
+	if err := parser.parseConfigReader(strings.NewReader("[core]\n    editor = vim\n    pager = less\n"), config, global); err != nil {
+		t.Fatalf("parseConfigReader failed: %v", err)
+	}
+	if err := parser.parseConfigReader(strings.NewReader("[core]\n    editor = nano\n"), config, local); err != nil {
+		t.Fatalf("parseConfigReader failed: %v", err)
+	}
+
+	value, source, err := config.GetWithSource("core.editor")
+	if err != nil {
+		t.Fatalf("GetWithSource failed: %v", err)
+	}
+	if value != "nano" {
+		t.Errorf("Expected 'nano', got '%s'", value)
+	}
+	if source == nil || *source != local {
+		t.Errorf("Expected local source, got %v", source)
+	}
+
+	pager, source, err := GetWithSource[string](config, "core.pager")
+	if err != nil {
+		t.Fatalf("GetWithSource failed: %v", err)
+	}
+	if pager != "less" || source == nil || source.Type != SourceTypeGlobal {
+		t.Errorf("Expected 'less' from global source, got '%s' from %v", pager, source)
+	}
+
+	path, err := config.WhichFile("core.editor")
+	if err != nil {
+		t.Fatalf("WhichFile failed: %v", err)
+	}
+	if path != local.Path {
+		t.Errorf("Expected '%s', got '%s'", local.Path, path)
+	}
+}
+
+func TestConfigWhichFileNoSource(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{},
+	}
+
+	if err := config.Set("user.name", "Test User"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if _, err := config.WhichFile("user.name"); !errors.Is(err, ErrNoSource) {
+		t.Errorf("Expected ErrNoSource, got %v", err)
+	}
+	if _, err := config.WhichFile("user.email"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestConfigSize(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user":            {"name": "Test User", "email": "test@example.com"},
+			"remote.origin":   {"url": "https://github.com/example/repo.git", "fetch": "+refs/heads/*:refs/remotes/origin/*"},
+			"remote.upstream": {"url": "https://github.com/upstream/repo.git"},
+			"remote.fork":     {"url": "https://github.com/fork/repo.git"},
+		},
+	}
+
+	if size := config.Size(); size != 6 {
+		t.Errorf("Expected size 6, got %d", size)
+	}
+	if size := config.SectionSize("remote.origin"); size != 2 {
+		t.Errorf("Expected remote.origin size 2, got %d", size)
+	}
+	if size := config.SectionSize("missing"); size != 0 {
+		t.Errorf("Expected missing section size 0, got %d", size)
+	}
+	if count := config.SectionCount(); count != 2 {
+		t.Errorf("Expected 2 top-level sections, got %d", count)
+	}
+
+	names := config.SubsectionNames("remote")
+	expected := []string{"fork", "origin", "upstream"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, names)
+			break
+		}
+	}
+
+	if names := config.SubsectionNames("user"); len(names) != 0 {
+		t.Errorf("Expected no subsections for user, got %v", names)
+	}
+}
+
+func TestCountValuesByType(t *testing.T) {
+	config := parseTestConfig(t, `[core]
+    bare = true
+    editor = vim
+    bigFileThreshold = 512m
+    compression = 9
+[http]
+    lowSpeedTime = 1m30s
+[tuning]
+    ratio = 3.14
+[commit]
+    gpgSign
+[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+`)
+
+	expected := map[string]int{"bool": 2, "int": 1, "float": 1, "bytesize": 1, "duration": 1, "string": 3}
+	if counts := config.CountValuesByType(); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+
+	tests := map[string]string{
+		"core.bare":             "bool",
+		"core.bigfilethreshold": "bytesize",
+		"tuning.ratio":          "float",
+		"core.compression":      "int",
+		"core.editor":           "string",
+		"http.lowspeedtime":     "duration",
+		"commit.gpgsign":        "bool",
+	}
+	for key, want := range tests {
+		if got, err := config.GetValueType(key); err != nil || got != want {
+			t.Errorf("GetValueType(%q) = %q, %v, expected %q", key, got, err, want)
+		}
+	}
+	if _, err := config.GetValueType("core.pager"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+
+	if counts := parseTestConfig(t, "").CountValuesByType(); counts == nil || len(counts) != 0 {
+		t.Errorf("Expected an empty map for an empty config, got %v", counts)
+	}
+}
+
+func TestKeysUnder(t *testing.T) {
+	config := parseTestConfig(t, `[remote]
+    pushDefault = origin
+[remote "origin"]
+    url = https://github.com/example/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+[remote "upstream"]
+    url = https://github.com/upstream/repo.git
+[remotes]
+    all = origin upstream
+[user]
+    name = Test User
+`)
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"remote", []string{"remote.origin.fetch", "remote.origin.url", "remote.pushdefault", "remote.upstream.url"}},
+		{"Remote", []string{"remote.origin.fetch", "remote.origin.url", "remote.pushdefault", "remote.upstream.url"}},
+		{"remote.origin", []string{"remote.origin.fetch", "remote.origin.url"}},
+		{"remote.origin.url", []string{"remote.origin.url"}},
+		{"remote.o", []string{}},
+		{"missing", []string{}},
+		{"", []string{"remote.origin.fetch", "remote.origin.url", "remote.pushdefault", "remote.upstream.url", "remotes.all", "user.name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if keys := config.KeysUnder(tt.prefix); !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("KeysUnder(%q) = %v, want %v", tt.prefix, keys, tt.expected)
+			}
+			if count := config.CountUnder(tt.prefix); count != len(tt.expected) {
+				t.Errorf("CountUnder(%q) = %d, want %d", tt.prefix, count, len(tt.expected))
+			}
+		})
+	}
+
+	// The exact-section APIs only see the plain [remote] section
+	if size := config.SectionSize("remote"); size != 1 {
+		t.Errorf("SectionSize(remote) = %d, want 1", size)
+	}
+	if section := config.GetSection("remote"); !reflect.DeepEqual(section, map[string]string{"pushdefault": "origin"}) {
+		t.Errorf("GetSection(remote) = %v", section)
+	}
+}
+
+func TestConfigSectionCountRemotesOnly(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"remote.origin":   {"url": "a"},
+			"remote.upstream": {"url": "b"},
+			"remote.fork":     {"url": "c"},
+		},
+	}
+
+	if count := config.SectionCount(); count != 1 {
+		t.Errorf("Expected SectionCount 1 for three remotes, got %d", count)
+	}
+}
+
+func TestConfigHasSubsectionKeys(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"remote.origin":           {"url": "https://github.com/example/repo.git"},
+			"url.https://github.com/": {"insteadof": "gh:"},
+		},
+	}
+
+	if !config.Has("remote.origin.url") {
+		t.Error("Expected remote.origin.url to exist")
+	}
+	if !config.Has("url.https://github.com/.insteadOf") {
+		t.Error("Expected URL subsection key to exist")
+	}
+	if config.Has("remote.origin.pushurl") {
+		t.Error("Expected remote.origin.pushurl to not exist")
+	}
+}
+
+func TestLoadWithDefaults(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	globalPath := filepath.Join(home, ".gitconfig")
+	if err := os.WriteFile(globalPath, []byte("[init]\n    defaultBranch = trunk\n"), 0644); err != nil {
+		t.Fatalf("Failed to write global config: %v", err)
+	}
+
+	config, err := Load(WithGlobal(), WithDefaults(map[string]string{
+		"init.defaultbranch": "main",
+		"http.sslverify":     "true",
+	}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if branch, _ := Get[string](config, "init.defaultbranch"); branch != "trunk" {
+		t.Errorf("Expected real source to override default, got '%s'", branch)
+	}
+
+	verify, source, err := GetWithSource[bool](config, "http.sslverify")
+	if err != nil {
+		t.Fatalf("GetWithSource failed: %v", err)
+	}
+	if !verify {
+		t.Error("Expected defaulted http.sslverify to be true")
+	}
+	if source == nil || source.Type != SourceTypeDefault {
+		t.Errorf("Expected default source, got %v", source)
+	}
+
+	if !config.Has("http.sslverify") {
+		t.Error("Expected Has to report defaulted key")
+	}
+	if config.HasExplicit("http.sslverify") {
+		t.Error("Expected HasExplicit to be false for defaulted key")
+	}
+	if !config.HasExplicit("init.defaultbranch") {
+		t.Error("Expected HasExplicit to be true for key from a file")
+	}
+
+	for _, source := range config.GetSources() {
+		if source.Type == SourceTypeDefault {
+			t.Error("Defaults should not be listed as a configuration source")
+		}
+	}
+
+	if err := os.WriteFile(globalPath, []byte("[user]\n    name = Test User\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite global config: %v", err)
+	}
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if branch, _ := Get[string](config, "init.defaultbranch"); branch != "main" {
+		t.Errorf("Expected default to be reapplied after reload, got '%s'", branch)
+	}
+}
+
+func TestLoadWithInvalidDefaults(t *testing.T) {
+	_, err := Load(WithGlobal(), WithDefaults(map[string]string{"initdefaultbranch": "main"}))
+	if !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Errorf("Expected ErrInvalidKeyFormat for malformed default key, got %v", err)
+	}
+}
+
+func TestLoadFromGitDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	gitDir := filepath.Join(t.TempDir(), "repo.git")
+	for _, dir := range []string{"objects", "refs"} {
+		if err := os.MkdirAll(filepath.Join(gitDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create bare repo: %v", err)
+		}
+	}
+	files := map[string]string{
+		"HEAD":            "ref: refs/heads/main\n",
+		"config":          "[core]\n    bare = true\n[remote \"origin\"]\n    url = https://example.com/repo.git\n",
+		"config.worktree": "[core]\n    sparseCheckout = true\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(gitDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	config, err := LoadFromGitDir(gitDir)
+	if err != nil {
+		t.Fatalf("LoadFromGitDir failed: %v", err)
+	}
+
+	if bare, _ := Get[bool](config, "core.bare"); !bare {
+		t.Error("Expected core.bare from <gitDir>/config")
+	}
+	if url, _ := Get[string](config, "remote.origin.url"); url != "https://example.com/repo.git" {
+		t.Errorf("Expected remote URL, got '%s'", url)
+	}
+
+	_, source, err := GetWithSource[bool](config, "core.sparsecheckout")
+	if err != nil {
+		t.Fatalf("GetWithSource failed: %v", err)
+	}
+	if source.Type != SourceTypeWorktree || source.Path != filepath.Join(gitDir, "config.worktree") {
+		t.Errorf("Expected worktree source, got %+v", source)
+	}
+
+	if _, err := LoadFromGitDir(home); err == nil {
+		t.Error("Expected error for a directory that is not a git directory")
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"http": {"postbuffer": "1048576"},
+			"core": {"editor": "vim"},
+		},
+	}
+
+	timeout, err := GetOrSet(config, "http.timeout", 30)
+	if err != nil {
+		t.Fatalf("GetOrSet failed: %v", err)
+	}
+	if timeout != 30 {
+		t.Errorf("Expected 30, got %d", timeout)
+	}
+	if !config.Has("http.timeout") {
+		t.Error("Expected http.timeout to be stored")
+	}
+	if raw := config.GetSection("http")["timeout"]; raw != "30" {
+		t.Errorf("Expected raw value '30', got '%s'", raw)
+	}
+
+	// An existing value wins over the default
+	buffer, err := GetOrSet(config, "http.postbuffer", 0)
+	if err != nil {
+		t.Fatalf("GetOrSet failed: %v", err)
+	}
+	if buffer != 1048576 {
+		t.Errorf("Expected 1048576, got %d", buffer)
+	}
+
+	if _, err := GetOrSet(config, "core.autocrlf", false); err != nil {
+		t.Fatalf("GetOrSet failed: %v", err)
+	}
+	if raw := config.GetSection("core")["autocrlf"]; raw != "false" {
+		t.Errorf("Expected raw value 'false', got '%s'", raw)
+	}
+
+	if _, err := GetOrSet(config, "user.name", "Test User"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+	if config.HasSection("user") {
+		t.Error("Expected user section not to be created")
+	}
+
+	if _, err := GetOrSet(config, "core.editor", 1); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestGetOrSetConcurrent(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{"core": {"editor": "vim"}},
+	}
+
+	const workers = 16
+	results := make(chan int, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := GetOrSet(config, "core.abbrev", i+1)
+			if err != nil {
+				t.Errorf("GetOrSet failed: %v", err)
+			}
+			results <- value
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	stored := GetWithDefault(config, "core.abbrev", 0)
+	for value := range results {
+		if value != stored {
+			t.Errorf("Expected every caller to see %d, got %d", stored, value)
+		}
+	}
+
+	values, err := config.GetMultiValue("core.abbrev")
+	if err != nil {
+		t.Fatalf("GetMultiValue failed: %v", err)
+	}
+	if len(values) != 1 {
+		t.Errorf("Expected a single stored value, got %v", values)
+	}
+}
+
+func TestNewTestConfig(t *testing.T) {
+	config := NewTestConfig(map[string]string{
+		"user.name":                     "Test User",
+		"remote.origin.url":             "https://github.com/user/repo.git",
+		"url.git@github.com:.insteadof": "https://github.com/",
+	})
+
+	if name, _ := Get[string](config, "user.name"); name != "Test User" {
+		t.Errorf("Expected 'Test User', got '%s'", name)
+	}
+	if !config.Has("url.git@github.com:.insteadof") {
+		t.Error("Expected URL subsection key to be set")
+	}
+	if config.Size() != 3 {
+		t.Errorf("Expected 3 keys, got %d", config.Size())
+	}
+}
+
+func TestNewTestConfigInvalidKeyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected NewTestConfig to panic on an invalid key")
+		}
+	}()
+
+	NewTestConfig(map[string]string{"nosection": "value"})
+}
+
+func TestNewTestConfigFromString(t *testing.T) {
+	data := `[user]
+    name = Test User
+[remote "origin"]
+    url = https://github.com/user/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+`
+	config, err := NewTestConfigFromString(data)
+	if err != nil {
+		t.Fatalf("NewTestConfigFromString failed: %v", err)
+	}
+
+	fetch, err := config.GetMultiValue("remote.origin.fetch")
+	if err != nil {
+		t.Fatalf("GetMultiValue failed: %v", err)
+	}
+	if len(fetch) != 2 {
+		t.Errorf("Expected 2 fetch refspecs, got %v", fetch)
+	}
+
+	// String renders effective values only, so round trip a single-valued config
+	single := config.Filter("user")
+	again, err := NewTestConfigFromString(single.String())
+	if err != nil {
+		t.Fatalf("Reparsing String output failed: %v", err)
+	}
+	if !single.Equal(again) {
+		t.Errorf("Round trip changed the config: %+v", Diff(single, again))
+	}
+
+	if _, err := config.WhichFile("user.name"); !errors.Is(err, ErrNoSource) {
+		t.Errorf("Expected ErrNoSource for parsed config, got %v", err)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	config, err := ParseBytes([]byte("[core]\n    editor = vim\n"))
+	if err != nil {
+		t.Fatalf("ParseBytes failed: %v", err)
+	}
+	if editor, _ := Get[string](config, "core.editor"); editor != "vim" {
+		t.Errorf("Expected 'vim', got '%s'", editor)
+	}
+
+	if _, err := ParseBytes([]byte("[core]\n    editor = \"unterminated\\\"\n")); err == nil {
+		t.Error("Expected error for invalid quoted value")
+	}
+}
+
+func TestConfigListSections(t *testing.T) {
+	config := parseTestConfig(t, `[core]
+    editor = vim
+[user]
+    name = Test User
+[remote "origin"]
+    url = https://github.com/user/repo.git
+[remote "upstream"]
+    url = https://github.com/upstream/repo.git
+[branch "main"]
+    remote = origin
+[remote]
+    pushDefault = origin
+`)
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", []string{"branch", "core", "remote", "user"}},
+		{"remote", []string{"remote", "remote.origin", "remote.upstream"}},
+		{"Remote", []string{"remote", "remote.origin", "remote.upstream"}},
+		{"branch", []string{"branch.main"}},
+		{"rem", []string{}},
+		{"http", []string{}},
+	}
+
+	for _, test := range tests {
+		if sections := config.ListSections(test.prefix); !reflect.DeepEqual(sections, test.expected) {
+			t.Errorf("ListSections(%q) = %v, expected %v", test.prefix, sections, test.expected)
+		}
+	}
+
+	if subsections := config.ListSubsections("remote"); !reflect.DeepEqual(subsections, []string{"origin", "upstream"}) {
+		t.Errorf("Expected [origin upstream], got %v", subsections)
+	}
+	if subsections := config.ListSubsections("core"); len(subsections) != 0 {
+		t.Errorf("Expected no subsections, got %v", subsections)
+	}
+}
+
+func TestConfigGetSectionMap(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user": {"name": "Test User"},
+			"core": {},
+		},
+	}
+
+	user, ok := config.GetSectionMap("user")
+	if !ok || user["name"] != "Test User" {
+		t.Errorf("Expected user section, got %v, %v", user, ok)
+	}
+
+	core, ok := config.GetSectionMap("core")
+	if !ok || core == nil || len(core) != 0 {
+		t.Errorf("Expected (map{}, true) for empty section, got %#v, %v", core, ok)
+	}
+
+	if missing, ok := config.GetSectionMap("http"); ok || missing != nil {
+		t.Errorf("Expected (nil, false) for missing section, got %#v, %v", missing, ok)
+	}
+
+	// The returned map is a copy
+	user["name"] = "Changed"
+	if name, _ := Get[string](config, "user.name"); name != "Test User" {
+		t.Errorf("Expected config to be unchanged, got '%s'", name)
+	}
+}
+
+func TestConfigGetStringOK(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user":          {"name": "Test User", "email": ""},
+			"remote.origin": {"url": "https://github.com/user/repo.git"},
+		},
+	}
+
+	tests := []struct {
+		key      string
+		expected string
+		ok       bool
+	}{
+		{"user.name", "Test User", true},
+		{"user.email", "", true},
+		{"remote.origin.url", "https://github.com/user/repo.git", true},
+		{"user.signingkey", "", false},
+		{"core.editor", "", false},
+		{"invalid", "", false},
+	}
+
+	for _, test := range tests {
+		value, ok := config.GetStringOK(test.key)
+		if value != test.expected || ok != test.ok {
+			t.Errorf("GetStringOK(%q) = (%q, %v), expected (%q, %v)", test.key, value, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestSetSection(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"remote.origin": {"url": "https://example.com/old.git", "pushurl": "ssh://example.com/old.git"},
+		},
+	}
+
+	values := make(map[string]string)
+	for i := 0; i < 10; i++ {
+		values[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	values["bad key"] = "x"
+
+	if err := config.SetSection("remote.origin", values); !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Fatalf("Expected ErrInvalidKeyFormat, got %v", err)
+	}
+	want := map[string]string{"url": "https://example.com/old.git", "pushurl": "ssh://example.com/old.git"}
+	if got := config.GetSection("remote.origin"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected section to be unchanged after a failed SetSection, got %v", got)
+	}
+	if config.Has("remote.origin.key0") {
+		t.Error("Expected no valid keys to be applied when one key is invalid")
+	}
+
+	if err := config.SetSection("remote.origin", map[string]string{"URL": "https://example.com/new.git", "fetch": "+refs/heads/*:refs/remotes/origin/*"}); err != nil {
+		t.Fatalf("SetSection failed: %v", err)
+	}
+	want = map[string]string{"url": "https://example.com/new.git", "fetch": "+refs/heads/*:refs/remotes/origin/*"}
+	if got := config.GetSection("remote.origin"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected section to be replaced, got %v", got)
+	}
+	if config.Has("remote.origin.pushurl") {
+		t.Error("Expected SetSection to remove keys not given")
+	}
+
+	if err := config.SetSection("Core", map[string]string{"editor": "vim"}); err != nil {
+		t.Fatalf("SetSection failed: %v", err)
+	}
+	if editor, _ := Get[string](config, "core.editor"); editor != "vim" {
+		t.Errorf("Expected core.editor = vim, got '%s'", editor)
+	}
+
+	for _, section := range []string{"", "bad section", `remote "origin"`, "bad_section!.sub"} {
+		if err := config.SetSection(section, map[string]string{"key": "value"}); !errors.Is(err, ErrInvalidKeyFormat) {
+			t.Errorf("SetSection(%q) = %v, want ErrInvalidKeyFormat", section, err)
+		}
+	}
+}
+
+func TestMergeSection(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user": {"name": "Test User", "email": "old@example.com"},
+		},
+	}
+
+	if err := config.MergeSection("user", map[string]string{"email": "new@example.com", "signingkey": "ABC123"}); err != nil {
+		t.Fatalf("MergeSection failed: %v", err)
+	}
+	want := map[string]string{"name": "Test User", "email": "new@example.com", "signingkey": "ABC123"}
+	if got := config.GetSection("user"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected merged section %v, got %v", want, got)
+	}
+
+	if err := config.MergeSection("user", map[string]string{"name": "Other", "in valid": "x"}); !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Fatalf("Expected ErrInvalidKeyFormat, got %v", err)
+	}
+	if name, _ := Get[string](config, "user.name"); name != "Test User" {
+		t.Errorf("Expected failed MergeSection to change nothing, got name '%s'", name)
+	}
+}
+
+func TestGetWithFallback(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"core":     {"editor": "vim", "abbrev": "auto"},
+			"sequence": {"editor": "nano"},
+		},
+	}
+
+	if editor := GetWithFallback(config, []string{"sequence.editor", "core.editor"}, "vi"); editor != "nano" {
+		t.Errorf("Expected first key to win, got '%s'", editor)
+	}
+	if editor := GetWithFallback(config, []string{"git.editor", "core.editor"}, "vi"); editor != "vim" {
+		t.Errorf("Expected fallback to second key, got '%s'", editor)
+	}
+	if editor := GetWithFallback(config, []string{"git.editor", "other.editor"}, "vi"); editor != "vi" {
+		t.Errorf("Expected default, got '%s'", editor)
+	}
+	if abbrev := GetWithFallback(config, []string{"core.abbrev", "log.abbrev"}, 7); abbrev != 7 {
+		t.Errorf("Expected unconvertible key to be skipped, got %d", abbrev)
+	}
+}
+
+func TestGetFirstOf(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"core":     {"editor": "vim", "abbrev": "auto"},
+			"sequence": {"editor": "nano"},
+		},
+	}
+
+	if editor, err := GetFirstOf[string](config, "git.editor", "sequence.editor", "core.editor"); err != nil || editor != "nano" {
+		t.Errorf("Expected 'nano', got '%s' (%v)", editor, err)
+	}
+
+	if _, err := GetFirstOf[string](config, "git.editor", "other.editor"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound when no key is set, got %v", err)
+	}
+
+	if _, err := GetFirstOf[int](config, "core.abbrev", "log.abbrev"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for an unconvertible value, got %v", err)
+	}
+}
+
+func TestGetMultiValueWithSources(t *testing.T) {
+	parser := newParser()
+	config := &Config{
+		sections: make(map[string]map[string]string),
+	}
+
+	system := ConfigSource{Type: SourceTypeSystem, Path: "/etc/gitconfig"}
+	global := ConfigSource{Type: SourceTypeGlobal, Path: "/home/user/.gitconfig"}
+	local := ConfigSource{Type: SourceTypeLocal, Path: "/repo/.git/config"}
+
+	files := []struct {
+		source ConfigSource
+		data   string
+	}{
+		{system, "[credential]\n    helper = cache\n"},
+		{global, "[user]\n    name = Test User\n[credential]\n    helper = osxkeychain\n"},
+		{local, "# reset inherited helpers\n[credential]\n    helper =\n    helper = store --file=.creds\n"},
+	}
+	for _, file := range files {
+		if err := parser.parseConfigReader(strings.NewReader(file.data), config, file.source); err != nil {
+			t.Fatalf("parseConfigReader failed: %v", err)
+		}
+	}
+
+	all, err := config.GetMultiValueWithSources("credential.helper")
+	if err != nil {
+		t.Fatalf("GetMultiValueWithSources failed: %v", err)
+	}
+	expected := []ValueWithSource{
+		{Value: "cache", Source: system, Line: 2},
+		{Value: "osxkeychain", Source: global, Line: 4},
+		{Value: "", Source: local, Line: 3},
+		{Value: "store --file=.creds", Source: local, Line: 4},
+	}
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, all)
+	}
+
+	effective, err := config.GetMultiValueWithSources("credential.helper", ResetOnEmpty())
+	if err != nil {
+		t.Fatalf("GetMultiValueWithSources failed: %v", err)
+	}
+	if !reflect.DeepEqual(effective, expected[3:]) {
+		t.Errorf("Expected %+v after reset, got %+v", expected[3:], effective)
+	}
+
+	if err := config.Set("core.editor", "vim"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	set, err := config.GetMultiValueWithSources("core.editor")
+	if err != nil {
+		t.Fatalf("GetMultiValueWithSources failed: %v", err)
+	}
+	if want := []ValueWithSource{{Value: "vim"}}; !reflect.DeepEqual(set, want) {
+		t.Errorf("Expected %+v for a programmatic value, got %+v", want, set)
+	}
+
+	if _, err := config.GetMultiValueWithSources("credential.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestGetNumberOfSubsections(t *testing.T) {
+	config := parseTestConfig(t, `[remote "origin"]
+    url = https://example.com/repo.git
+[remote "upstream"]
+    url = https://example.com/upstream.git
+[remote "fork"]
+    url = https://example.com/fork.git
+[remotes]
+    default = origin
+[branch "main"]
+    remote = origin
+[branch "feature/x"]
+    remote = fork
+[submodule "lib"]
+    path = lib`)
+
+	if n := config.GetNumberOfRemotes(); n != 3 {
+		t.Errorf("GetNumberOfRemotes() = %d, want 3", n)
+	}
+	if n := config.GetNumberOfBranches(); n != 2 {
+		t.Errorf("GetNumberOfBranches() = %d, want 2", n)
+	}
+	if n := config.GetNumberOfSubmodules(); n != 1 {
+		t.Errorf("GetNumberOfSubmodules() = %d, want 1", n)
+	}
+
+	empty := &Config{}
+	if n := empty.GetNumberOfRemotes(); n != 0 {
+		t.Errorf("GetNumberOfRemotes() on empty config = %d, want 0", n)
+	}
+	if n := empty.GetNumberOfBranches() + empty.GetNumberOfSubmodules(); n != 0 {
+		t.Errorf("Expected no branches or submodules, got %d", n)
+	}
+}
+
+func TestGetSortedSectionsAndKeys(t *testing.T) {
+	config := parseTestConfig(t, `[alias]
+    st = status
+[remote "origin"]
+    url = https://example.com/repo.git
+[user]
+    name = Test User
+    email = test@example.com
+[branch "main"]
+    remote = origin
+[core]
+    editor = vim
+    bare = false
+[remote "fork"]
+    url = https://example.com/fork.git`)
+
+	expectedSections := []string{"core", "user", "remote.fork", "remote.origin", "branch.main", "alias"}
+	expectedKeys := []string{
+		"core.bare", "core.editor",
+		"user.email", "user.name",
+		"remote.fork.url", "remote.origin.url",
+		"branch.main.remote",
+		"alias.st",
+	}
+
+	for i := 0; i < 5; i++ {
+		if sections := config.GetSortedSections(); !reflect.DeepEqual(sections, expectedSections) {
+			t.Fatalf("GetSortedSections = %v, expected %v", sections, expectedSections)
+		}
+		if keys := config.GetSortedKeys(); !reflect.DeepEqual(keys, expectedKeys) {
+			t.Fatalf("GetSortedKeys = %v, expected %v", keys, expectedKeys)
+		}
+	}
+
+	first := config.String()
+	for i := 0; i < 5; i++ {
+		if config.String() != first {
+			t.Fatal("String output is not deterministic")
+		}
+	}
+	if strings.Index(first, "[core]") > strings.Index(first, "[user]") {
+		t.Errorf("Expected core before user in String output:\n%s", first)
+	}
+}
+
+func benchmarkReadConfig(b *testing.B) *Config {
+	b.Helper()
+
+	config := &Config{sections: make(map[string]map[string]string)}
+	if err := newParser().parseConfigReader(strings.NewReader(benchmarkConfig(200)), config, ConfigSource{}); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	return config
+}
+
+func BenchmarkGet(b *testing.B) {
+	config := benchmarkReadConfig(b)
+	for i := 0; i < b.N; i++ {
+		if _, err := Get[string](config, "remote.r100.url"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetSection(b *testing.B) {
+	config := benchmarkReadConfig(b)
+	for i := 0; i < b.N; i++ {
+		if len(config.GetSection("remote.r100")) == 0 {
+			b.Fatal("empty section")
+		}
+	}
+}
+
+func BenchmarkForEachInSection(b *testing.B) {
+	config := benchmarkReadConfig(b)
+	for i := 0; i < b.N; i++ {
+		n := 0
+		config.ForEachInSection("remote.r100", func(key, value string) bool {
+			n++
+			return true
+		})
+		if n == 0 {
+			b.Fatal("empty section")
+		}
+	}
+}
+
+func BenchmarkGetAll(b *testing.B) {
+	config := benchmarkReadConfig(b)
+	for i := 0; i < b.N; i++ {
+		_ = config.GetAll()
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	config := benchmarkReadConfig(b)
+	for i := 0; i < b.N; i++ {
+		_ = config.String()
+	}
+}
+
+func BenchmarkClone(b *testing.B) {
+	config := benchmarkReadConfig(b)
+	for i := 0; i < b.N; i++ {
+		_ = config.Clone()
+	}
+}
+
+func TestForEachInSection(t *testing.T) {
+	config := parseTestConfig(t, `[remote "origin"]
+    url = https://example.com/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    prune = true`)
+
+	got := make(map[string]string)
+	config.ForEachInSection("remote.origin", func(key, value string) bool {
+		got[key] = value
+		return true
+	})
+	if !reflect.DeepEqual(got, config.GetSection("remote.origin")) {
+		t.Errorf("ForEachInSection visited %v", got)
+	}
+
+	calls := 0
+	config.ForEachInSection("remote.origin", func(key, value string) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("Expected iteration to stop after 1 call, got %d", calls)
+	}
+
+	config.ForEachInSection("missing", func(key, value string) bool {
+		t.Errorf("Unexpected key %s in missing section", key)
+		return true
+	})
+}
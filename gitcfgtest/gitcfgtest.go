@@ -0,0 +1,120 @@
+// Package gitcfgtest provides fixtures for testing code that loads git
+// configuration with gitcfg: throwaway repositories with chosen config files
+// and assertions that name the file and line behind a wrong value.
+package gitcfgtest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/gitcfg"
+)
+
+// Keys of the files map passed to NewRepo for the standard config files. Any
+// other key is a path relative to the repository root, such as ".gitmodules"
+// or ".git/info/sparse-checkout".
+const (
+	Local    = gitcfg.LocalConfigFile    // the repository's .git/config
+	Worktree = gitcfg.WorktreeConfigFile // the main worktree's .git/config.worktree
+	Global   = "global"                  // ~/.gitconfig in the isolated home directory
+)
+
+// NewRepo creates a repository in a temporary directory, removed when the
+// test ends, and returns its root. files maps Local, Worktree, Global or a
+// path relative to the root to the content to write there; Local is written
+// empty when not given. The layout is complete enough for the git binary,
+// so configs may be loaded with or without WithGitCommand.
+//
+// The global config is isolated from the user's own: HOME points to a fresh
+// temporary directory holding the Global file, XDG_CONFIG_HOME is cleared and
+// GIT_CONFIG_NOSYSTEM is set. Since it changes the environment, NewRepo cannot
+// be used in parallel tests.
+func NewRepo(t testing.TB, files map[string]string) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	for _, dir := range []string{"objects", "refs"} {
+		if err := os.MkdirAll(filepath.Join(root, ".git", dir), 0o755); err != nil {
+			t.Fatalf("gitcfgtest: %v", err)
+		}
+	}
+	if _, ok := files[Local]; !ok {
+		writeFile(t, filepath.Join(root, filepath.FromSlash(Local)), "")
+	}
+
+	for name, content := range files {
+		if name == Global {
+			writeFile(t, filepath.Join(home, gitcfg.GlobalConfigFile), content)
+			continue
+		}
+		if filepath.IsAbs(name) || strings.HasPrefix(filepath.Clean(filepath.FromSlash(name)), "..") {
+			t.Fatalf("gitcfgtest: %q is not a path inside the repository", name)
+		}
+		writeFile(t, filepath.Join(root, filepath.FromSlash(name)), content)
+	}
+
+	return root
+}
+
+func writeFile(t testing.TB, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("gitcfgtest: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("gitcfgtest: %v", err)
+	}
+}
+
+// AssertKey reports an error unless key's effective value in cfg is want.
+// The message names the source and line the wrong value came from.
+func AssertKey(t testing.TB, cfg *gitcfg.Config, key, want string) {
+	t.Helper()
+
+	values, err := cfg.GetMultiValueWithSources(key)
+	if err != nil {
+		t.Errorf("%s: %v, want %q", key, err, want)
+		return
+	}
+	if got := values[len(values)-1]; got.Value != want {
+		t.Errorf("%s = %q (from %s), want %q", key, got.Value, origin(got), want)
+	}
+}
+
+// AssertNoKey reports an error if cfg sets key, naming where it was set.
+func AssertNoKey(t testing.TB, cfg *gitcfg.Config, key string) {
+	t.Helper()
+
+	values, err := cfg.GetMultiValueWithSources(key)
+	if errors.Is(err, gitcfg.ErrKeyNotFound) || errors.Is(err, gitcfg.ErrSectionNotFound) {
+		return
+	} else if err != nil {
+		t.Errorf("%s: %v", key, err)
+		return
+	}
+	got := values[len(values)-1]
+	t.Errorf("%s = %q (from %s), want it unset", key, got.Value, origin(got))
+}
+
+// origin describes where a value was set, e.g. "local /repo/.git/config:3".
+func origin(v gitcfg.ValueWithSource) string {
+	if v.Source.Path == "" {
+		return "no file"
+	}
+	location := v.Source.Type.String() + " " + v.Source.Path
+	if v.Line > 0 {
+		location += ":" + strconv.Itoa(v.Line)
+	}
+	return location
+}
@@ -0,0 +1,98 @@
+package gitcfgtest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unkn0wn-root/gitcfg"
+)
+
+// recorder is a testing.TB that collects failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNewRepo(t *testing.T) {
+	repo := NewRepo(t, map[string]string{
+		Global:        "[user]\n\tname = Global User\n\temail = global@example.com\n",
+		Local:         "[user]\n\tname = Local User\n[extensions]\n\tworktreeConfig = true\n",
+		Worktree:      "[core]\n\tsparseCheckout = true\n",
+		".gitmodules": "[submodule \"lib\"]\n\tpath = lib\n\turl = https://example.com/lib.git\n",
+	})
+
+	cfg, err := gitcfg.LoadAll(repo)
+	if err != nil {
+		t.Fatalf("LoadAll failed: %v", err)
+	}
+	AssertKey(t, cfg, "user.name", "Local User")
+	AssertKey(t, cfg, "user.email", "global@example.com")
+	AssertKey(t, cfg, gitcfg.CoreSparseCheckout, "true")
+	AssertNoKey(t, cfg, "core.editor")
+
+	if _, err := os.Stat(filepath.Join(repo, ".gitmodules")); err != nil {
+		t.Errorf("Expected the .gitmodules file to be written: %v", err)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return
+	}
+	viaGit, err := gitcfg.Load(gitcfg.WithLocal(), gitcfg.WithRepoPath(repo), gitcfg.WithGitCommand())
+	if err != nil {
+		t.Fatalf("Load with git failed: %v", err)
+	}
+	AssertKey(t, viaGit, "user.name", "Local User")
+}
+
+func TestNewRepoDefaults(t *testing.T) {
+	repo := NewRepo(t, nil)
+
+	cfg, err := gitcfg.LoadAll(repo)
+	if err != nil {
+		t.Fatalf("LoadAll failed: %v", err)
+	}
+	if size := cfg.Size(); size != 0 {
+		t.Errorf("Expected an isolated, empty config, got %d keys:\n%s", size, cfg)
+	}
+	if path := cfg.GetSources()[0].Path; path != filepath.Join(repo, ".git", "config") {
+		t.Errorf("Expected the empty local config to be loaded, got %s", path)
+	}
+}
+
+func TestAssertKeyReportsSource(t *testing.T) {
+	repo := NewRepo(t, map[string]string{Local: "[core]\n\tbare = false\n\teditor = vim\n"})
+	cfg, err := gitcfg.LoadLocal(repo)
+	if err != nil {
+		t.Fatalf("LoadLocal failed: %v", err)
+	}
+
+	r := &recorder{TB: t}
+	AssertKey(r, cfg, "core.editor", "nano")
+	AssertKey(r, cfg, "core.pager", "less")
+	AssertNoKey(r, cfg, "core.bare")
+	AssertKey(r, cfg, "core.bare", "false")
+
+	if len(r.errors) != 3 {
+		t.Fatalf("Expected 3 failures, got %q", r.errors)
+	}
+	local := filepath.Join(repo, ".git", "config")
+	if want := `core.editor = "vim" (from local ` + local + `:3), want "nano"`; r.errors[0] != want {
+		t.Errorf("Expected %q, got %q", want, r.errors[0])
+	}
+	if !strings.Contains(r.errors[1], "core.pager") || !strings.Contains(r.errors[1], `want "less"`) {
+		t.Errorf("Unexpected failure for a missing key: %q", r.errors[1])
+	}
+	if want := `core.bare = "false" (from local ` + local + `:2), want it unset`; r.errors[2] != want {
+		t.Errorf("Expected %q, got %q", want, r.errors[2])
+	}
+}
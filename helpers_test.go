@@ -0,0 +1,27 @@
+package gitcfg
+
+import (
+	"fmt"
+)
+
+// NewTestConfig builds a Config from dotted "section.key" → value pairs.
+// It is for use in tests only and panics on an invalid key so that broken
+// fixtures fail fast.
+func NewTestConfig(entries map[string]string) *Config {
+	config := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0),
+	}
+	for key, value := range entries {
+		if err := config.setRawValue(key, value); err != nil {
+			panic(fmt.Sprintf("NewTestConfig: %v", err))
+		}
+	}
+	return config
+}
+
+// NewTestConfigFromString parses git config data with ParseFromString. It is
+// for use in tests only.
+func NewTestConfigFromString(s string) (*Config, error) {
+	return ParseFromString(s)
+}
@@ -0,0 +1,146 @@
+package gitcfg
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// IdentityRole selects whether GetEffectiveUser resolves the author or the
+// committer identity.
+type IdentityRole int
+
+const (
+	IdentityAuthor IdentityRole = iota
+	IdentityCommitter
+)
+
+func (r IdentityRole) String() string {
+	if r == IdentityCommitter {
+		return "committer"
+	}
+	return "author"
+}
+
+type identityOptions struct {
+	role IdentityRole
+}
+
+type IdentityOption func(*identityOptions)
+
+// AsCommitter resolves the committer identity (GIT_COMMITTER_*,
+// committer.*) instead of the author identity.
+func AsCommitter() IdentityOption {
+	return func(opts *identityOptions) {
+		opts.role = IdentityCommitter
+	}
+}
+
+// GetEffectiveUser resolves the identity git would record, by default for the
+// author. For both name and email the order is:
+//
+//  1. GIT_AUTHOR_NAME / GIT_AUTHOR_EMAIL (GIT_COMMITTER_* with AsCommitter)
+//  2. author.name / author.email (committer.* with AsCommitter)
+//  3. user.name / user.email
+//
+// When a value is still missing and user.useConfigOnly is set,
+// ErrIdentityNotConfigured is returned. Otherwise git's implicit identity is
+// used: the EMAIL environment variable, then the login name and host name.
+// SigningKey is read from user.signingKey.
+func (c *Config) GetEffectiveUser(opts ...IdentityOption) (*User, error) {
+	options := &identityOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	envPrefix := "GIT_" + strings.ToUpper(options.role.String()) + "_"
+	section := options.role.String()
+
+	u := &User{
+		Name:       c.identityValue(envPrefix+"NAME", section+".name", UserName),
+		Email:      c.identityValue(envPrefix+"EMAIL", section+".email", UserEmail),
+		SigningKey: GetWithDefault(c, UserSigningKey, ""),
+	}
+
+	if u.Name != "" && u.Email != "" {
+		return u, nil
+	}
+
+	var useConfigOnly bool
+	if err := lookupOptional(c, UserUseConfigOnly, &useConfigOnly); err != nil {
+		return nil, err
+	}
+	if !useConfigOnly {
+		c.fillImplicitIdentity(u)
+	}
+
+	if u.Name == "" || u.Email == "" {
+		missing := "name"
+		if u.Name != "" {
+			missing = "email"
+		}
+		return nil, &ConfigError{
+			Op:  "identity",
+			Err: fmt.Errorf("%w: no %s %s", ErrIdentityNotConfigured, options.role, missing),
+		}
+	}
+
+	return u, nil
+}
+
+// identityValue returns the first non-empty value among the environment
+// variable and the given config keys.
+func (c *Config) identityValue(envVar string, keys ...string) string {
+	if value, ok := c.getenv(envVar); ok && value != "" {
+		return value
+	}
+	for _, key := range keys {
+		if value := GetWithDefault(c, key, ""); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// fillImplicitIdentity completes u the way git does without explicit
+// configuration.
+func (c *Config) fillImplicitIdentity(u *User) {
+	if u.Email == "" {
+		if email, ok := c.getenv("EMAIL"); ok && email != "" {
+			u.Email = email
+		}
+	}
+	if u.Name != "" && u.Email != "" {
+		return
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return
+	}
+	if u.Name == "" {
+		u.Name = current.Name
+		if u.Name == "" {
+			u.Name = current.Username
+		}
+	}
+	if u.Email == "" {
+		if host, err := os.Hostname(); err == nil && host != "" {
+			u.Email = current.Username + "@" + host
+		}
+	}
+}
+
+// getenv looks up an environment variable through the WithEnv hook, falling
+// back to the process environment.
+func (c *Config) getenv(key string) (string, bool) {
+	c.mu.RLock()
+	lookup := c.lookupEnv
+	c.mu.RUnlock()
+
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	return lookup(key)
+}
@@ -0,0 +1,115 @@
+package gitcfg
+
+import (
+	"errors"
+	"testing"
+)
+
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestGetEffectiveUserFromConfig(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Config User
+    email = config@example.com
+    signingKey = ABCDEF12
+`)
+	config.lookupEnv = envMap(nil)
+
+	u, err := config.GetEffectiveUser()
+	if err != nil {
+		t.Fatalf("GetEffectiveUser failed: %v", err)
+	}
+
+	expected := User{Name: "Config User", Email: "config@example.com", SigningKey: "ABCDEF12"}
+	if *u != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *u)
+	}
+}
+
+func TestGetEffectiveUserEnvironment(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Config User
+    email = config@example.com
+[committer]
+    email = committer@example.com
+`)
+	config.lookupEnv = envMap(map[string]string{
+		"GIT_AUTHOR_NAME":     "Env Author",
+		"GIT_COMMITTER_NAME":  "Env Committer",
+		"GIT_COMMITTER_EMAIL": "",
+	})
+
+	author, err := config.GetEffectiveUser()
+	if err != nil {
+		t.Fatalf("GetEffectiveUser failed: %v", err)
+	}
+	if author.Name != "Env Author" || author.Email != "config@example.com" {
+		t.Errorf("Unexpected author identity: %+v", author)
+	}
+
+	committer, err := config.GetEffectiveUser(AsCommitter())
+	if err != nil {
+		t.Fatalf("GetEffectiveUser failed: %v", err)
+	}
+	if committer.Name != "Env Committer" || committer.Email != "committer@example.com" {
+		t.Errorf("Unexpected committer identity: %+v", committer)
+	}
+}
+
+func TestGetEffectiveUserUseConfigOnly(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Config User
+    useConfigOnly = true
+`)
+	config.lookupEnv = envMap(map[string]string{"EMAIL": "implicit@example.com"})
+
+	if _, err := config.GetEffectiveUser(); !errors.Is(err, ErrIdentityNotConfigured) {
+		t.Errorf("Expected ErrIdentityNotConfigured, got %v", err)
+	}
+
+	// The environment counts as explicit configuration
+	config.lookupEnv = envMap(map[string]string{"GIT_AUTHOR_EMAIL": "env@example.com"})
+	u, err := config.GetEffectiveUser()
+	if err != nil {
+		t.Fatalf("GetEffectiveUser failed: %v", err)
+	}
+	if u.Email != "env@example.com" {
+		t.Errorf("Expected env@example.com, got %s", u.Email)
+	}
+}
+
+func TestGetEffectiveUserImplicitEmail(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Config User\n")
+	config.lookupEnv = envMap(map[string]string{"EMAIL": "implicit@example.com"})
+
+	u, err := config.GetEffectiveUser()
+	if err != nil {
+		t.Fatalf("GetEffectiveUser failed: %v", err)
+	}
+	if u.Email != "implicit@example.com" {
+		t.Errorf("Expected implicit@example.com, got %s", u.Email)
+	}
+}
+
+func TestLoadWithEnv(t *testing.T) {
+	config, err := Load(WithEnv(envMap(map[string]string{
+		"GIT_AUTHOR_NAME":  "Env Author",
+		"GIT_AUTHOR_EMAIL": "env@example.com",
+	})))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	u, err := config.GetEffectiveUser()
+	if err != nil {
+		t.Fatalf("GetEffectiveUser failed: %v", err)
+	}
+	if u.Name != "Env Author" || u.Email != "env@example.com" {
+		t.Errorf("Unexpected identity: %+v", u)
+	}
+}
@@ -0,0 +1,88 @@
+package gitcfg
+
+import (
+	"iter"
+	"sort"
+)
+
+// The iterators below walk a snapshot taken under the read lock when
+// iteration starts. The lock is not held while the loop body runs, so it is
+// safe to modify the config inside a range loop; such changes are not visible
+// to the iteration in progress.
+
+// All iterates over every key/value pair in sorted key order, yielding the
+// fully-qualified dotted key. Multi-valued keys are yielded once per value.
+func (c *Config) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, kv := range c.snapshotEntries("") {
+			if !yield(kv.Key, kv.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Section iterates over the keys of a single section (dotted form for
+// subsections, e.g. remote.origin) in sorted order. Keys are yielded without
+// the section prefix.
+func (c *Config) Section(name string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, kv := range c.snapshotEntries(name) {
+			if !yield(kv.Key, kv.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Sections iterates over the section names in sorted order.
+func (c *Config) Sections() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		sections := c.GetSections()
+		sort.Strings(sections)
+
+		for _, section := range sections {
+			if !yield(section) {
+				return
+			}
+		}
+	}
+}
+
+// snapshotEntries copies the entries of one section, or of all sections with
+// fully-qualified keys when section is empty.
+func (c *Config) snapshotEntries(section string) []KeyValue {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	var entries []KeyValue
+	appendSection := func(name, prefix string) {
+		keys := make([]string, 0, len(c.sections[name]))
+		for key := range c.sections[name] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			for _, value := range c.rawValues(name, key) {
+				entries = append(entries, KeyValue{Key: prefix + key, Value: value.value})
+			}
+		}
+	}
+
+	if section != "" {
+		appendSection(section, "")
+		return entries
+	}
+
+	names := make([]string, 0, len(c.sections))
+	for name := range c.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		appendSection(name, name+".")
+	}
+	return entries
+}
@@ -0,0 +1,96 @@
+package gitcfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigAll(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user":          {"name": "Test User", "email": "test@example.com"},
+			"remote.origin": {"url": "https://github.com/example/repo.git"},
+		},
+	}
+
+	var keys []string
+	for key, value := range config.All() {
+		keys = append(keys, key)
+		if value == "" {
+			t.Errorf("Expected value for key '%s'", key)
+		}
+	}
+
+	expected := []string{"remote.origin.url", "user.email", "user.name"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected %v, got %v", expected, keys)
+	}
+}
+
+func TestConfigSectionIterator(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"remote.origin": {"url": "https://github.com/example/repo.git", "fetch": "+refs/heads/*:refs/remotes/origin/*"},
+		},
+	}
+
+	got := make(map[string]string)
+	for key, value := range config.Section("remote.origin") {
+		got[key] = value
+	}
+	if !reflect.DeepEqual(got, config.sections["remote.origin"]) {
+		t.Errorf("Expected %v, got %v", config.sections["remote.origin"], got)
+	}
+
+	for range config.Section("missing") {
+		t.Error("Expected no entries for missing section")
+	}
+}
+
+func TestConfigSectionsIterator(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user": {"name": "Test User"},
+			"core": {"editor": "vim"},
+		},
+	}
+
+	var sections []string
+	for section := range config.Sections() {
+		sections = append(sections, section)
+		if section == "core" {
+			break
+		}
+	}
+
+	if !reflect.DeepEqual(sections, []string{"core"}) {
+		t.Errorf("Expected early break after 'core', got %v", sections)
+	}
+}
+
+func TestConfigIterateWhileMutating(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"user": {"name": "Test User", "email": "test@example.com"},
+		},
+	}
+
+	count := 0
+	for key, value := range config.All() {
+		// Writing inside the loop must not deadlock
+		if err := config.Set(key, value+"-changed"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if err := config.Set("extra.key", "value"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("Expected to iterate over snapshot of 2 entries, got %d", count)
+	}
+	if config.sections["user"]["name"] != "Test User-changed" {
+		t.Errorf("Expected mutation to apply, got '%s'", config.sections["user"]["name"])
+	}
+}
@@ -0,0 +1,220 @@
+package gitcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const jsonSourcesKey = "_sources"
+
+type jsonSource struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// MarshalJSON encodes the configuration as nested objects: sections map to
+// objects, subsections are nested one level deeper (remote.origin.url becomes
+// {"remote": {"origin": {"url": ...}}}) and multi-valued keys become arrays.
+// Configuration sources are listed under a top-level "_sources" array.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	root := make(map[string]any, len(c.sections)+1)
+
+	for section, sectionMap := range c.sections {
+		name, subsection, _ := strings.Cut(section, ".")
+
+		target, ok := root[name].(map[string]any)
+		if !ok {
+			if _, exists := root[name]; exists {
+				return nil, fmt.Errorf("gitconfig: marshal: section %s conflicts with %s", name, jsonSourcesKey)
+			}
+			target = make(map[string]any)
+			root[name] = target
+		}
+
+		if subsection != "" {
+			sub, ok := target[subsection].(map[string]any)
+			if !ok {
+				if _, exists := target[subsection]; exists {
+					return nil, fmt.Errorf("gitconfig: marshal: subsection %s conflicts with key %s.%s", section, name, subsection)
+				}
+				sub = make(map[string]any)
+				target[subsection] = sub
+			}
+			target = sub
+		}
+
+		for key := range sectionMap {
+			if _, exists := target[key]; exists {
+				return nil, fmt.Errorf("gitconfig: marshal: key %s.%s conflicts with a subsection", section, key)
+			}
+			values := valueStrings(c.rawValues(section, key))
+			if len(values) == 1 {
+				target[key] = values[0]
+			} else {
+				target[key] = values
+			}
+		}
+	}
+
+	if len(c.sources) > 0 {
+		sources := make([]jsonSource, len(c.sources))
+		for i, source := range c.sources {
+			sources[i] = jsonSource{Type: source.Type.String(), Path: source.Path}
+		}
+		root[jsonSourcesKey] = sources
+	}
+
+	return json.Marshal(root)
+}
+
+// UnmarshalJSON replaces the configuration with the contents of data, which
+// must have the shape produced by MarshalJSON. Keys are validated the same
+// way as values read from config files.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		return &ConfigError{Op: "unmarshal", Err: err}
+	}
+
+	decoded := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0),
+	}
+
+	for name, raw := range root {
+		if name == jsonSourcesKey {
+			sources, err := decodeJSONSources(raw)
+			if err != nil {
+				return &ConfigError{Op: "unmarshal", Key: jsonSourcesKey, Err: err}
+			}
+			decoded.sources = sources
+			continue
+		}
+
+		if err := decoded.decodeJSONSection(name, raw, true); err != nil {
+			return err
+		}
+	}
+
+	c.lock()
+	c.sections = decoded.sections
+	c.values = decoded.values
+	c.sources = decoded.sources
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *Config) decodeJSONSection(section string, raw json.RawMessage, allowSubsections bool) error {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return &ConfigError{
+			Op:  "unmarshal",
+			Key: section,
+			Err: fmt.Errorf("%w: section must be an object", ErrInvalidValue),
+		}
+	}
+
+	if len(entries) == 0 && (isValidSectionName(section) || isValidSubsectionName(section)) {
+		c.sections[section] = make(map[string]string)
+		return nil
+	}
+
+	for key, value := range entries {
+		fullKey := section + "." + key
+
+		if trimmed := bytes.TrimSpace(value); len(trimmed) > 0 && trimmed[0] == '{' {
+			if !allowSubsections {
+				return &ConfigError{
+					Op:  "unmarshal",
+					Key: fullKey,
+					Err: fmt.Errorf("%w: subsections cannot be nested", ErrInvalidValue),
+				}
+			}
+			if err := c.decodeJSONSection(fullKey, value, false); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values, err := decodeJSONValues(value)
+		if err != nil {
+			return &ConfigError{Op: "unmarshal", Key: fullKey, Err: err}
+		}
+		for _, v := range values {
+			if err := c.addRawValue(fullKey, v, nil, 0); err != nil {
+				return &ConfigError{Op: "unmarshal", Key: fullKey, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeJSONValues accepts a scalar or an array of scalars. Numbers and
+// booleans are kept in their literal form since git stores everything as text.
+func decodeJSONValues(raw json.RawMessage) ([]string, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		items = []json.RawMessage{raw}
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		var value any
+		if err := json.Unmarshal(item, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+		}
+
+		switch v := value.(type) {
+		case string:
+			values = append(values, v)
+		case bool, float64:
+			values = append(values, string(bytes.TrimSpace(item)))
+		default:
+			return nil, fmt.Errorf("%w: unsupported JSON value %s", ErrInvalidValue, item)
+		}
+	}
+
+	return values, nil
+}
+
+func decodeJSONSources(raw json.RawMessage) ([]ConfigSource, error) {
+	var entries []jsonSource
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+
+	sources := make([]ConfigSource, 0, len(entries))
+	for _, entry := range entries {
+		sourceType, err := parseSourceType(entry.Type)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, ConfigSource{Type: sourceType, Path: entry.Path})
+	}
+
+	return sources, nil
+}
+
+func parseSourceType(name string) (ConfigSourceType, error) {
+	for t := SourceTypeSystem; t.String() != "unknown"; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown source type %q", ErrInvalidValue, name)
+}
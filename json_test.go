@@ -0,0 +1,115 @@
+package gitcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func newJSONTestConfig(t *testing.T) *Config {
+	t.Helper()
+
+	parser := newParser()
+	config := &Config{
+		sections: make(map[string]map[string]string),
+	}
+
+	configData := `[user]
+    name = Test User
+    email = test@example.com
+[core]
+    autocrlf = true
+[remote "origin"]
+    url = https://github.com/example/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+[branch "main"]
+    remote = origin
+`
+	source := ConfigSource{Type: SourceTypeGlobal, Path: "/home/user/.gitconfig"}
+	if err := parser.parseConfigReader(strings.NewReader(configData), config, source); err != nil {
+		t.Fatalf("parseConfigReader failed: %v", err)
+	}
+	config.sources = append(config.sources, source)
+
+	return config
+}
+
+func TestMarshalJSONGolden(t *testing.T) {
+	config := newJSONTestConfig(t)
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	data = append(data, '\n')
+
+	golden := filepath.Join("testdata", "config.golden.json")
+	if *updateGolden {
+		if err := os.WriteFile(golden, data, 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("JSON output does not match golden file.\nExpected:\n%s\nGot:\n%s", expected, data)
+	}
+}
+
+func TestUnmarshalJSONRoundTrip(t *testing.T) {
+	original := newJSONTestConfig(t)
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+
+	var decoded Config
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded.GetAll(), original.GetAll()) {
+		t.Errorf("Round trip mismatch.\nExpected: %v\nGot: %v", original.GetAll(), decoded.GetAll())
+	}
+
+	fetch, err := decoded.GetMultiValue("remote.origin.fetch")
+	if err != nil {
+		t.Fatalf("GetMultiValue failed: %v", err)
+	}
+	if len(fetch) != 2 {
+		t.Errorf("Expected 2 fetch values, got %d", len(fetch))
+	}
+
+	if !reflect.DeepEqual(decoded.GetSources(), original.GetSources()) {
+		t.Errorf("Expected sources %v, got %v", original.GetSources(), decoded.GetSources())
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	tests := []string{
+		`{"user": "not an object"}`,
+		`{"user": {"na me": "value"}}`,
+		`{"remote": {"origin": {"nested": {"deep": "value"}}}}`,
+		`{"user": {"name": {"first": ["a", {"b": 1}]}}}`,
+		`{"_sources": [{"type": "nonsense", "path": "/x"}]}`,
+	}
+
+	for _, data := range tests {
+		var config Config
+		if err := json.Unmarshal([]byte(data), &config); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+}
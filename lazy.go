@@ -0,0 +1,73 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// lazyState defers loading of a config created with WithLazyLoad.
+type lazyState struct {
+	once sync.Once
+	opts *configOptions
+	err  error
+}
+
+// EnsureLoaded performs the deferred load of a config created with
+// WithLazyLoad and returns its error, if any. It is a no-op for configs that
+// were loaded eagerly.
+func (c *Config) EnsureLoaded() error {
+	if c.lazy == nil {
+		return nil
+	}
+
+	c.lazy.once.Do(func() {
+		loaded, err := loadConfig(context.Background(), c.lazy.opts)
+		if err != nil {
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				err = &ConfigError{Op: "load", Err: err}
+			}
+			c.lazy.err = err
+			return
+		}
+
+		c.mu.Lock()
+		c.sections = loaded.sections
+		c.values = loaded.values
+		c.sources = loaded.sources
+		c.defaults = loaded.defaults
+		c.warnings = loaded.warnings
+		c.stats = loaded.stats
+		c.comments = loaded.comments
+		c.maxLineLength = loaded.maxLineLength
+		c.limits = loaded.limits
+		c.strict = loaded.strict
+		c.logger = loaded.logger
+		c.load = loaded.load
+		c.mu.Unlock()
+	})
+	return c.lazy.err
+}
+
+// rlock read-locks the config, performing any deferred load first. A failed
+// load leaves the config empty; methods that return an error report it by
+// calling EnsureLoaded or loadErr.
+func (c *Config) rlock() {
+	c.EnsureLoaded()
+	c.mu.RLock()
+}
+
+// lock write-locks the config, performing any deferred load first.
+func (c *Config) lock() {
+	c.EnsureLoaded()
+	c.mu.Lock()
+}
+
+// loadErr returns the error of a failed deferred load.
+func (c *Config) loadErr() error {
+	if c.lazy == nil {
+		return nil
+	}
+	return c.lazy.err
+}
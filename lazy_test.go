@@ -0,0 +1,160 @@
+package gitcfg
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLoadWithLazyLoad(t *testing.T) {
+	repoPath := t.TempDir()
+	gitDir := filepath.Join(repoPath, ".git")
+
+	config, err := Load(WithLocal(), WithRepoPath(repoPath), WithLazyLoad())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Nothing exists on disk yet, so an eager load would have failed or
+	// produced an empty config
+	if err := os.Mkdir(gitDir, 0755); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "config"), []byte("[user]\n    name = Lazy User\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	name, err := Get[string](config, "user.name")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if name != "Lazy User" {
+		t.Errorf("Expected 'Lazy User', got '%s'", name)
+	}
+
+	// The load happens only once
+	if err := os.WriteFile(filepath.Join(gitDir, "config"), []byte("[user]\n    name = Changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if name := GetWithDefault(config, "user.name", ""); name != "Lazy User" {
+		t.Errorf("Expected the first load to be kept, got '%s'", name)
+	}
+
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if name := GetWithDefault(config, "user.name", ""); name != "Changed" {
+		t.Errorf("Expected Reload to pick up changes, got '%s'", name)
+	}
+}
+
+func TestLoadWithLazyLoadError(t *testing.T) {
+	repoPath := filepath.Join(t.TempDir(), "missing")
+
+	config, err := Load(WithLocal(), WithRepoPath(repoPath), WithLazyLoad())
+	if err != nil {
+		t.Fatalf("Expected no error before first access, got %v", err)
+	}
+
+	if config.Has("user.name") {
+		t.Error("Expected Has to be false after a failed load")
+	}
+
+	var configErr *ConfigError
+	if _, err := Get[string](config, "user.name"); !errors.As(err, &configErr) || configErr.Op != "load" {
+		t.Errorf("Expected load ConfigError from Get, got %v", err)
+	}
+	if err := config.EnsureLoaded(); !errors.As(err, &configErr) {
+		t.Errorf("Expected load ConfigError from EnsureLoaded, got %v", err)
+	}
+	if _, err := config.GetMultiValue("remote.origin.fetch"); !errors.As(err, &configErr) || configErr.Op != "load" {
+		t.Errorf("Expected load ConfigError from GetMultiValue, got %v", err)
+	}
+}
+
+func TestLazyLoadErrorReportedByAccessors(t *testing.T) {
+	config, err := Load(WithLocal(), WithRepoPath(filepath.Join(t.TempDir(), "missing")), WithLazyLoad())
+	if err != nil {
+		t.Fatalf("Expected no error before first access, got %v", err)
+	}
+	loadErr := config.EnsureLoaded()
+	if loadErr == nil {
+		t.Fatal("Expected the deferred load to fail")
+	}
+
+	ignore := func(op ChangeOp, key, oldVal, newVal string) error { return nil }
+	var dst struct {
+		Name string `gitcfg:"user.name"`
+	}
+	calls := map[string]func() error{
+		"GetRegexp":                  func() error { _, err := config.GetRegexp("^user"); return err },
+		"GetGlob":                    func() error { _, err := config.GetGlob("user.*"); return err },
+		"GetKeysMatchingPattern":     func() error { _, err := config.GetKeysMatchingPattern("user.*"); return err },
+		"GetSectionsMatchingPattern": func() error { _, err := config.GetSectionsMatchingPattern("remote.*"); return err },
+		"MarshalJSON":                func() error { _, err := config.MarshalJSON(); return err },
+		"MarshalText":                func() error { _, err := config.MarshalText(); return err },
+		"PrintTo":                    func() error { return config.PrintTo(io.Discard, OutputFormatINI) },
+		"WriteTo":                    func() error { _, err := config.WriteTo(io.Discard); return err },
+		"Unmarshal":                  func() error { return config.Unmarshal(&dst) },
+		"GetAllRemotes":              func() error { _, err := config.GetAllRemotes(); return err },
+		"GetRemote":                  func() error { _, err := config.GetRemote("origin"); return err },
+		"GetRemotesByURL":            func() error { _, err := config.GetRemotesByURL("example.com"); return err },
+		"GetAllBranches":             func() error { _, err := config.GetAllBranches(); return err },
+		"GetBranch":                  func() error { _, err := config.GetBranch("main"); return err },
+		"GetSubmodules":              func() error { _, err := config.GetSubmodules(); return err },
+		"GetAdviceConfig":            func() error { _, err := config.GetAdviceConfig(); return err },
+		"GetFilterDriver":            func() error { _, err := config.GetFilterDriver("lfs"); return err },
+		"GetSSHCommand":              func() error { _, err := config.GetSSHCommand(""); return err },
+		"GetEffectiveProxy":          func() error { _, err := config.GetEffectiveProxy("https://example.com"); return err },
+		"GetHEAD":                    func() error { _, err := config.GetHEAD(); return err },
+		"GetPackedRefs":              func() error { _, err := config.GetPackedRefs(); return err },
+		"GetSparseCheckoutPatterns":  func() error { _, err := config.GetSparseCheckoutPatterns(); return err },
+		"GetAlternatesConfig":        func() error { _, err := config.GetAlternatesConfig(); return err },
+		"IterateChanges":             func() error { return config.IterateChanges(nil, ignore) },
+		"Set":                        func() error { return config.Set("user.name", "Test User") },
+		"AppendValue":                func() error { return config.AppendValue("remote.origin.fetch", "+refs/tags/*:refs/tags/*") },
+		"SetSection":                 func() error { return config.SetSection("user", map[string]string{"name": "Test User"}) },
+		"Merge":                      func() error { return config.Merge(&Config{}, true) },
+		"Transaction":                func() error { return config.Transaction(func(*Config) error { return nil }) },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, loadErr) {
+			t.Errorf("%s: expected the load error, got %v", name, err)
+		}
+	}
+}
+
+func TestLazyLoadConcurrentAccess(t *testing.T) {
+	repoPath := t.TempDir()
+	gitDir := filepath.Join(repoPath, ".git")
+	if err := os.Mkdir(gitDir, 0755); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "config"), []byte("[core]\n    editor = vim\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := Load(WithLocal(), WithRepoPath(repoPath), WithLazyLoad())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if editor := GetWithDefault(config, "core.editor", ""); editor != "vim" {
+				t.Errorf("Expected 'vim', got '%s'", editor)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := config.EnsureLoaded(); err != nil {
+		t.Errorf("EnsureLoaded failed: %v", err)
+	}
+}
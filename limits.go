@@ -0,0 +1,87 @@
+package gitcfg
+
+import (
+	"fmt"
+	"io"
+)
+
+// Unlimited disables a single field of Limits.
+const Unlimited = -1
+
+// Limits bounds the resources a load may spend on config files, protecting
+// services that read configs from untrusted repositories. A zero field takes
+// its value from DefaultLimits and a field set to Unlimited is not checked.
+// Exceeding a limit fails the load with an error wrapping ErrLimitExceeded.
+//
+// Limits apply to files read directly and to ParseFromReader; with
+// WithGitCommand, git does the parsing and only its own limits apply.
+//
+// There is deliberately no MaxIncludeDepth. Files read directly never follow
+// include.path or includeIf, and with WithGitCommand, git follows them and
+// stops at its own fixed depth of 10, which cannot be configured.
+type Limits struct {
+	// MaxFileSize is the largest config file, in bytes.
+	MaxFileSize int64
+	// MaxKeys is the number of values a load may store, counting every
+	// value of a multi-valued key.
+	MaxKeys int
+	// MaxSections is the number of distinct sections and subsections.
+	MaxSections int
+	// MaxValueLength is the longest value, in bytes, after unquoting.
+	MaxValueLength int
+}
+
+// DefaultLimits are the limits used unless WithLimits says otherwise. They
+// are far above anything a real config needs.
+var DefaultLimits = Limits{
+	MaxFileSize:    64 << 20,
+	MaxKeys:        1_000_000,
+	MaxSections:    100_000,
+	MaxValueLength: DefaultMaxLineLength,
+}
+
+// NoLimits turns every limit off.
+var NoLimits = Limits{
+	MaxFileSize:    Unlimited,
+	MaxKeys:        Unlimited,
+	MaxSections:    Unlimited,
+	MaxValueLength: Unlimited,
+}
+
+// withDefaults fills zero fields from DefaultLimits.
+func (l Limits) withDefaults() Limits {
+	if l.MaxFileSize == 0 {
+		l.MaxFileSize = DefaultLimits.MaxFileSize
+	}
+	if l.MaxKeys == 0 {
+		l.MaxKeys = DefaultLimits.MaxKeys
+	}
+	if l.MaxSections == 0 {
+		l.MaxSections = DefaultLimits.MaxSections
+	}
+	if l.MaxValueLength == 0 {
+		l.MaxValueLength = DefaultLimits.MaxValueLength
+	}
+	return l
+}
+
+// exceeds reports whether n is over limit, which may be Unlimited.
+func exceeds[T int | int64](n, limit T) bool {
+	return limit >= 0 && n > limit
+}
+
+func limitError(name string, limit int64) error {
+	return fmt.Errorf("%w: %s of %d", ErrLimitExceeded, name, limit)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
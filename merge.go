@@ -0,0 +1,304 @@
+package gitcfg
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Merge copies other's keys into the receiver. With overwrite set, other takes
+// precedence and its values win; otherwise other only fills keys the receiver
+// does not define. In both cases the values of multi-valued keys are
+// concatenated in precedence order (lowest first), and other's sources are
+// appended to the receiver's.
+//
+// The merge is applied under a single write lock, so concurrent readers see
+// either the old or the fully merged state. If other contains an invalid key
+// nothing is merged.
+func (c *Config) Merge(other *Config, overwrite bool) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	if other == nil {
+		return nil
+	}
+
+	// Work on a copy so merging a config into itself cannot deadlock
+	src := other.Clone()
+
+	for section, sectionMap := range src.sections {
+		for key := range sectionMap {
+			if fullKey := section + "." + key; !isValidConfigKey(fullKey) {
+				return &ConfigError{
+					Op:      "merge",
+					Key:     key,
+					Section: section,
+					Err:     fmt.Errorf("%w: %s", ErrInvalidKeyFormat, fullKey),
+				}
+			}
+		}
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	for section, sectionMap := range src.sections {
+		if len(sectionMap) == 0 {
+			if c.sections == nil {
+				c.sections = make(map[string]map[string]string)
+			}
+			if c.sections[section] == nil {
+				c.sections[section] = make(map[string]string)
+			}
+			continue
+		}
+
+		for key := range sectionMap {
+			incoming := src.rawValues(section, key)
+			existing := c.rawValues(section, key)
+
+			merged := make([]configValue, 0, len(existing)+len(incoming))
+			if overwrite {
+				merged = append(append(merged, existing...), incoming...)
+			} else {
+				merged = append(append(merged, incoming...), existing...)
+			}
+			c.putValues(section, key, merged)
+		}
+	}
+
+	c.sources = append(c.sources, src.sources...)
+	c.partial = c.partial || src.partial
+	return nil
+}
+
+// AddSource parses the config file at path and layers it over the receiver
+// as a source of type t with the highest precedence: its values follow the
+// existing values of each key, so they win, and keep their file and line.
+// path is appended to the sources, so Reload reads it again. The file is
+// parsed with the settings the receiver was loaded with, before the lock is
+// taken, and then merged under a single write lock. If the file cannot be
+// read or parsed the receiver is left unchanged.
+func (c *Config) AddSource(ctx context.Context, path string, t ConfigSourceType) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.rlock()
+	parser := newParser()
+	parser.maxLineLength = c.maxLineLength
+	parser.limits = c.limits
+	parser.strict = c.strict
+	parser.logger = c.logger
+	parser.comments = c.load != nil && c.load.comments
+	c.mu.RUnlock()
+
+	source := ConfigSource{Type: t, Path: path}
+	added := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0, 1),
+	}
+	start := time.Now()
+	if err := parser.parseConfigFile(source, added); err != nil {
+		return err
+	}
+	parser.debug("config source added", "type", source.Type, "path", source.Path,
+		"values", parser.values, "duration", time.Since(start))
+	stat := fileStat(source, parser.values, parser.bytes, start)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	for section, valueMap := range added.values {
+		for key, incoming := range valueMap {
+			existing := c.rawValues(section, key)
+			merged := make([]configValue, 0, len(existing)+len(incoming))
+			c.putValues(section, key, append(append(merged, existing...), incoming...))
+		}
+	}
+
+	c.sources = append(c.sources, source)
+	c.stats = append(c.stats, stat)
+	c.comments = append(c.comments, added.comments...)
+	return nil
+}
+
+// RemoveSource drops the source whose path is path, together with every
+// value it set, so the effective value of each key falls back to the
+// remaining sources, as if the file had never been loaded. Values set
+// programmatically are kept, and defaults from WithDefaults fill the keys
+// left without a value. GetSources, SourceStats and Reload no longer see the
+// source. Comments kept by WithPreserveComments are not tied to a source and
+// stay. It fails with an error wrapping ErrSourceNotFound when no source has
+// that path.
+func (c *Config) RemoveSource(path string) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	index := slices.IndexFunc(c.sources, func(source ConfigSource) bool { return source.Path == path })
+	if index < 0 {
+		return &ConfigError{
+			Op:     "remove",
+			Source: path,
+			Err:    fmt.Errorf("%w: %s", ErrSourceNotFound, path),
+		}
+	}
+	removed := c.sources[index]
+
+	for section, valueMap := range c.values {
+		for key, values := range valueMap {
+			kept := make([]configValue, 0, len(values))
+			for _, v := range values {
+				if v.source == nil || *v.source != removed {
+					kept = append(kept, v)
+				}
+			}
+			switch {
+			case len(kept) == len(values):
+			case len(kept) == 0:
+				c.removeValues(section, key)
+			default:
+				c.putValues(section, key, kept)
+			}
+		}
+	}
+
+	defaultSource := &ConfigSource{Type: SourceTypeDefault}
+	for key, value := range c.defaults {
+		section, name, err := splitValidKey(key)
+		if err != nil {
+			continue
+		}
+		if _, exists := c.sections[section][name]; !exists {
+			c.putValues(section, name, []configValue{{value: value, source: defaultSource}})
+		}
+	}
+
+	c.sources = slices.Delete(c.sources, index, index+1)
+	c.stats = slices.DeleteFunc(c.stats, func(stat SourceStat) bool {
+		return stat.Type == removed.Type && stat.Path == removed.Path
+	})
+	return nil
+}
+
+// MergeAll merges configs into a new Config in increasing order of
+// precedence: values from later configs override earlier ones. Nil configs
+// and configs with invalid keys are skipped.
+func MergeAll(configs ...*Config) *Config {
+	merged := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0),
+	}
+
+	for _, config := range configs {
+		_ = merged.Merge(config, true)
+	}
+
+	return merged
+}
+
+// Import adds the keys of other that the receiver does not define, with all
+// their values and the sources they came from. Keys the receiver already
+// has are left alone, values and all, unlike Merge with overwrite unset,
+// which still combines the values of multi-valued keys. Sources are appended
+// once each, and only if they supplied an imported value. If other contains
+// an invalid key nothing is imported.
+func (c *Config) Import(other *Config) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	if other == nil {
+		return nil
+	}
+
+	// Work on a copy so importing a config into itself cannot deadlock
+	src := other.Clone()
+
+	for section, sectionMap := range src.sections {
+		for key := range sectionMap {
+			if fullKey := section + "." + key; !isValidConfigKey(fullKey) {
+				return &ConfigError{
+					Op:      "import",
+					Key:     key,
+					Section: section,
+					Err:     fmt.Errorf("%w: %s", ErrInvalidKeyFormat, fullKey),
+				}
+			}
+		}
+	}
+
+	c.lock()
+	defer c.mu.Unlock()
+
+	used := make(map[ConfigSource]bool)
+	for section, sectionMap := range src.sections {
+		for key := range sectionMap {
+			if _, exists := c.sections[section][key]; exists {
+				continue
+			}
+			values := src.rawValues(section, key)
+			for _, v := range values {
+				if v.source != nil {
+					used[*v.source] = true
+				}
+			}
+			c.putValues(section, key, values)
+		}
+	}
+
+	for _, source := range src.sources {
+		if used[source] && !slices.Contains(c.sources, source) {
+			c.sources = append(c.sources, source)
+		}
+	}
+	return nil
+}
+
+// Export returns a new config holding only the values loaded from
+// repository-local config files, as a way to extract the repository's own
+// settings from a merged view. A multi-valued key keeps just its local
+// values, and the result lists only the local sources.
+func (c *Config) Export() *Config {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	exported := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0),
+	}
+
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			var local []configValue
+			for _, v := range c.rawValues(section, key) {
+				if v.source != nil && v.source.Type == SourceTypeLocal {
+					local = append(local, v)
+				}
+			}
+			if len(local) > 0 {
+				exported.putValues(section, key, local)
+			}
+		}
+	}
+
+	for _, source := range c.sources {
+		if source.Type == SourceTypeLocal {
+			exported.sources = append(exported.sources, source)
+		}
+	}
+	return exported
+}
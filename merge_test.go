@@ -0,0 +1,396 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestConfigMergeOverwrite(t *testing.T) {
+	base := parseTestConfig(t, `[user]
+    name = Default User
+    email = default@example.com
+[credential]
+    helper = cache
+`)
+	user := parseTestConfig(t, `[user]
+    name = Real User
+[credential]
+    helper = store
+[core]
+    editor = vim
+`)
+	user.sources = []ConfigSource{{Type: SourceTypeGlobal, Path: "/home/user/.gitconfig"}}
+
+	if err := base.Merge(user, true); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	if name, _ := Get[string](base, "user.name"); name != "Real User" {
+		t.Errorf("Expected 'Real User', got '%s'", name)
+	}
+	if email, _ := Get[string](base, "user.email"); email != "default@example.com" {
+		t.Errorf("Expected default email to be kept, got '%s'", email)
+	}
+	if editor, _ := Get[string](base, "core.editor"); editor != "vim" {
+		t.Errorf("Expected 'vim', got '%s'", editor)
+	}
+
+	helpers, _ := base.GetMultiValue("credential.helper")
+	if !reflect.DeepEqual(helpers, []string{"cache", "store"}) {
+		t.Errorf("Expected helpers in precedence order, got %v", helpers)
+	}
+
+	if len(base.GetSources()) != 1 {
+		t.Errorf("Expected merged sources to be appended, got %v", base.GetSources())
+	}
+}
+
+func TestConfigMergeFillGaps(t *testing.T) {
+	user := parseTestConfig(t, `[user]
+    name = Real User
+[credential]
+    helper = store
+`)
+	defaults := parseTestConfig(t, `[user]
+    name = Default User
+    email = default@example.com
+[credential]
+    helper = cache
+`)
+
+	if err := user.Merge(defaults, false); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	if name, _ := Get[string](user, "user.name"); name != "Real User" {
+		t.Errorf("Expected existing name to be kept, got '%s'", name)
+	}
+	if email, _ := Get[string](user, "user.email"); email != "default@example.com" {
+		t.Errorf("Expected gap to be filled, got '%s'", email)
+	}
+
+	helpers, _ := user.GetMultiValue("credential.helper")
+	if !reflect.DeepEqual(helpers, []string{"cache", "store"}) {
+		t.Errorf("Expected lower-precedence helper first, got %v", helpers)
+	}
+	if helper, _ := Get[string](user, "credential.helper"); helper != "store" {
+		t.Errorf("Expected receiver's helper to stay effective, got '%s'", helper)
+	}
+}
+
+func TestConfigMergeInvalidKey(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+	other := &Config{
+		sections: map[string]map[string]string{
+			"core":        {"editor": "vim"},
+			"bad section": {"key": "value"},
+		},
+	}
+
+	if err := config.Merge(other, true); err == nil {
+		t.Fatal("Expected error for invalid key")
+	}
+	if config.Has("core.editor") {
+		t.Error("Expected no keys to be merged on error")
+	}
+}
+
+func TestConfigMergeSelf(t *testing.T) {
+	config := parseTestConfig(t, "[remote \"origin\"]\n    fetch = +refs/heads/*:refs/remotes/origin/*\n")
+
+	if err := config.Merge(config, true); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	values, _ := config.GetMultiValue("remote.origin.fetch")
+	if len(values) != 2 {
+		t.Errorf("Expected values to be concatenated, got %v", values)
+	}
+}
+
+func TestConfigMergeConcurrentReaders(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+	other := parseTestConfig(t, "[user]\n    name = Other User\n    email = other@example.com\n")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				config.GetAll()
+			}
+		}()
+	}
+
+	if err := config.Merge(other, true); err != nil {
+		t.Errorf("Merge failed: %v", err)
+	}
+	wg.Wait()
+}
+
+func TestMergeAll(t *testing.T) {
+	system := parseTestConfig(t, "[core]\n    editor = nano\n    pager = less\n")
+	global := parseTestConfig(t, "[core]\n    editor = vim\n")
+	local := parseTestConfig(t, "[user]\n    name = Local User\n")
+
+	merged := MergeAll(system, nil, global, local)
+
+	expected := map[string]map[string]string{
+		"core": {"editor": "vim", "pager": "less"},
+		"user": {"name": "Local User"},
+	}
+	if !reflect.DeepEqual(merged.GetAll(), expected) {
+		t.Errorf("Expected %v, got %v", expected, merged.GetAll())
+	}
+}
+
+func TestConfigImport(t *testing.T) {
+	config := parseTestConfig(t, `[user]
+    name = Real User
+[credential]
+    helper = store
+`)
+	plugin := parseTestConfig(t, `[user]
+    name = Plugin User
+    email = plugin@example.com
+[credential]
+    helper = cache
+[lfs]
+    batch = false
+`)
+	plugin.sources = []ConfigSource{{Type: SourceTypeCustom, Path: "/etc/plugin.gitconfig"}}
+	for _, values := range plugin.values {
+		for _, list := range values {
+			for i := range list {
+				list[i].source = &plugin.sources[0]
+			}
+		}
+	}
+
+	if err := config.Import(plugin); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if name, _ := Get[string](config, "user.name"); name != "Real User" {
+		t.Errorf("Expected Import to keep 'Real User', got '%s'", name)
+	}
+	if helpers, _ := config.GetMultiValue("credential.helper"); !reflect.DeepEqual(helpers, []string{"store"}) {
+		t.Errorf("Expected existing multi-valued key to be left alone, got %v", helpers)
+	}
+	if email, _ := Get[string](config, "user.email"); email != "plugin@example.com" {
+		t.Errorf("Expected imported email, got '%s'", email)
+	}
+	if _, source, err := config.GetWithSource("lfs.batch"); err != nil || source == nil || source.Path != "/etc/plugin.gitconfig" {
+		t.Errorf("Expected imported key to keep its source, got %v, %v", source, err)
+	}
+	if sources := config.GetSources(); !reflect.DeepEqual(sources, plugin.sources) {
+		t.Errorf("Expected the plugin source to be added once, got %v", sources)
+	}
+
+	if err := config.Import(plugin); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if sources := config.GetSources(); len(sources) != 1 {
+		t.Errorf("Expected no new sources from a second Import, got %v", sources)
+	}
+}
+
+func TestConfigExport(t *testing.T) {
+	dir := t.TempDir()
+	systemPath := filepath.Join(dir, "gitconfig")
+	localPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(systemPath, []byte("[core]\n    autocrlf = input\n[credential]\n    helper = cache\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(localPath, []byte("[core]\n    bare = false\n[credential]\n    helper = store\n[remote \"origin\"]\n    url = https://example.com/repo.git\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{sections: make(map[string]map[string]string)}
+	for _, source := range []ConfigSource{{Type: SourceTypeSystem, Path: systemPath}, {Type: SourceTypeLocal, Path: localPath}} {
+		if err := newParser().parseConfigFile(source, config); err != nil {
+			t.Fatalf("parseConfigFile failed: %v", err)
+		}
+		config.sources = append(config.sources, source)
+	}
+
+	exported := config.Export()
+
+	expected := []string{"core.bare", "credential.helper", "remote.origin.url"}
+	if keys := exported.KeysUnder(""); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected local keys %v, got %v", expected, keys)
+	}
+	if helpers, _ := exported.GetMultiValue("credential.helper"); !reflect.DeepEqual(helpers, []string{"store"}) {
+		t.Errorf("Expected only the local helper, got %v", helpers)
+	}
+	if sources := exported.GetSources(); len(sources) != 1 || sources[0].Path != localPath {
+		t.Errorf("Expected only the local source, got %v", sources)
+	}
+	if config.Size() != 4 {
+		t.Errorf("Export modified the receiver")
+	}
+}
+
+func TestConfigAddSource(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n[remote \"origin\"]\n    fetch = +refs/heads/*:refs/remotes/origin/*\n")
+
+	path := filepath.Join(t.TempDir(), "policy.cfg")
+	writeTestFile(t, path, "[user]\n\tname = Policy User\n[remote \"origin\"]\n\tfetch = +refs/tags/*:refs/tags/*\n")
+
+	if err := config.AddSource(context.Background(), path, SourceTypeCustom); err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+
+	if name, _ := Get[string](config, "user.name"); name != "Policy User" {
+		t.Errorf("Expected the added source to take precedence, got '%s'", name)
+	}
+	fetch, _ := config.GetMultiValue("remote.origin.fetch")
+	expected := []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"}
+	if !reflect.DeepEqual(fetch, expected) {
+		t.Errorf("Expected %q, got %q", expected, fetch)
+	}
+
+	values, err := config.GetMultiValueWithSources("user.name")
+	if err != nil {
+		t.Fatalf("GetMultiValueWithSources failed: %v", err)
+	}
+	if last := values[len(values)-1]; last.Source != (ConfigSource{Type: SourceTypeCustom, Path: path}) || last.Line != 2 {
+		t.Errorf("Expected the value to come from %s:2, got %+v", path, last)
+	}
+
+	sources := config.GetSources()
+	if sources[len(sources)-1].Path != path {
+		t.Errorf("Expected %s to be the last source, got %+v", path, sources)
+	}
+	if stats := config.SourceStats(); len(stats) != 1 || stats[0].Path != path || stats[0].KeyCount != 2 {
+		t.Errorf("Expected stats for the added source, got %+v", stats)
+	}
+
+	before := config.String()
+	err = config.AddSource(context.Background(), filepath.Join(t.TempDir(), "missing.cfg"), SourceTypeCustom)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+	if config.String() != before {
+		t.Error("Expected a failed AddSource to leave the config unchanged")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := config.AddSource(ctx, path, SourceTypeCustom); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestConfigAddSourceReload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	base := filepath.Join(t.TempDir(), "base.cfg")
+	writeTestFile(t, base, "[user]\n\tname = Base User\n")
+	config, err := Load(WithFile(base))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	extra := filepath.Join(t.TempDir(), "extra.cfg")
+	writeTestFile(t, extra, "[user]\n\temail = extra@example.com\n")
+	if err := config.AddSource(context.Background(), extra, SourceTypeCustom); err != nil {
+		t.Fatalf("AddSource failed: %v", err)
+	}
+
+	writeTestFile(t, extra, "[user]\n\temail = changed@example.com\n")
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if email, _ := Get[string](config, "user.email"); email != "changed@example.com" {
+		t.Errorf("Expected Reload to reread the added source, got '%s'", email)
+	}
+}
+
+func TestConfigAddSourceConcurrentReaders(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n")
+	path := filepath.Join(t.TempDir(), "extra.cfg")
+	writeTestFile(t, path, "[user]\n\temail = extra@example.com\n")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				config.GetAll()
+			}
+		}()
+	}
+
+	if err := config.AddSource(context.Background(), path, SourceTypeCustom); err != nil {
+		t.Errorf("AddSource failed: %v", err)
+	}
+	wg.Wait()
+}
+
+func TestConfigRemoveSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.cfg")
+	writeTestFile(t, system, "[core]\n\tautocrlf = input\n\teditor = vim\n[remote \"origin\"]\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n")
+	local := filepath.Join(dir, "local.cfg")
+	writeTestFile(t, local, "[core]\n\teditor = nano\n[remote \"origin\"]\n\tfetch = +refs/tags/*:refs/tags/*\n")
+
+	config, err := Load(WithFile(system), WithFile(local),
+		WithDefaults(map[string]string{"core.autocrlf": "false"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	config.Set("user.name", "Test User")
+
+	if err := config.RemoveSource(system); err != nil {
+		t.Fatalf("RemoveSource failed: %v", err)
+	}
+
+	if editor, _ := Get[string](config, "core.editor"); editor != "nano" {
+		t.Errorf("Expected 'nano', got '%s'", editor)
+	}
+	if autocrlf, _ := Get[string](config, "core.autocrlf"); autocrlf != "false" {
+		t.Errorf("Expected the default to fill core.autocrlf, got '%s'", autocrlf)
+	}
+	if fetch, _ := config.GetMultiValue("remote.origin.fetch"); !reflect.DeepEqual(fetch, []string{"+refs/tags/*:refs/tags/*"}) {
+		t.Errorf("Expected only the local refspec, got %q", fetch)
+	}
+	if name, _ := Get[string](config, "user.name"); name != "Test User" {
+		t.Errorf("Expected programmatic values to be kept, got '%s'", name)
+	}
+
+	values, err := config.GetMultiValueWithSources("core.editor")
+	if err != nil || len(values) != 1 || values[0].Source.Path != local {
+		t.Errorf("Expected core.editor to come from %s only, got %+v, %v", local, values, err)
+	}
+	if sources := config.GetSources(); len(sources) != 1 || sources[0].Path != local {
+		t.Errorf("Expected only %s to remain, got %+v", local, sources)
+	}
+	if stats := config.SourceStats(); len(stats) != 1 || stats[0].Path != local {
+		t.Errorf("Expected only the stats of %s to remain, got %+v", local, stats)
+	}
+
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if autocrlf, _ := Get[string](config, "core.autocrlf"); autocrlf != "false" {
+		t.Errorf("Expected Reload to skip the removed source, got core.autocrlf = '%s'", autocrlf)
+	}
+
+	err = config.RemoveSource(system)
+	if !errors.Is(err, ErrSourceNotFound) {
+		t.Errorf("Expected ErrSourceNotFound, got %v", err)
+	}
+}
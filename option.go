@@ -1,21 +1,50 @@
 package gitcfg
 
 import (
+    "bytes"
     "fmt"
+    "io"
+    "log/slog"
+    "os"
+    "os/exec"
+    "strings"
     "time"
     "context"
 )
 
 const DefaultTimeout = 30 * time.Second
 
+// DefaultMaxLineLength is the longest config file line accepted unless
+// WithMaxLineLength says otherwise. It is large enough for long aliases and
+// insteadOf lists while bounding memory use on malformed files.
+const DefaultMaxLineLength = 16 << 20
+
 type configOptions struct {
 	includeSystem   bool
 	includeGlobal   bool
 	includeLocal    bool
 	includeWorktree bool
 	repoPath        string
+	gitDir          string
+	files           []string
+	blobRefs        []string
+	gitBinary       string
+	gitEnv          []string
+	gitEnvSet       bool
 	useGitCommand   bool
 	timeout         time.Duration
+	deadline        time.Time
+	defaults        map[string]string
+	redact          bool
+	sensitiveKeys   []string
+	lookupEnv       func(string) (string, bool)
+	lazyLoad        bool
+	skipUnreadable  bool
+	maxLineLength   int
+	limits          Limits
+	strict          bool
+	logger          *slog.Logger
+	comments        bool
 }
 
 type ConfigOption func(*configOptions)
@@ -32,6 +61,14 @@ func WithGlobal() ConfigOption {
 	}
 }
 
+// WithNoGlobal leaves out the global config, which Load otherwise always
+// reads. A later WithGlobal adds it back.
+func WithNoGlobal() ConfigOption {
+	return func(opts *configOptions) {
+		opts.includeGlobal = false
+	}
+}
+
 func WithLocal() ConfigOption {
 	return func(opts *configOptions) {
 		opts.includeLocal = true
@@ -50,18 +87,184 @@ func WithRepoPath(path string) ConfigOption {
 	}
 }
 
+// WithGitDir points the local and worktree sources at a git directory itself,
+// such as a bare repository or the target of GIT_DIR, reading <path>/config
+// and <path>/config.worktree instead of the .git directory of a repository
+// root. It takes precedence over WithRepoPath for those sources.
+func WithGitDir(path string) ConfigOption {
+	return func(opts *configOptions) {
+		opts.gitDir = path
+	}
+}
+
+// WithFile adds an explicit config file, read after every other scope with a
+// SourceTypeCustom source, like git config --file. Unlike the standard
+// scopes, a missing file is an error. WithFile may be given several times.
+func WithFile(path string) ConfigOption {
+	return func(opts *configOptions) {
+		opts.files = append(opts.files, path)
+	}
+}
+
+// WithBlobRef adds the config stored in a git object of the repository,
+// such as "HEAD:.gitconfig", read after the files given with WithFile. See
+// GetBlobConfig.
+func WithBlobRef(ref string) ConfigOption {
+	return func(opts *configOptions) {
+		opts.blobRefs = append(opts.blobRefs, ref)
+	}
+}
+
 func WithGitCommand() ConfigOption {
 	return func(opts *configOptions) {
 		opts.useGitCommand = true
 	}
 }
 
+// WithGitBinary runs the git executable at path, or found on PATH under that
+// name, instead of "git". Load fails with ErrGitNotFound if it does not exist.
+func WithGitBinary(path string) ConfigOption {
+	return func(opts *configOptions) {
+		opts.gitBinary = path
+	}
+}
+
+// WithGitEnv sets the environment of the git subprocess. A nil env selects a
+// sanitized environment: HOME, PATH, XDG_CONFIG_HOME and the GIT_* variables
+// of this process, minus GIT_CONFIG_PARAMETERS, GIT_CONFIG_COUNT and
+// GIT_CONFIG_KEY_*/GIT_CONFIG_VALUE_*, which would inject config values.
+// Without this option git inherits the full environment.
+func WithGitEnv(env []string) ConfigOption {
+	return func(opts *configOptions) {
+		opts.gitEnv = env
+		opts.gitEnvSet = true
+	}
+}
+
+// WithSkipUnreadable makes Load skip a discovered system, global, local or
+// worktree config that cannot be opened because it is missing or permission
+// is denied, rather than failing. The skipped file is left out of GetSources
+// and reported by Warnings. Files given with WithFile must still be readable.
+func WithSkipUnreadable() ConfigOption {
+	return func(opts *configOptions) {
+		opts.skipUnreadable = true
+	}
+}
+
+// WithMaxLineLength sets the longest line, in bytes, accepted in a config
+// file. Longer lines fail the load with an error wrapping bufio.ErrTooLong.
+// The default is DefaultMaxLineLength.
+func WithMaxLineLength(n int) ConfigOption {
+	return func(opts *configOptions) {
+		opts.maxLineLength = n
+	}
+}
+
+// WithLimits bounds the size of the config files a load accepts; see Limits.
+// Without it DefaultLimits apply. Pass NoLimits to turn them off.
+func WithLimits(limits Limits) ConfigOption {
+	return func(opts *configOptions) {
+		opts.limits = limits
+	}
+}
+
+// WithStrictMode makes Load fail with an error wrapping ErrUnknownKey on the
+// first key that is not known, for tools that must not silently ignore
+// unexpected settings. The known keys are those in this package plus any
+// added with RegisterKnownKey. Like Limits, it applies to files read
+// directly, not to WithGitCommand. In strict mode Load reads every source
+// before failing and joins the errors of all broken files with errors.Join.
+func WithStrictMode() ConfigOption {
+	return func(opts *configOptions) {
+		opts.strict = true
+	}
+}
+
+// WithPreserveComments keeps the comment lines of the files read, available
+// from Comments and written back by MarshalText and WriteTo. Each comment is
+// tied to the section or key that follows it. Comments are not available
+// with WithGitCommand, since git config does not report them.
+func WithPreserveComments() ConfigOption {
+	return func(opts *configOptions) {
+		opts.comments = true
+	}
+}
+
+// WithLogger makes Load and Reload log diagnostics to logger at Debug level:
+// every source discovered, skipped or not found for a selected scope, the
+// includes git followed with WithGitCommand, the time spent on each source,
+// and the lines the parser skipped and why.
+func WithLogger(logger *slog.Logger) ConfigOption {
+	return func(opts *configOptions) {
+		opts.logger = logger
+	}
+}
+
 func WithTimeout(timeout time.Duration) ConfigOption {
 	return func(opts *configOptions) {
 		opts.timeout = timeout
 	}
 }
 
+// WithDeadline makes Load give up at t, like a context deadline. It can be
+// combined with WithTimeout, which bounds each git invocation; whichever
+// expires first wins.
+func WithDeadline(t time.Time) ConfigOption {
+	return func(opts *configOptions) {
+		opts.deadline = t
+	}
+}
+
+// WithNoTimeout removes the DefaultTimeout on git invocations, so that only
+// the deadline of the context passed to LoadWithContext, if any, applies.
+func WithNoTimeout() ConfigOption {
+	return func(opts *configOptions) {
+		opts.timeout = 0
+	}
+}
+
+// WithDefaults supplies fallback values for keys that no configuration source
+// defines. Defaults have the lowest precedence, are reported with a
+// SourceTypeDefault source and are validated when the config is loaded.
+func WithDefaults(defaults map[string]string) ConfigOption {
+	return func(opts *configOptions) {
+		if opts.defaults == nil {
+			opts.defaults = make(map[string]string, len(defaults))
+		}
+		for key, value := range defaults {
+			opts.defaults[key] = value
+		}
+	}
+}
+
+// WithRedaction makes String mask credentials the way Redacted does. The
+// given glob patterns are marked sensitive in addition to
+// DefaultSensitiveKeys, e.g. WithRedaction("github.*", "*.apikey").
+func WithRedaction(sensitiveKeys ...string) ConfigOption {
+	return func(opts *configOptions) {
+		opts.redact = true
+		opts.sensitiveKeys = append(opts.sensitiveKeys, sensitiveKeys...)
+	}
+}
+
+// WithEnv replaces os.LookupEnv for accessors that consult environment
+// variables, such as GetEffectiveUser. It is mainly useful in tests.
+func WithEnv(lookup func(string) (string, bool)) ConfigOption {
+	return func(opts *configOptions) {
+		opts.lookupEnv = lookup
+	}
+}
+
+// WithLazyLoad defers all file and git command I/O until the config is first
+// accessed or EnsureLoaded is called. Errors from the deferred load are
+// returned by EnsureLoaded and by the first accessors that can report them.
+// The deferred load is not bound to the context passed to LoadWithContext.
+func WithLazyLoad() ConfigOption {
+	return func(opts *configOptions) {
+		opts.lazyLoad = true
+	}
+}
+
 func Load(opts ...ConfigOption) (*Config, error) {
 	return LoadWithContext(context.Background(), opts...)
 }
@@ -76,7 +279,70 @@ func LoadWithContext(ctx context.Context, opts ...ConfigOption) (*Config, error)
 		opt(options)
 	}
 
-	if (options.includeLocal || options.includeWorktree) && options.repoPath != "" {
+	for key := range options.defaults {
+		if !isValidConfigKey(key) {
+			return nil, &ConfigError{
+				Op:  "load",
+				Key: key,
+				Err: fmt.Errorf("invalid default: %w", ErrInvalidKeyFormat),
+			}
+		}
+	}
+
+	if options.gitBinary != "" {
+		if _, err := exec.LookPath(options.gitBinary); err != nil {
+			return nil, &ConfigError{
+				Op:  "load",
+				Err: fmt.Errorf("%w: %w", ErrGitNotFound, err),
+			}
+		}
+	}
+
+	var redaction *redactor
+	if options.redact {
+		var err error
+		patterns := append(append([]string(nil), DefaultSensitiveKeys...), options.sensitiveKeys...)
+		if redaction, err = newRedactor(patterns); err != nil {
+			return nil, &ConfigError{Op: "load", Err: err}
+		}
+	}
+
+	if options.lazyLoad {
+		return &Config{
+			sections:  make(map[string]map[string]string),
+			sources:   make([]ConfigSource, 0),
+			redaction: redaction,
+			lookupEnv: options.lookupEnv,
+			lazy:      &lazyState{opts: options},
+		}, nil
+	}
+
+	config, err := loadConfig(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	config.redaction = redaction
+	config.lookupEnv = options.lookupEnv
+
+	return config, nil
+}
+
+// loadConfig performs the file or git command I/O for a set of options.
+func loadConfig(ctx context.Context, options *configOptions) (*Config, error) {
+	if !options.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, options.deadline)
+		defer cancel()
+	}
+
+	if (options.includeLocal || options.includeWorktree) && options.gitDir != "" {
+		if err := validateGitDir(options.gitDir); err != nil {
+			return nil, &ConfigError{
+				Op:  "load",
+				Err: fmt.Errorf("invalid git directory: %w", err),
+			}
+		}
+	} else if (options.includeLocal || options.includeWorktree) && options.repoPath != "" {
 		if err := validateRepoPath(options.repoPath); err != nil {
 			return nil, &ConfigError{
 				Op:  "load",
@@ -86,11 +352,33 @@ func LoadWithContext(ctx context.Context, opts ...ConfigOption) (*Config, error)
 	}
 
 	parser := newParser()
+	parser.maxLineLength = options.maxLineLength
+	parser.limits = options.limits
+	parser.strict = options.strict
+	parser.logger = options.logger
+	parser.comments = options.comments
+
+	var config *Config
+	var err error
 	if options.useGitCommand {
-		return parser.parseFromGitCommand(ctx, options)
+		config, err = parser.parseFromGitCommand(ctx, options)
+	} else {
+		config, err = parser.parseFromFiles(ctx, options)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return parser.parseFromFiles(ctx, options)
+	if err := config.applyDefaults(options.defaults); err != nil {
+		return nil, err
+	}
+	config.maxLineLength = options.maxLineLength
+	config.limits = options.limits
+	config.strict = options.strict
+	config.logger = options.logger
+	config.load = options
+
+	return config, nil
 }
 
 func LoadGlobal() (*Config, error) {
@@ -105,6 +393,13 @@ func LoadAll(repoPath string) (*Config, error) {
 	return Load(WithSystem(), WithGlobal(), WithLocal(), WithWorktree(), WithRepoPath(repoPath))
 }
 
+// LoadFromGitDir loads the global configuration together with the config and
+// config.worktree files of gitDir, which is the git directory itself rather
+// than a repository root.
+func LoadFromGitDir(gitDir string) (*Config, error) {
+	return Load(WithLocal(), WithWorktree(), WithGitDir(gitDir))
+}
+
 func LoadGlobalWithContext(ctx context.Context) (*Config, error) {
 	return LoadWithContext(ctx, WithGlobal())
 }
@@ -116,3 +411,64 @@ func LoadLocalWithContext(ctx context.Context, repoPath string) (*Config, error)
 func LoadAllWithContext(ctx context.Context, repoPath string) (*Config, error) {
 	return LoadWithContext(ctx, WithSystem(), WithGlobal(), WithLocal(), WithWorktree(), WithRepoPath(repoPath))
 }
+
+// gitCommand builds a git invocation honoring WithGitBinary and WithGitEnv.
+// opts may be nil.
+func (opts *configOptions) gitCommand(ctx context.Context, args ...string) *exec.Cmd {
+	binary := "git"
+	if opts != nil && opts.gitBinary != "" {
+		binary = opts.gitBinary
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	if opts != nil && opts.gitEnvSet {
+		cmd.Env = opts.gitEnv
+		if cmd.Env == nil {
+			cmd.Env = sanitizedGitEnv(os.Environ())
+		}
+	}
+	return cmd
+}
+
+// sanitizedGitEnv keeps the variables git needs to locate its config files,
+// dropping those that inject config values.
+func sanitizedGitEnv(environ []string) []string {
+	env := make([]string, 0)
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if name == "HOME" || name == "PATH" || name == "XDG_CONFIG_HOME" ||
+			(strings.HasPrefix(name, "GIT_") && !injectsGitConfig(name)) {
+			env = append(env, entry)
+		}
+	}
+	return env
+}
+
+func injectsGitConfig(name string) bool {
+	return name == "GIT_CONFIG_PARAMETERS" || name == "GIT_CONFIG_COUNT" ||
+		strings.HasPrefix(name, "GIT_CONFIG_KEY_") || strings.HasPrefix(name, "GIT_CONFIG_VALUE_")
+}
+
+// ParseFromReader parses git config data from r without touching the
+// filesystem. The result has no sources, so WhichFile reports ErrNoSource and
+// Reload is a no-op.
+func ParseFromReader(r io.Reader) (*Config, error) {
+	config := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0),
+	}
+	if err := newParser().parseConfigReader(r, config, ConfigSource{}); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ParseFromString parses git config data held in a string.
+func ParseFromString(s string) (*Config, error) {
+	return ParseFromReader(strings.NewReader(s))
+}
+
+// ParseBytes parses git config data held in a byte slice.
+func ParseBytes(data []byte) (*Config, error) {
+	return ParseFromReader(bytes.NewReader(data))
+}
@@ -0,0 +1,256 @@
+package gitcfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// OutputFormat selects how PrintTo serializes a Config.
+type OutputFormat int
+
+const (
+	// OutputFormatINI is the git config file format, readable by git and by
+	// ParseFromReader.
+	OutputFormatINI OutputFormat = iota
+	// OutputFormatJSON is the indented form of MarshalJSON.
+	OutputFormatJSON
+	// OutputFormatFlat prints one key=value line per value, like
+	// git config --list.
+	OutputFormatFlat
+	// OutputFormatTable prints aligned KEY, VALUE and ORIGIN columns for
+	// terminal display.
+	OutputFormatTable
+)
+
+func (f OutputFormat) String() string {
+	switch f {
+	case OutputFormatINI:
+		return "ini"
+	case OutputFormatJSON:
+		return "json"
+	case OutputFormatFlat:
+		return "flat"
+	case OutputFormatTable:
+		return "table"
+	default:
+		return "unknown"
+	}
+}
+
+// printEntry is one key with all of its values, ready for output.
+type printEntry struct {
+	section string
+	key     string
+	values  []configValue
+}
+
+// PrintTo writes the configuration to w in the given format. Sections and
+// keys are sorted as by GetSortedKeys, multi-valued keys are written once per value, and configs
+// loaded with WithRedaction are masked as by Redacted.
+func (c *Config) PrintTo(w io.Writer, format OutputFormat) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	entries := c.printEntries()
+
+	var err error
+	switch format {
+	case OutputFormatINI:
+		err = writeINI(w, entries, nil)
+	case OutputFormatJSON:
+		err = c.writeJSON(w, entries)
+	case OutputFormatFlat:
+		err = writeFlat(w, entries)
+	case OutputFormatTable:
+		err = writeTable(w, entries)
+	default:
+		return &ConfigError{
+			Op:  "print",
+			Err: fmt.Errorf("%w: unknown output format %d", ErrInvalidValue, int(format)),
+		}
+	}
+
+	if err != nil {
+		return &ConfigError{Op: "print", Err: err}
+	}
+	return nil
+}
+
+// printEntries returns every key in GetSortedKeys order, with redaction
+// applied.
+func (c *Config) printEntries() []printEntry {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	return c.entries(c.redaction)
+}
+
+// entries returns every key in GetSortedKeys order, masked by r when it is
+// non-nil. Callers must hold the lock.
+func (c *Config) entries(r *redactor) []printEntry {
+	var entries []printEntry
+	for _, section := range c.sortedSections() {
+		name := section
+		if r != nil {
+			name = r.section(section)
+		}
+
+		for _, key := range sortedSectionKeys(c.sections[section]) {
+			values := append([]configValue(nil), c.rawValues(section, key)...)
+			if r != nil {
+				for i := range values {
+					if masked := r.value(section, key, values[i].value); masked != values[i].value {
+						values[i].value, values[i].bare = masked, false
+					}
+				}
+			}
+			entries = append(entries, printEntry{section: name, key: key, values: values})
+		}
+	}
+
+	return entries
+}
+
+// writeINI writes entries in git config format. Each comment is written
+// before the section header or key it preceded when read; comments whose
+// section or key is gone, and those that ended a file, are written last.
+func writeINI(w io.Writer, entries []printEntry, comments []CommentLine) error {
+	var sb strings.Builder
+
+	written := make(map[string]bool)
+	writeComments := func(before, indent string) {
+		if len(comments) == 0 {
+			return
+		}
+		written[before] = true
+		for _, comment := range comments {
+			if comment.Before == before {
+				sb.WriteString(indent)
+				sb.WriteString(comment.Text)
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	current := ""
+	for i, entry := range entries {
+		if i == 0 || entry.section != current {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			current = entry.section
+			writeComments(entry.section, "")
+			sb.WriteString(iniSectionHeader(entry.section))
+			sb.WriteString("\n")
+		}
+		writeComments(entry.section+"."+entry.key, "\t")
+		for _, v := range entry.values {
+			if v.bare {
+				// "key =" would read back as an empty string, which is false
+				fmt.Fprintf(&sb, "\t%s\n", entry.key)
+				continue
+			}
+			fmt.Fprintf(&sb, "\t%s = %s\n", entry.key, iniValue(v.value))
+		}
+	}
+
+	trailing := false
+	for _, comment := range comments {
+		if !written[comment.Before] {
+			if !trailing && len(entries) > 0 {
+				sb.WriteString("\n")
+			}
+			trailing = true
+			sb.WriteString(comment.Text)
+			sb.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// iniSectionHeader turns the dotted form back into [section "subsection"].
+func iniSectionHeader(section string) string {
+	name, subsection, found := strings.Cut(section, ".")
+	if !found {
+		return "[" + name + "]"
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(subsection)
+	return fmt.Sprintf("[%s \"%s\"]", name, escaped)
+}
+
+// iniValue quotes a value when git would otherwise strip or misread part of
+// it, using only the escapes git understands.
+func iniValue(value string) string {
+	if value == "" || (value == strings.TrimSpace(value) && !strings.ContainsAny(value, "\"\\#;\n\t")) {
+		return value
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(value)
+	return `"` + escaped + `"`
+}
+
+func writeFlat(w io.Writer, entries []printEntry) error {
+	var sb strings.Builder
+	for _, entry := range entries {
+		for _, v := range entry.values {
+			if v.bare {
+				fmt.Fprintf(&sb, "%s.%s\n", entry.section, entry.key)
+				continue
+			}
+			fmt.Fprintf(&sb, "%s.%s=%s\n", entry.section, entry.key, v.value)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeTable(w io.Writer, entries []printEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tORIGIN")
+
+	escape := strings.NewReplacer("\n", `\n`, "\t", `\t`)
+	for _, entry := range entries {
+		for _, v := range entry.values {
+			origin := "-"
+			if v.source != nil && v.source.Path != "" {
+				origin = fmt.Sprintf("%s:%s", v.source.Type, v.source.Path)
+			}
+			fmt.Fprintf(tw, "%s.%s\t%s\t%s\n", entry.section, entry.key, escape.Replace(v.value), origin)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func (c *Config) writeJSON(w io.Writer, entries []printEntry) error {
+	printable := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  c.GetSources(),
+	}
+	for _, entry := range entries {
+		printable.putValues(entry.section, entry.key, entry.values)
+	}
+
+	data, err := printable.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -2,32 +2,46 @@ package gitcfg
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
+	"math"
 	"os"
 	"os/exec"
-	"regexp"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
 type parser struct {
-	sectionRegex      *regexp.Regexp
-	keyValueRegex     *regexp.Regexp
-	commentRegex      *regexp.Regexp
-	continuationRegex *regexp.Regexp
+	noShowScope   bool                  // git predates --show-scope (2.26)
+	maxLineLength int                   // longest line accepted in a config file; 0 means DefaultMaxLineLength
+	limits        Limits                // set by WithLimits; zero fields mean DefaultLimits
+	strict        bool                  // set by WithStrictMode; reject keys isKnownKey does not accept
+	logger        *slog.Logger          // set by WithLogger; nil disables diagnostics
+	values        int                   // values stored so far in this load, checked against Limits.MaxKeys
+	bytes         int64                 // bytes of config text read so far in this load
+	comments      bool                  // set by WithPreserveComments; record comment lines
+	keyNames      map[string]parsedName // key names as written, lowercased and validated once
+	sectionNames  map[string]parsedName // section headers as written, interned in their dotted form
+}
+
+// parsedName is a key or section name from a config file in its stored form.
+type parsedName struct {
+	raw   string // the name as written
+	name  string
+	valid bool // a plain name that can be stored without further checks
 }
 
 func newParser() *parser {
-	return &parser{
-		sectionRegex:      regexp.MustCompile(`^\s*\[([^\]]+)\]\s*$`),
-		keyValueRegex:     regexp.MustCompile(`^\s*([^=\s]+)\s*=\s*(.*)$`),
-		commentRegex:      regexp.MustCompile(`^\s*[#;]`),
-		continuationRegex: regexp.MustCompile(`^\s+(.*)$`),
-	}
+	return &parser{}
 }
 
 func (p *parser) parseFromGitCommand(ctx context.Context, opts *configOptions) (*Config, error) {
@@ -42,34 +56,124 @@ func (p *parser) parseFromGitCommand(ctx context.Context, opts *configOptions) (
 		defer cancel()
 	}
 
-	args := []string{"config", "--list", "--null", "--show-origin"}
+	// git config reads one scope per invocation, so each is listed separately
+	// and the results are merged in precedence order.
+	seen := make(map[string]bool)
+	for _, scope := range p.buildSourceFlags(opts) {
+		start := time.Now()
+		output, warnings, err := p.runGitConfig(ctx, opts, scope.flags)
+		if err != nil {
+			var missing *missingScopeError
+			if errors.As(err, &missing) && scope.sourceType != SourceTypeCustom {
+				// An absent system, global or worktree file is not an error,
+				// matching file mode
+				p.debug("config source not found", "type", scope.sourceType)
+				continue
+			}
+			if opts.skipUnreadable && scope.sourceType != SourceTypeCustom && errors.Is(err, fs.ErrPermission) {
+				p.debug("config source skipped", "type", scope.sourceType, "error", err)
+				config.warnings = append(config.warnings, err)
+				continue
+			}
+			return nil, err
+		}
 
-	sourceFlags := p.buildSourceFlags(opts)
-	if len(sourceFlags) > 0 {
-		args = append(args, sourceFlags...)
+		listed := len(config.sources)
+		if err := p.parseGitConfigOutput(output, config, scope.sourceType, opts.repoPath, seen); err != nil {
+			return nil, err
+		}
+		for _, source := range config.sources[listed:] {
+			stat := SourceStat{Path: source.Path, Type: source.Type, ParseDuration: time.Since(start)}
+			if info, err := os.Stat(source.Path); err == nil {
+				stat.ByteSize, stat.ModTime = info.Size(), info.ModTime()
+			}
+			config.stats = append(config.stats, stat)
+		}
+		p.debug("config scope read", "type", scope.sourceType, "flags", strings.Join(scope.flags, " "), "duration", time.Since(start))
+		for _, line := range strings.Split(warnings, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				config.warnings = append(config.warnings, errors.New(line))
+			}
+		}
 	}
+	countSourceValues(config, config.stats)
+
+	return config, nil
+}
+
+// gitConfigScope is one git config invocation: the flags selecting the scope
+// and the source type its values are recorded with.
+type gitConfigScope struct {
+	flags      []string
+	sourceType ConfigSourceType
+}
+
+// missingScopeError reports that git could not read the file of a scope
+// because it does not exist.
+type missingScopeError struct {
+	stderr string
+}
+
+func (e *missingScopeError) Error() string {
+	return e.stderr
+}
 
-	cmd := exec.CommandContext(ctx, "git", args...)
+func (p *parser) runGitConfig(ctx context.Context, opts *configOptions, scopeFlags []string) (output, warnings string, err error) {
+	var args []string
 	if opts.repoPath != "" {
-		cmd.Dir = opts.repoPath
+		args = append(args, "-C", opts.repoPath)
+	}
+	if opts.gitDir != "" {
+		args = append(args, "--git-dir="+opts.gitDir)
 	}
+	args = append(args, "config", "--list", "--null", "--show-origin")
+	if !p.noShowScope {
+		args = append(args, "--show-scope")
+	}
+	args = append(args, scopeFlags...)
 
-	output, err := cmd.Output()
-	if err != nil {
-		var exitError *exec.ExitError
-		if errors.As(err, &exitError) {
-			return nil, &ConfigError{
-				Op:  "load",
-				Err: fmt.Errorf("git config failed: %s", string(exitError.Stderr)),
-			}
+	var stdout, stderr bytes.Buffer
+	cmd := opts.gitCommand(ctx, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if !p.noShowScope && strings.Contains(message, "show-scope") {
+			// Older git: scopes come from the invocation alone
+			p.noShowScope = true
+			return p.runGitConfig(ctx, opts, scopeFlags)
 		}
-		return nil, &ConfigError{
+		return "", "", &ConfigError{
 			Op:  "load",
-			Err: fmt.Errorf("failed to execute git config: %w", err),
+			Err: classifyGitError(ctx, err, message),
 		}
 	}
 
-	return p.parseGitConfigOutput(string(output), config)
+	return stdout.String(), stderr.String(), nil
+}
+
+// classifyGitError attaches a sentinel to a failed git invocation so callers
+// can tell common failures apart with errors.Is.
+func classifyGitError(ctx context.Context, err error, stderr string) error {
+	var exitError *exec.ExitError
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		return fmt.Errorf("%w: %w", ErrGitNotFound, err)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrGitTimeout, ctx.Err())
+	case !errors.As(err, &exitError):
+		return fmt.Errorf("failed to execute git config: %w", err)
+	case strings.Contains(stderr, "unable to read config file") && strings.Contains(stderr, "No such file or directory"):
+		return &missingScopeError{stderr: stderr}
+	case strings.Contains(stderr, "not a git repository"),
+		strings.Contains(stderr, "can only be used inside a git repository"):
+		return fmt.Errorf("%w: %s", ErrNotARepository, stderr)
+	case strings.Contains(stderr, "Permission denied"):
+		return fmt.Errorf("%w: %s", fs.ErrPermission, stderr)
+	default:
+		return fmt.Errorf("git config failed: %s", stderr)
+	}
 }
 
 func (p *parser) parseFromFiles(ctx context.Context, opts *configOptions) (*Config, error) {
@@ -78,155 +182,460 @@ func (p *parser) parseFromFiles(ctx context.Context, opts *configOptions) (*Conf
 		sources:  make([]ConfigSource, 0),
 	}
 
-	for _, source := range getAllConfigPaths(opts) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sources := getAllConfigPaths(ctx, opts)
+	if p.logger != nil {
+		p.logDiscovery(opts, sources)
+	}
+
+	// In strict mode every source is checked, so that all broken files are
+	// reported at once
+	var errs []error
+	for _, source := range sources {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
 		}
 
-		if err := p.parseConfigFile(source.Path, config); err != nil {
+		start := time.Now()
+		values, size := p.values, p.bytes
+		if err := p.parseConfigFile(source, config); err != nil {
+			if opts.skipUnreadable && source.Type != SourceTypeCustom &&
+				(errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist)) {
+				p.debug("config source skipped", "type", source.Type, "path", source.Path, "error", err)
+				config.warnings = append(config.warnings, err)
+				continue
+			}
+			if p.strict {
+				errs = append(errs, err)
+				continue
+			}
 			return nil, err
 		}
+		p.debug("config source parsed", "type", source.Type, "path", source.Path,
+			"values", p.values-values, "duration", time.Since(start))
 		config.sources = append(config.sources, source)
+		config.stats = append(config.stats, fileStat(source, p.values-values, p.bytes-size, start))
+	}
+	for _, ref := range opts.blobRefs {
+		if err := p.parseBlob(ctx, opts, ref, config); err != nil {
+			if p.strict {
+				errs = append(errs, err)
+				continue
+			}
+			return nil, err
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
 	return config, nil
 }
 
-func (p *parser) buildSourceFlags(opts *configOptions) []string {
-	var sourceFlags []string
+// buildSourceFlags returns the git config invocations for the selected
+// scopes, in the same order file mode reads them.
+func (p *parser) buildSourceFlags(opts *configOptions) []gitConfigScope {
+	var scopes []gitConfigScope
 
 	if opts.includeSystem {
-		sourceFlags = append(sourceFlags, "--system")
+		scopes = append(scopes, gitConfigScope{[]string{"--system"}, SourceTypeSystem})
 	}
 	if opts.includeGlobal {
-		sourceFlags = append(sourceFlags, "--global")
+		scopes = append(scopes, gitConfigScope{[]string{"--global"}, SourceTypeGlobal})
 	}
 	if opts.includeLocal {
-		sourceFlags = append(sourceFlags, "--local")
+		scopes = append(scopes, gitConfigScope{[]string{"--local"}, SourceTypeLocal})
+	}
+	if opts.includeWorktree {
+		scopes = append(scopes, gitConfigScope{[]string{"--worktree"}, SourceTypeWorktree})
+	}
+	for _, path := range opts.files {
+		// git resolves --file against -C, file mode against the working directory
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		scopes = append(scopes, gitConfigScope{[]string{"--file", path}, SourceTypeCustom})
+	}
+	for _, ref := range opts.blobRefs {
+		scopes = append(scopes, gitConfigScope{[]string{"--blob", ref}, SourceTypeCustom})
 	}
 
-	return sourceFlags
+	return scopes
 }
 
-func (p *parser) parseGitConfigOutput(output string, config *Config) (*Config, error) {
-	lines := strings.Split(strings.TrimRight(output, "\x00"), "\x00")
-	for _, line := range lines {
-		if line == "" {
+// gitOriginPrefixes are the origin types --show-origin prints before a key.
+var gitOriginPrefixes = []string{"file:", "blob:", "command line:", "standard input:"}
+
+// parseGitConfigOutput parses `git config --list --null --show-origin
+// [--show-scope]` output: an optional scope, an origin and a "key\nvalue"
+// record per value, each terminated by NUL. A key printed without a newline
+// has no value, which git reads as true. Values are recorded with the scope
+// git reports, or sourceType when it reports none; relative file origins are
+// resolved against repoPath.
+//
+// Files already listed in seen, e.g. the .git/config that --worktree falls
+// back to when extensions.worktreeConfig is off, are skipped so their values
+// are not recorded twice.
+func (p *parser) parseGitConfigOutput(output string, config *Config, sourceType ConfigSourceType, repoPath string, seen map[string]bool) error {
+	records := strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+
+	origins := make(map[string]*ConfigSource)
+	skipped := make(map[string]bool)
+	var origin string
+	scope := sourceType
+	for _, record := range records {
+		if record == "" {
 			continue
 		}
 
-		key, value, source := p.parseGitConfigLine(line)
-		if key != "" {
-			if err := config.setRawValue(key, value); err != nil {
-				return nil, &ConfigError{
-					Op:     "parse",
-					Key:    key,
-					Source: source,
-					Err:    err,
+		if scopeType, ok := parseGitScope(record); ok {
+			scope = scopeType
+			switch {
+			case scopeType == SourceTypeCustom:
+				// --file values are reported with the command scope
+				scope = sourceType
+			case scopeType == SourceTypeLocal && sourceType == SourceTypeWorktree:
+				// Some git versions report config.worktree as local; the
+				// .git/config that --worktree may fall back to is skipped
+				// below as already seen
+				scope = SourceTypeWorktree
+			}
+			continue
+		}
+
+		if path, ok := cutGitOrigin(record); ok {
+			if ref, isBlob := strings.CutPrefix(record, "blob:"); isBlob {
+				// A blob is named by its ref, which is not a path
+				if unquoted, err := strconv.Unquote(ref); err == nil {
+					ref = unquoted
 				}
+				origin = ref
+				continue
+			}
+			path = gitOriginPath(path)
+			if path != "" && !isAbsGitPath(path) && repoPath != "" {
+				path = filepath.Join(repoPath, path)
 			}
+			origin = path
+			continue
 		}
-	}
 
-	return config, nil
-}
+		if skipped[origin] || (origins[origin] == nil && seen[origin]) {
+			skipped[origin] = true
+			continue
+		}
+
+		source := origins[origin]
+		if source == nil {
+			source = &ConfigSource{Type: scope, Path: origin}
+			if len(origins) == 0 {
+				p.debug("config source discovered", "type", scope, "path", origin)
+			} else {
+				// Every file after the first of a scope was reached
+				// through include.path or includeIf
+				p.debug("config include followed", "type", scope, "path", origin)
+			}
+			origins[origin] = source
+			if origin != "" {
+				seen[origin] = true
+				config.sources = append(config.sources, *source)
+			}
+		}
 
-func (p *parser) parseGitConfigLine(line string) (key, value, source string) {
-	// Parse show-origin format: "file:path\tkey=value"
-	if strings.HasPrefix(line, "file:") {
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) == 2 {
-			source = strings.TrimPrefix(parts[0], "file:")
-			kvParts := strings.SplitN(parts[1], "=", 2)
-			if len(kvParts) == 2 {
-				key = strings.TrimSpace(kvParts[0])
-				value = kvParts[1]
+		// git --list -z ends a bare key with no newline and no value
+		key, value, found := strings.Cut(record, "\n")
+		if err := config.storeRawValue(key, configValue{value: value, source: source, bare: !found}, true); err != nil {
+			return &ConfigError{
+				Op:     "parse",
+				Key:    key,
+				Source: origin,
+				Err:    err,
 			}
 		}
-		return key, value, source
 	}
 
-	// Fallback for older git versions
-	parts := strings.SplitN(line, "=", 2)
-	if len(parts) == 2 {
-		key = strings.TrimSpace(parts[0])
-		value = parts[1]
+	return nil
+}
+
+// parseGitScope recognizes a --show-scope record. Scopes without a matching
+// source type, like command and unknown, map to SourceTypeCustom.
+func parseGitScope(record string) (ConfigSourceType, bool) {
+	switch record {
+	case "system":
+		return SourceTypeSystem, true
+	case "global":
+		return SourceTypeGlobal, true
+	case "local":
+		return SourceTypeLocal, true
+	case "worktree":
+		return SourceTypeWorktree, true
+	case "command", "unknown":
+		return SourceTypeCustom, true
+	default:
+		return 0, false
+	}
+}
+
+func cutGitOrigin(record string) (string, bool) {
+	if strings.Contains(record, "\n") {
+		return "", false
 	}
-	return key, value, source
+	for _, prefix := range gitOriginPrefixes {
+		if path, ok := strings.CutPrefix(record, prefix); ok {
+			return path, true
+		}
+	}
+	return "", false
 }
 
-func (p *parser) parseConfigFile(path string, config *Config) error {
-	file, err := os.Open(path)
+func (p *parser) parseConfigFile(source ConfigSource, config *Config) error {
+	file, err := os.Open(source.Path)
 	if err != nil {
 		return &ConfigError{
 			Op:     "parse",
-			Source: path,
+			Source: source.Path,
 			Err:    fmt.Errorf("failed to open config file: %w", err),
 		}
 	}
 	defer file.Close()
 
-	return p.parseConfigReader(file, config, path)
+	// Refuse an oversized regular file before reading any of it; other
+	// files are bounded while they are read
+	maxFileSize := p.limits.withDefaults().MaxFileSize
+	if info, err := file.Stat(); err == nil && info.Mode().IsRegular() && exceeds(info.Size(), maxFileSize) {
+		return &ConfigError{
+			Op:     "parse",
+			Source: source.Path,
+			Err:    limitError("MaxFileSize", maxFileSize),
+		}
+	}
+
+	return p.parseConfigReader(file, config, source)
 }
 
-func (p *parser) parseConfigReader(reader io.Reader, config *Config, source string) error {
-	scanner := bufio.NewScanner(reader)
-	var currentSection string
+func (p *parser) parseConfigReader(reader io.Reader, config *Config, source ConfigSource) error {
+	maxLineLength := p.maxLineLength
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+
+	limits := p.limits.withDefaults()
+	counted := &countingReader{r: reader}
+	if limits.MaxFileSize >= 0 {
+		// One byte past the limit is enough to tell that it was exceeded
+		counted.r = io.LimitReader(reader, limits.MaxFileSize+1)
+	}
+	defer func() { p.bytes += counted.n }()
+
+	scanner := bufio.NewScanner(counted)
+	// Leave room for the line terminator, which counts against the buffer
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength+2)
+	var section parsedName
+	var comments []string // comment lines waiting for the line they precede
 	lineNumber := 0
 
 	for scanner.Scan() {
 		lineNumber++
-		line := scanner.Text()
-
-		if line == "" || p.commentRegex.MatchString(line) {
-			continue
+		if exceeds(counted.n, limits.MaxFileSize) {
+			break
+		}
+		// Tolerate files saved by Windows editors: CRLF endings and a BOM
+		line := bytes.TrimSuffix(scanner.Bytes(), []byte("\r"))
+		if lineNumber == 1 {
+			line = bytes.TrimPrefix(line, []byte("\ufeff"))
 		}
 
-		if matches := p.sectionRegex.FindStringSubmatch(line); matches != nil {
-			currentSection = strings.TrimSpace(matches[1])
+		kind, name, rawValue := scanConfigLine(line)
+		switch kind {
+		case configLineSection:
+			section = p.sectionName(name)
+			comments = config.attachComments(comments, section.name)
+			continue
+		case configLineOther:
+			if p.comments && isCommentLine(line) {
+				comments = append(comments, string(bytes.TrimSpace(line)))
+			} else if p.logger != nil {
+				p.logSkippedLine(source, lineNumber, line)
+			}
 			continue
 		}
+		if len(comments) > 0 {
+			comments = config.attachComments(comments, section.name+"."+strings.ToLower(string(name)))
+		}
 
-		if matches := p.keyValueRegex.FindStringSubmatch(line); matches != nil {
-			key := strings.TrimSpace(matches[1])
-			value := strings.TrimSpace(matches[2])
-
-			if processedValue, err := p.processQuotedValue(value); err != nil {
-				return &ConfigError{
+		value, err := p.processQuotedValue(string(rawValue))
+		if err != nil {
+			return newParseError(ConfigError{
+				Op:     "parse",
+				Key:    string(name),
+				Source: source.Path,
+				Line:   lineNumber,
+				Column: column(line, rawValue),
+				Err:    fmt.Errorf("invalid quoted value: %w", err),
+			})
+		}
+		if p.strict {
+			if fullKey := p.buildFullKey(section.raw, string(name)); !isKnownKey(fullKey) {
+				return newParseError(ConfigError{
 					Op:     "parse",
-					Key:    key,
-					Source: fmt.Sprintf("%s:%d", source, lineNumber),
-					Err:    fmt.Errorf("invalid quoted value: %w", err),
-				}
-			} else {
-				value = processedValue
+					Key:    fullKey,
+					Source: source.Path,
+					Line:   lineNumber,
+					Column: column(line, name),
+					Err:    ErrUnknownKey,
+				})
 			}
+		}
+		if exceeds(len(value), limits.MaxValueLength) {
+			return newParseError(ConfigError{
+				Op:     "parse",
+				Key:    string(name),
+				Source: source.Path,
+				Line:   lineNumber,
+				Column: column(line, rawValue),
+				Err:    limitError("MaxValueLength", int64(limits.MaxValueLength)),
+			})
+		}
+		p.values++
+		if exceeds(p.values, limits.MaxKeys) {
+			return newParseError(ConfigError{
+				Op:     "parse",
+				Key:    string(name),
+				Source: source.Path,
+				Line:   lineNumber,
+				Column: column(line, name),
+				Err:    limitError("MaxKeys", int64(limits.MaxKeys)),
+			})
+		}
 
-			fullKey := p.buildFullKey(currentSection, key)
-			if err := config.setRawValue(fullKey, value); err != nil {
-				return &ConfigError{
+		if p.logger != nil && isIncludeKey(section.name, string(name)) {
+			p.debug("config include not followed", "source", source.Path, "line", lineNumber, "path", value)
+		}
+
+		parsed := configValue{value: value, source: &source, line: lineNumber, bare: kind == configLineBareKey}
+		if key := p.keyName(name); section.valid && key.valid {
+			config.appendParsedValue(section.name, key.name, parsed)
+		} else {
+			// Unusual keys take the general path, which validates them and
+			// reports the problem
+			fullKey := p.buildFullKey(section.raw, string(name))
+			if err := config.storeRawValue(fullKey, parsed, true); err != nil {
+				return newParseError(ConfigError{
 					Op:     "parse",
 					Key:    fullKey,
-					Source: fmt.Sprintf("%s:%d", source, lineNumber),
+					Source: source.Path,
+					Line:   lineNumber,
+					Column: column(line, name),
 					Err:    err,
-				}
+				})
 			}
 		}
+
+		// Sections only come into being with their first value
+		if exceeds(len(config.sections), limits.MaxSections) {
+			return newParseError(ConfigError{
+				Op:     "parse",
+				Source: source.Path,
+				Line:   lineNumber,
+				Err:    limitError("MaxSections", int64(limits.MaxSections)),
+			})
+		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return &ConfigError{
+	if exceeds(counted.n, limits.MaxFileSize) {
+		return newParseError(ConfigError{
 			Op:     "parse",
-			Source: source,
+			Source: source.Path,
+			Err:    limitError("MaxFileSize", limits.MaxFileSize),
+		})
+	}
+
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return newParseError(ConfigError{
+			Op:     "parse",
+			Source: source.Path,
+			Line:   lineNumber + 1,
+			Err:    fmt.Errorf("line longer than %d bytes: %w", maxLineLength, err),
+		})
+	} else if err != nil {
+		return newParseError(ConfigError{
+			Op:     "parse",
+			Source: source.Path,
 			Err:    fmt.Errorf("scanner error: %w", err),
-		}
+		})
 	}
+	config.attachComments(comments, "")
 
 	return nil
 }
 
+// debug logs a diagnostic when WithLogger is set.
+func (p *parser) debug(msg string, args ...any) {
+	if p.logger != nil {
+		p.logger.Debug(msg, args...)
+	}
+}
+
+// logDiscovery logs the config files found for the selected scopes and the
+// scopes for which none exists.
+func (p *parser) logDiscovery(opts *configOptions, sources []ConfigSource) {
+	found := make(map[ConfigSourceType]bool)
+	for _, source := range sources {
+		found[source.Type] = true
+		p.debug("config source discovered", "type", source.Type, "path", source.Path)
+	}
+
+	requested := []struct {
+		sourceType ConfigSourceType
+		included   bool
+	}{
+		{SourceTypeSystem, opts.includeSystem},
+		{SourceTypeGlobal, opts.includeGlobal},
+		{SourceTypeLocal, opts.includeLocal},
+		{SourceTypeWorktree, opts.includeWorktree},
+	}
+	for _, scope := range requested {
+		if scope.included && !found[scope.sourceType] {
+			p.debug("config source not found", "type", scope.sourceType)
+		}
+	}
+}
+
+// logSkippedLine logs a line the parser ignores because it is neither a
+// section header nor a key, leaving out blank lines and comments.
+func (p *parser) logSkippedLine(source ConfigSource, lineNumber int, line []byte) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == ';' {
+		return
+	}
+
+	reason := "not a section header or key"
+	if trimmed[0] == '[' {
+		reason = "malformed section header"
+	}
+	p.debug("config line skipped", "source", source.Path, "line", lineNumber, "reason", reason)
+}
+
+// isIncludeKey reports whether a key read from a file is an include the
+// file parser does not follow.
+func isIncludeKey(section, key string) bool {
+	if !strings.EqualFold(key, "path") {
+		return false
+	}
+	return section == "include" || strings.HasPrefix(section, "includeif.")
+}
+
+// column returns the 1-based byte column at which part, a non-empty
+// subslice of line, starts.
+func column(line, part []byte) int {
+	return cap(line) - cap(part) + 1
+}
+
 func (p *parser) processQuotedValue(value string) (string, error) {
 	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
 		return strconv.Unquote(value)
@@ -235,34 +644,174 @@ func (p *parser) processQuotedValue(value string) (string, error) {
 }
 
 func (p *parser) buildFullKey(section, key string) string {
+	key = strings.ToLower(key)
 	if section == "" {
 		return key
 	}
+	return headerSection(section) + "." + key
+}
 
-	// Handle subsections like remote "origin" -> remote.origin
-	if strings.Contains(section, " ") {
-		parts := strings.SplitN(section, " ", 2)
-		if len(parts) == 2 {
-			subsection := strings.TrimSpace(parts[1])
-			if len(subsection) >= 2 && subsection[0] == '"' && subsection[len(subsection)-1] == '"' {
-				return parts[0] + "." + subsection[1:len(subsection)-1] + "." + key
-			}
+// headerSection converts the text of a section header to the internal
+// dotted form, e.g. remote "origin" -> remote.origin.
+func headerSection(section string) string {
+	if name, subsection, found := strings.Cut(section, " "); found {
+		subsection = strings.TrimSpace(subsection)
+		if len(subsection) >= 2 && subsection[0] == '"' && subsection[len(subsection)-1] == '"' {
+			return strings.ToLower(name) + "." + unescapeSubsection(subsection[1:len(subsection)-1])
+		}
+	}
+
+	return strings.ToLower(section)
+}
+
+// keyName returns the stored form of a key name as written in a file. Names
+// are cached, so the handful of distinct keys in a config are converted and
+// validated only once. A key containing a dot is never valid here, since it
+// would move part of the name into the section.
+func (p *parser) keyName(raw []byte) parsedName {
+	if key, ok := p.keyNames[string(raw)]; ok {
+		return key
+	}
+
+	written := string(raw)
+	name := strings.ToLower(written)
+	key := parsedName{
+		raw:   written,
+		name:  name,
+		valid: !strings.Contains(name, ".") && isValidKeyName(name),
+	}
+	if p.keyNames == nil {
+		p.keyNames = make(map[string]parsedName)
+	}
+	p.keyNames[key.raw] = key
+	return key
+}
+
+// sectionName returns the dotted form of a section header. Headers are
+// interned, so a section repeated within or across files shares one string.
+func (p *parser) sectionName(raw []byte) parsedName {
+	if section, ok := p.sectionNames[string(raw)]; ok {
+		return section
+	}
+
+	header := string(raw)
+	name := headerSection(header)
+	section := parsedName{
+		raw:   header,
+		name:  name,
+		valid: isValidKeyName(name) || isValidSubsectionName(name),
+	}
+	if p.sectionNames == nil {
+		p.sectionNames = make(map[string]parsedName)
+	}
+	p.sectionNames[section.raw] = section
+	return section
+}
+
+type configLineKind int
+
+const (
+	configLineOther    configLineKind = iota // blank, comment or unrecognized
+	configLineSection                        // [section] or [section "subsection"]
+	configLineKeyValue                       // key = value
+	configLineBareKey                        // a key without "=", a boolean set to true
+)
+
+// scanConfigLine classifies one line of a config file without allocating.
+// For a section header name is the text between the brackets; for a key it
+// is the key as written and value the unprocessed value, both trimmed. A
+// header followed by anything but whitespace, as in "[core] x", is not
+// recognized.
+func scanConfigLine(line []byte) (kind configLineKind, name, value []byte) {
+	i := skipLineSpace(line, 0)
+	if i == len(line) {
+		return configLineOther, nil, nil
+	}
+
+	switch line[i] {
+	case '#', ';':
+		return configLineOther, nil, nil
+	case '[':
+		if end := bytes.IndexByte(line[i+1:], ']'); end > 0 && skipLineSpace(line, i+end+2) == len(line) {
+			return configLineSection, bytes.TrimSpace(line[i+1 : i+1+end]), nil
+		}
+	}
+
+	// key = value: the key runs up to the first whitespace or '='
+	k := i
+	for k < len(line) && !isLineSpace(line[k]) && line[k] != '=' {
+		k++
+	}
+	if k > i {
+		if eq := skipLineSpace(line, k); eq < len(line) && line[eq] == '=' {
+			return configLineKeyValue, bytes.TrimSpace(line[i:k]), bytes.TrimSpace(line[eq+1:])
+		}
+	}
+
+	// A key without "=" is a boolean set to true; it is stored with an empty
+	// value, as git config --list reports it, and marked bare so it is
+	// written back without one
+	if isASCIILetter(line[i]) {
+		n := i + 1
+		for n < len(line) && (isASCIILetter(line[n]) || ('0' <= line[n] && line[n] <= '9') || line[n] == '-') {
+			n++
+		}
+		if rest := skipLineSpace(line, n); rest == len(line) || line[rest] == '#' || line[rest] == ';' {
+			return configLineBareKey, line[i:n], nil
 		}
 	}
 
-	return section + "." + key
+	return configLineOther, nil, nil
 }
 
+// isLineSpace reports the whitespace that separates tokens within a line.
+func isLineSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\f' || b == '\r'
+}
+
+func skipLineSpace(line []byte, i int) int {
+	for i < len(line) && isLineSpace(line[i]) {
+		i++
+	}
+	return i
+}
+
+func isASCIILetter(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// unescapeSubsection resolves the \" and \\ escapes git allows inside quoted
+// subsection names; any other escaped character stands for itself.
+func unescapeSubsection(name string) string {
+	if !strings.Contains(name, `\`) {
+		return name
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+1 < len(name) {
+			i++
+		}
+		sb.WriteByte(name[i])
+	}
+	return sb.String()
+}
+
+// isValidConfigKey reports whether key has the form section.key or
+// section.subsection.key. Section and key names are restricted to letters,
+// digits, '-' and '_', while the subsection may contain any character except
+// newline and NUL, which allows URL subsections like url.https://host/.insteadof.
 func isValidConfigKey(key string) bool {
-	if key == "" || !strings.Contains(key, ".") {
+	section, keyName, err := parseConfigKey(key)
+	if err != nil {
 		return false
 	}
-	for _, r := range key {
-		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '_' {
-			return false
-		}
+
+	name, subsection, hasSubsection := strings.Cut(section, ".")
+	if !isValidKeyName(name) || !isValidKeyName(keyName) {
+		return false
 	}
-	return true
+	return !hasSubsection || isValidSubsection(subsection)
 }
 
 func isValidSectionName(name string) bool {
@@ -283,16 +832,15 @@ func isValidSectionName(name string) bool {
 	return isValidKeyName(name)
 }
 
+// isValidSubsectionName validates the internal dotted form of a section with
+// a subsection, e.g. remote.origin or url.https://github.com/.
 func isValidSubsectionName(name string) bool {
-	if name == "" || !strings.Contains(name, ".") {
-		return false
-	}
-	for _, part := range strings.Split(name, ".") {
-		if !isValidKeyName(part) {
-			return false
-		}
-	}
-	return true
+	section, subsection, found := strings.Cut(name, ".")
+	return found && isValidKeyName(section) && isValidSubsection(subsection)
+}
+
+func isValidSubsection(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "\n\x00")
 }
 
 func isValidKeyName(name string) bool {
@@ -325,6 +873,52 @@ func parseBool(value string) (bool, error) {
 	}
 }
 
+// parseDuration accepts Go duration strings ("1m30s") as well as plain
+// integers, which are interpreted as seconds like most git timeout settings.
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration value: %s", value)
+	}
+	return d, nil
+}
+
+// parseGitInt parses an integer the way git does, with an optional k, m or g
+// suffix scaling it by 1024, 1024^2 or 1024^3.
+func parseGitInt(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	digits := value
+
+	var scale int64 = 1
+	if value != "" {
+		switch value[len(value)-1] {
+		case 'k', 'K':
+			scale = 1 << 10
+		case 'm', 'M':
+			scale = 1 << 20
+		case 'g', 'G':
+			scale = 1 << 30
+		}
+		if scale != 1 {
+			digits = value[:len(value)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer value: %s", value)
+	}
+	if n > math.MaxInt64/scale || n < math.MinInt64/scale {
+		return 0, fmt.Errorf("integer value out of range: %s", value)
+	}
+	return n * scale, nil
+}
+
 func convertValue[T Constraint](value string) (T, error) {
 	var result any
 	var err error
@@ -396,8 +990,59 @@ func convertValue[T Constraint](value string) (T, error) {
 
 	if err != nil {
 		var zero T
-		return zero, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+		return zero, &ConversionError{
+			Value: value,
+			Type:  reflect.TypeFor[T]().String(),
+			Hint:  conversionHint[T](value),
+			Err:   fmt.Errorf("%w: %w", ErrInvalidValue, err),
+		}
 	}
 
 	return result.(T), nil
 }
+
+// conversionHint suggests GetBytes or GetDuration for a number that failed
+// to convert because it carries a size suffix or a duration unit.
+func conversionHint[T Constraint](value string) string {
+	switch any(*new(T)).(type) {
+	case string, bool:
+		return ""
+	}
+
+	value = strings.TrimSpace(value)
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		// A plain number that is out of range or not an integer
+		return ""
+	}
+	if _, err := parseGitInt(value); err == nil {
+		return "use GetBytes for sizes with a k, m or g suffix"
+	}
+	if _, err := time.ParseDuration(value); err == nil {
+		return "use GetDuration for durations"
+	}
+	return ""
+}
+
+// inferValueType names the most specific type value converts to: "int",
+// "bool", "float", "bytesize" (an integer with a k, m or g suffix),
+// "duration" or "string". Integers win over bool, so 0 and 1 count as ints,
+// while an empty value counts as a bool since git reads a bare key as true.
+func inferValueType(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if _, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return "int"
+	}
+	if _, err := parseBool(value); err == nil {
+		return "bool"
+	}
+	if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		return "float"
+	}
+	if _, err := parseGitInt(trimmed); err == nil {
+		return "bytesize"
+	}
+	if _, err := time.ParseDuration(trimmed); err == nil {
+		return "duration"
+	}
+	return "string"
+}
@@ -1,22 +1,27 @@
 package gitcfg
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 )
 
 func TestNewParser(t *testing.T) {
-	parser := newParser()
-	if parser == nil {
+	if newParser() == nil {
 		t.Fatal("Parser is nil")
 	}
-	if parser.sectionRegex == nil {
-		t.Error("Section regex is nil")
-	}
-	if parser.keyValueRegex == nil {
-		t.Error("Key-value regex is nil")
-	}
 }
 
 func TestParseConfigReader(t *testing.T) {
@@ -35,7 +40,7 @@ func TestParseConfigReader(t *testing.T) {
 `
 
 	reader := strings.NewReader(configData)
-	err := parser.parseConfigReader(reader, config, "test")
+	err := parser.parseConfigReader(reader, config, ConfigSource{Path: "test"})
 	if err != nil {
 		t.Fatalf("parseConfigReader failed: %v", err)
 	}
@@ -51,34 +56,6 @@ func TestParseConfigReader(t *testing.T) {
 	}
 }
 
-func TestParseGitConfigLine(t *testing.T) {
-	parser := newParser()
-
-	tests := []struct {
-		line           string
-		expectedKey    string
-		expectedValue  string
-		expectedSource string
-	}{
-		{"file:/home/user/.gitconfig\tuser.name=Test User", "user.name", "Test User", "/home/user/.gitconfig"},
-		{"file:/etc/gitconfig\tcore.editor=vim", "core.editor", "vim", "/etc/gitconfig"},
-		{"user.email=test@example.com", "user.email", "test@example.com", ""},
-	}
-
-	for _, test := range tests {
-		key, value, source := parser.parseGitConfigLine(test.line)
-		if key != test.expectedKey {
-			t.Errorf("Expected key '%s', got '%s'", test.expectedKey, key)
-		}
-		if value != test.expectedValue {
-			t.Errorf("Expected value '%s', got '%s'", test.expectedValue, value)
-		}
-		if source != test.expectedSource {
-			t.Errorf("Expected source '%s', got '%s'", test.expectedSource, source)
-		}
-	}
-}
-
 func TestProcessQuotedValue(t *testing.T) {
 	parser := newParser()
 
@@ -320,7 +297,7 @@ func TestSubsectionParsing(t *testing.T) {
 `
 
 	reader := strings.NewReader(configData)
-	err := parser.parseConfigReader(reader, config, "test")
+	err := parser.parseConfigReader(reader, config, ConfigSource{Path: "test"})
 	if err != nil {
 		t.Fatalf("parseConfigReader failed: %v", err)
 	}
@@ -346,3 +323,674 @@ func TestSubsectionParsing(t *testing.T) {
 		t.Errorf("Expected branch main remote 'origin', got '%s'", config.sections["branch.main"]["remote"])
 	}
 }
+
+func TestParseConfigKey(t *testing.T) {
+	tests := []struct {
+		key             string
+		expectedSection string
+		expectedKey     string
+		hasError        bool
+	}{
+		{"user.name", "user", "name", false},
+		{"remote.origin.url", "remote.origin", "url", false},
+		{"url.https://github.com/.insteadOf", "url.https://github.com/", "insteadof", false},
+		{"url.git@github.com:.insteadOf", "url.git@github.com:", "insteadof", false},
+		{"http.https://example.com:8443/path/.sslVerify", "http.https://example.com:8443/path/", "sslverify", false},
+		{"url.ssh://git@host.example.co.uk/org/.pushInsteadOf", "url.ssh://git@host.example.co.uk/org/", "pushinsteadof", false},
+		{"Core.AutoCRLF", "core", "autocrlf", false},
+		{"Remote.Origin.URL", "remote.Origin", "url", false},
+		{"invalid", "", "", true},
+		{".name", "", "", true},
+		{"user.", "", "", true},
+		{"", "", "", true},
+	}
+
+	for _, test := range tests {
+		section, key, err := parseConfigKey(test.key)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("Expected error for key '%s'", test.key)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for key '%s': %v", test.key, err)
+			continue
+		}
+		if section != test.expectedSection || key != test.expectedKey {
+			t.Errorf("parseConfigKey(%q) = (%q, %q), expected (%q, %q)", test.key, section, key, test.expectedSection, test.expectedKey)
+		}
+	}
+}
+
+func TestIsValidConfigKeyURLSubsections(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"url.https://github.com/.insteadof", true},
+		{"url.git@github.com:org/.insteadof", true},
+		{"submodule.path/to/sub.url", true},
+		{"http.https://example.com:8443/.proxy", true},
+		{"url.https://github.com/.in stead", false},
+		{"bad section.key", false},
+		{"remote.line\nbreak.url", false},
+	}
+
+	for _, test := range tests {
+		result := isValidConfigKey(test.key)
+		if result != test.expected {
+			t.Errorf("Expected %v for key %q, got %v", test.expected, test.key, result)
+		}
+	}
+}
+
+func TestURLSubsectionParsing(t *testing.T) {
+	parser := newParser()
+	config := &Config{
+		sections: make(map[string]map[string]string),
+	}
+
+	configData := `[url "https://github.com/"]
+    insteadOf = gh:
+[url "git@github.com:"]
+    pushInsteadOf = https://github.com/
+[http "https://example.com:8443/path/"]
+    sslVerify = false
+[Core]
+    AutoCRLF = input
+[remote "with \"quotes\""]
+    url = https://example.com/quoted.git
+`
+
+	if err := parser.parseConfigReader(strings.NewReader(configData), config, ConfigSource{Path: "test"}); err != nil {
+		t.Fatalf("parseConfigReader failed: %v", err)
+	}
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"url.https://github.com/.insteadOf", "gh:"},
+		{"url.git@github.com:.pushinsteadof", "https://github.com/"},
+		{"http.https://example.com:8443/path/.sslverify", "false"},
+		{"core.autocrlf", "input"},
+		{`remote.with "quotes".url`, "https://example.com/quoted.git"},
+	}
+
+	for _, test := range tests {
+		value, err := Get[string](config, test.key)
+		if err != nil {
+			t.Errorf("Get(%q) failed: %v", test.key, err)
+			continue
+		}
+		if value != test.expected {
+			t.Errorf("Get(%q): expected '%s', got '%s'", test.key, test.expected, value)
+		}
+	}
+}
+
+func TestSplitConfigKey(t *testing.T) {
+	tests := []struct {
+		key        string
+		section    string
+		subsection string
+		keyName    string
+		hasError   bool
+	}{
+		{"user.name", "user", "", "name", false},
+		{"remote.origin.url", "remote", "origin", "url", false},
+		{"Remote.Origin.URL", "remote", "Origin", "url", false},
+		{"branch.feature/login.merge", "branch", "feature/login", "merge", false},
+		{"url.https://github.com/.insteadOf", "url", "https://github.com/", "insteadof", false},
+		{"http.https://example.com:8443/path/.sslVerify", "http", "https://example.com:8443/path/", "sslverify", false},
+		{"invalid", "", "", "", true},
+		{"user.", "", "", "", true},
+		{"us er.name", "", "", "", true},
+		{"", "", "", "", true},
+	}
+
+	for _, test := range tests {
+		section, subsection, keyName, err := SplitConfigKey(test.key)
+		if test.hasError {
+			if !errors.Is(err, ErrInvalidKeyFormat) {
+				t.Errorf("Expected ErrInvalidKeyFormat for %q, got %v", test.key, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.key, err)
+			continue
+		}
+		if section != test.section || subsection != test.subsection || keyName != test.keyName {
+			t.Errorf("SplitConfigKey(%q) = (%q, %q, %q), expected (%q, %q, %q)",
+				test.key, section, subsection, keyName, test.section, test.subsection, test.keyName)
+		}
+
+		joined, err := JoinConfigKey(section, subsection, keyName)
+		if err != nil {
+			t.Errorf("JoinConfigKey failed for %q: %v", test.key, err)
+		}
+		if !IsValidConfigKey(joined) {
+			t.Errorf("Expected joined key %q to be valid", joined)
+		}
+	}
+}
+
+func TestJoinConfigKey(t *testing.T) {
+	tests := []struct {
+		section    string
+		subsection string
+		key        string
+		expected   string
+		hasError   bool
+	}{
+		{"user", "", "name", "user.name", false},
+		{"Remote", "Origin", "URL", "remote.Origin.url", false},
+		{"url", "git@github.com:", "insteadOf", "url.git@github.com:.insteadof", false},
+		{"", "", "name", "", true},
+		{"user", "", "", "", true},
+		{"user", "", "first.name", "", true},
+		{"remote", "bad\nname", "url", "", true},
+	}
+
+	for _, test := range tests {
+		key, err := JoinConfigKey(test.section, test.subsection, test.key)
+		if test.hasError {
+			if !errors.Is(err, ErrInvalidKeyFormat) {
+				t.Errorf("Expected ErrInvalidKeyFormat for %q/%q/%q, got %v", test.section, test.subsection, test.key, err)
+			}
+			continue
+		}
+		if err != nil || key != test.expected {
+			t.Errorf("JoinConfigKey(%q, %q, %q) = %q, %v; expected %q", test.section, test.subsection, test.key, key, err, test.expected)
+		}
+	}
+}
+
+func TestParseConfigReaderCRLFAndBOM(t *testing.T) {
+	data := "\ufeff[core]\r\n\tautocrlf = true\r\n\tsymlinks\r\n[remote \"origin\"]\r\n\turl = \"https://example.com/repo.git\"\r\n"
+
+	config, err := ParseFromReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseFromReader failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"core.autocrlf":     "true",
+		"core.symlinks":     "",
+		"remote.origin.url": "https://example.com/repo.git",
+	}
+	for key, expected := range tests {
+		value, err := Get[string](config, key)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", key, err)
+		}
+		if value != expected {
+			t.Errorf("Get(%q) = %q, expected %q", key, value, expected)
+		}
+	}
+}
+
+func TestParseLongLines(t *testing.T) {
+	long := strings.Repeat("x", 1<<20)
+	data := "[alias]\n    st = status\n    long = !echo " + long + "\n[user]\n    name = Test\n"
+
+	config, err := ParseFromString(data)
+	if err != nil {
+		t.Fatalf("ParseFromString failed: %v", err)
+	}
+	if value, _ := Get[string](config, "alias.long"); value != "!echo "+long {
+		t.Errorf("Expected 1MB value, got %d bytes", len(value))
+	}
+	if name, _ := Get[string](config, "user.name"); name != "Test" {
+		t.Errorf("Expected parsing to continue past the long line, got %q", name)
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Load(WithFile(path), WithMaxLineLength(1024))
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("Expected bufio.ErrTooLong, got %v", err)
+	}
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Source != path || configErr.Line != 3 {
+		t.Errorf("Expected error to name %s line 3, got %v", path, err)
+	}
+
+	if _, err := Load(WithFile(path), WithMaxLineLength(2<<20)); err != nil {
+		t.Errorf("Expected line within limit to load, got %v", err)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("[user]\n    name = Test\n    email = \"bad \\q escape\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(WithFile(path))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got %T: %v", err, err)
+	}
+	if parseErr.FileName != path || parseErr.LineNumber != 3 {
+		t.Errorf("Expected %s line 3, got %s line %d", path, parseErr.FileName, parseErr.LineNumber)
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Op != "parse" || configErr.Line != 3 {
+		t.Errorf("Expected the ParseError to unwrap to its ConfigError, got %v", configErr)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected errors.Is to reach strconv.ErrSyntax, got %v", err)
+	}
+	if err.Error() != configErr.Error() {
+		t.Errorf("Expected the ConfigError message, got %q", err.Error())
+	}
+
+	_, err = ParseFromReader(strings.NewReader("[core]\n    bare = false\n[a b]\n    key = value\n"))
+	if !errors.As(err, &parseErr) || parseErr.LineNumber != 4 || !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Errorf("Expected an invalid key ParseError on line 4, got %v", err)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	data := "[core]\n    bare = false\n[remote \"origin\"]\n    url = https://example.com/repo.git\n    fetch = +refs/heads/*:refs/remotes/origin/*\n"
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		limits Limits
+		limit  string // empty when the file is within limits
+		line   int
+	}{
+		{"defaults", Limits{}, "", 0},
+		{"unlimited", NoLimits, "", 0},
+		{"file size", Limits{MaxFileSize: 32}, "MaxFileSize", 0},
+		{"keys", Limits{MaxKeys: 2}, "MaxKeys", 5},
+		{"sections", Limits{MaxSections: 1}, "MaxSections", 4},
+		{"value length", Limits{MaxValueLength: 20}, "MaxValueLength", 4},
+		{"exact", Limits{MaxFileSize: int64(len(data)), MaxKeys: 3, MaxSections: 2, MaxValueLength: 35}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newParser()
+			p.limits = tt.limits
+			config := &Config{sections: make(map[string]map[string]string)}
+			err := p.parseConfigFile(ConfigSource{Type: SourceTypeCustom, Path: path}, config)
+
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatalf("parseConfigFile() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("parseConfigFile() error = %v, want ErrLimitExceeded", err)
+			}
+			if !strings.Contains(err.Error(), tt.limit) {
+				t.Errorf("error %q does not name %s", err, tt.limit)
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) || configErr.Source != path || configErr.Line != tt.line {
+				t.Errorf("error %v, want %s line %d", err, path, tt.line)
+			}
+		})
+	}
+
+	t.Run("reader", func(t *testing.T) {
+		p := newParser()
+		p.limits = Limits{MaxFileSize: 32}
+		config := &Config{sections: make(map[string]map[string]string)}
+		if err := p.parseConfigReader(strings.NewReader(data), config, ConfigSource{}); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("parseConfigReader() error = %v, want ErrLimitExceeded", err)
+		}
+	})
+
+	t.Run("load", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("XDG_CONFIG_HOME", "")
+
+		config, err := Load(WithFile(path), WithLimits(Limits{MaxKeys: 3}))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data+"    prune = true\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.Reload(); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("Reload() error = %v, want ErrLimitExceeded", err)
+		}
+	})
+}
+
+func TestParseGitInt(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{"42", 42, false},
+		{"-3", -3, false},
+		{"1k", 1024, false},
+		{"2M", 2 << 20, false},
+		{"1g", 1 << 30, false},
+		{" 8 ", 8, false},
+		{"", 0, true},
+		{"k", 0, true},
+		{"1t", 0, true},
+		{"9999999999g", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := parseGitInt(test.value)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseGitInt(%q) error = %v, wantErr %v", test.value, err, test.wantErr)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("parseGitInt(%q) = %d, expected %d", test.value, got, test.expected)
+		}
+	}
+}
+
+// benchmarkConfig builds a config with the given number of remotes, each a
+// quoted subsection with comments, quoted values and a multi-valued key.
+func benchmarkConfig(remotes int) string {
+	var sb strings.Builder
+	sb.WriteString("# generated for benchmarks\n[core]\n\trepositoryformatversion = 0\n\tfilemode = true\n\tbare = false\n")
+	sb.WriteString("[user]\n\tname = \"Bench User\"\n\temail = bench@example.com\n")
+	for i := 0; i < remotes; i++ {
+		fmt.Fprintf(&sb, "; remote %d\n[remote \"r%d\"]\n", i, i)
+		fmt.Fprintf(&sb, "\turl = https://example.com/org/repo%d.git\n", i)
+		fmt.Fprintf(&sb, "\tfetch = +refs/heads/*:refs/remotes/r%d/*\n", i)
+		fmt.Fprintf(&sb, "\tfetch = +refs/tags/*:refs/tags/*\n")
+		fmt.Fprintf(&sb, "\tpushUrl = \"git@example.com:org/repo%d.git\"\n", i)
+		fmt.Fprintf(&sb, "\tprune\n")
+	}
+	return sb.String()
+}
+
+func benchmarkParse(b *testing.B, data string) {
+	lines := strings.Count(data, "\n")
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		config := &Config{
+			sections: make(map[string]map[string]string),
+			sources:  make([]ConfigSource, 0),
+		}
+		if err := newParser().parseConfigReader(strings.NewReader(data), config, ConfigSource{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(testing.AllocsPerRun(10, func() {
+		config := &Config{sections: make(map[string]map[string]string)}
+		_ = newParser().parseConfigReader(strings.NewReader(data), config, ConfigSource{})
+	}))/float64(lines), "allocs/line")
+}
+
+func BenchmarkParseSmall(b *testing.B) {
+	benchmarkParse(b, benchmarkConfig(3))
+}
+
+func BenchmarkParseLarge(b *testing.B) {
+	benchmarkParse(b, benchmarkConfig(2000))
+}
+
+var (
+	sectionRegex  = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*$`)
+	keyValueRegex = regexp.MustCompile(`^\s*([^=\s]+)\s*=\s*(.*)$`)
+	bareKeyRegex  = regexp.MustCompile(`^\s*([A-Za-z][-A-Za-z0-9]*)\s*(?:[#;].*)?$`)
+	commentRegex  = regexp.MustCompile(`^\s*[#;]`)
+)
+
+// regexpParseConfig is the regexp-based line parser that scanConfigLine
+// replaced, kept as the reference its results are compared against.
+func regexpParseConfig(data string) (*Config, error) {
+	p := newParser()
+	config := &Config{sections: make(map[string]map[string]string)}
+	source := ConfigSource{Path: "test"}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(nil, DefaultMaxLineLength)
+
+	var currentSection string
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if line == "" || commentRegex.MatchString(line) {
+			continue
+		}
+		if matches := sectionRegex.FindStringSubmatch(line); matches != nil {
+			currentSection = strings.TrimSpace(matches[1])
+			continue
+		}
+
+		matches := keyValueRegex.FindStringSubmatch(line)
+		bareKey := false
+		if matches == nil {
+			if bare := bareKeyRegex.FindStringSubmatch(line); bare != nil {
+				matches = []string{bare[0], bare[1], ""}
+				bareKey = true
+			}
+		}
+		if matches == nil {
+			continue
+		}
+
+		key := strings.TrimSpace(matches[1])
+		value, err := p.processQuotedValue(strings.TrimSpace(matches[2]))
+		if err != nil {
+			return nil, &ConfigError{
+				Op:     "parse",
+				Key:    key,
+				Source: source.Path,
+				Line:   lineNumber,
+				Err:    fmt.Errorf("invalid quoted value: %w", err),
+			}
+		}
+
+		fullKey := p.buildFullKey(currentSection, key)
+		if err := config.storeRawValue(fullKey, configValue{value: value, source: &source, line: lineNumber, bare: bareKey}, true); err != nil {
+			return nil, &ConfigError{
+				Op:     "parse",
+				Key:    fullKey,
+				Source: source.Path,
+				Line:   lineNumber,
+				Err:    err,
+			}
+		}
+	}
+	return config, nil
+}
+
+// compareWithRegexpParser checks that parseConfigReader and the reference
+// parser agree on data, including on errors.
+func compareWithRegexpParser(t testing.TB, data string) {
+	t.Helper()
+
+	want, wantErr := regexpParseConfig(data)
+
+	got := &Config{sections: make(map[string]map[string]string)}
+	gotErr := newParser().parseConfigReader(strings.NewReader(data), got, ConfigSource{Path: "test"})
+	var configErr *ConfigError
+	if errors.As(gotErr, &configErr) {
+		// The reference parser does not track columns
+		withoutColumn := *configErr
+		withoutColumn.Column = 0
+		gotErr = &withoutColumn
+	}
+
+	if (wantErr == nil) != (gotErr == nil) || (wantErr != nil && wantErr.Error() != gotErr.Error()) {
+		t.Fatalf("parsing %q: error %v, reference error %v", data, gotErr, wantErr)
+	}
+	if wantErr != nil {
+		return
+	}
+	if g, w := flattenValues(got), flattenValues(want); !reflect.DeepEqual(g, w) {
+		t.Fatalf("parsing %q:\ngot       %q\nreference %q", data, g, w)
+	}
+}
+
+func flattenValues(c *Config) []string {
+	var entries []string
+	for section, keys := range c.values {
+		for key, values := range keys {
+			for _, v := range values {
+				entries = append(entries, fmt.Sprintf("%s|%s|%s|%d|%t", section, key, v.value, v.line, v.bare))
+			}
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+func TestScannerMatchesRegexpParser(t *testing.T) {
+	tokens := []string{
+		"", " ", "\t", "\v", "\f", "\r", "\u00a0", "[", "]", `"`, `\`, "=", "#", ";", ".",
+		"-", "_", "core", "Remote", "origin", "url", "KEY", "x1", "é", "\xff", ` "sub"`,
+		`"a\"b"`, "value with spaces",
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		lines := make([]string, 1+rng.Intn(8))
+		for j := range lines {
+			var sb strings.Builder
+			for k := rng.Intn(8); k > 0; k-- {
+				sb.WriteString(tokens[rng.Intn(len(tokens))])
+			}
+			lines[j] = sb.String()
+		}
+		compareWithRegexpParser(t, strings.Join(lines, "\n"))
+	}
+
+	compareWithRegexpParser(t, benchmarkConfig(50))
+}
+
+func FuzzParseConfigReader(f *testing.F) {
+	f.Add("[core]\n\teditor = vim\n")
+	f.Add("[remote \"origin\"]\n\turl = \"https://example.com\" \n\tprune ; comment\n")
+	f.Add("\ufeff[Core] \r\n  Bare=  false  \r\n")
+	f.Add("[a.b \"c\\\"d\"]\nkey.with.dots = 1\n[] x\n= y\n[core] z = 1\n")
+	f.Add("key = before any section\n")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		compareWithRegexpParser(t, data)
+	})
+}
+
+func TestParseStrictMode(t *testing.T) {
+	RegisterKnownKey("example.*.token")
+
+	tests := []struct {
+		name    string
+		data    string
+		wantKey string // empty when the config is accepted
+	}{
+		{"known keys", "[core]\n    bare = false\n[remote \"origin\"]\n    url = https://example.com/repo.git\n[advice]\n    detachedHead = false\n", ""},
+		{"registered key", "[example \"ci\"]\n    token = secret\n", ""},
+		{"unknown key", "[user]\n    name = Test\n[foobar]\n    baz = 1\n", "foobar.baz"},
+		{"unknown subsection key", "[remote \"origin\"]\n    colour = blue\n", "remote.origin.colour"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newParser()
+			p.strict = true
+			config := &Config{sections: make(map[string]map[string]string)}
+			err := p.parseConfigReader(strings.NewReader(tt.data), config, ConfigSource{Path: "config"})
+
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Fatalf("parseConfigReader() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrUnknownKey) {
+				t.Fatalf("parseConfigReader() error = %v, want ErrUnknownKey", err)
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) || configErr.Key != tt.wantKey {
+				t.Errorf("error %v, want key %s", err, tt.wantKey)
+			}
+		})
+	}
+
+	if _, err := ParseFromString("[foobar]\n    baz = 1\n"); err != nil {
+		t.Errorf("ParseFromString() without strict mode error = %v", err)
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("[foobar]\n    baz = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(WithFile(path), WithStrictMode()); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Load() with WithStrictMode error = %v, want ErrUnknownKey", err)
+	}
+
+	// Every broken file is reported, not just the first
+	other := filepath.Join(t.TempDir(), "other")
+	if err := os.WriteFile(other, []byte("[user]\n    name = Test\n    nickname = T\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(WithFile(path), WithFile(other), WithStrictMode())
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("Load() with two broken files error = %v, want both reported", err)
+	}
+	var configErr *ConfigError
+	if !errors.As(joined.Unwrap()[1], &configErr) || configErr.Source != other || configErr.Line != 3 || configErr.Column != 5 {
+		t.Errorf("second error = %v, want %s:3:5", joined.Unwrap()[1], other)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	path := filepath.Join(t.TempDir(), "config")
+	data := "# comment\n[user]\n    name = Test\n[include]\n    path = extra.inc\n[broken\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := Load(WithFile(path), WithLogger(logger)); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	logged := buf.String()
+	for _, want := range []string{
+		`msg="config source not found" type=global`,
+		`msg="config source discovered" type=file path=` + path,
+		`msg="config source parsed" type=file path=` + path + ` values=2 duration=`,
+		`msg="config include not followed" source=` + path + ` line=5 path=extra.inc`,
+		`msg="config line skipped" source=` + path + ` line=6 reason="malformed section header"`,
+	} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log is missing %q:\n%s", want, logged)
+		}
+	}
+	if strings.Contains(logged, "line=1 ") {
+		t.Errorf("comment line was logged as skipped:\n%s", logged)
+	}
+}
@@ -1,11 +1,14 @@
 package gitcfg
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -33,23 +36,117 @@ func validateRepoPath(path string) error {
 
 	gitDir := filepath.Join(path, ".git")
 	if _, err := os.Stat(gitDir); err != nil {
-		return errors.New("not a Git repository")
+		return ErrNotARepository
 	}
 
 	return nil
 }
 
-func getSystemConfigPath() string {
+// validateGitDir checks that path is a git directory itself, such as a bare
+// repository or the target of GIT_DIR, rather than a working tree.
+func validateGitDir(path string) error {
+	if path == "" {
+		return errors.New("empty path")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("path does not exist: %w", err)
+	}
+
+	if !info.IsDir() {
+		return errors.New("path is not a directory")
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "HEAD")); err != nil {
+		return ErrNotARepository
+	}
+
+	return nil
+}
+
+// expandPath expands a leading ~ to the user's home directory and any
+// environment variables, and returns the absolute path.
+func expandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand %s: %w", path, err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	path = os.ExpandEnv(path)
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", path, err)
+	}
+	return abs, nil
+}
+
+// expandAndValidatePath is expandPath for paths that must exist.
+func expandAndValidatePath(raw string) (string, error) {
+	path, err := expandPath(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("cannot use %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// expandPathRelativeTo is expandPath, except that relative paths are
+// resolved against base instead of the working directory.
+func expandPathRelativeTo(path, base string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		if expanded := os.ExpandEnv(path); !filepath.IsAbs(expanded) {
+			path = filepath.Join(base, expanded)
+		}
+	}
+	return expandPath(path)
+}
+
+// xdgGitPath returns $XDG_CONFIG_HOME/git/<name>, or ~/.config/git/<name>
+// when xdgConfigHome is empty.
+func xdgGitPath(xdgConfigHome, name string) (string, error) {
+	if xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, "git", name), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate %s: %w", name, err)
+	}
+	return filepath.Join(home, ".config", "git", name), nil
+}
+
+func getSystemConfigPath(ctx context.Context, opts *configOptions) string {
 	// Try to get from git config --system --list first
-	if path := getSystemConfigPathFromGit(); path != "" {
+	if path := getSystemConfigPathFromGit(ctx, opts); path != "" {
 		return path
 	}
 
-	return getSystemConfigPathFallback()
+	return getSystemConfigPathFallback(opts)
 }
 
-func getSystemConfigPathFromGit() string {
-    cmd := exec.Command("git", "config", "--system", "--show-origin", "--list")
+// getSystemConfigPathFromGit asks git where its system config lives. It gives
+// up after the configured timeout or when ctx is done, leaving the caller to
+// fall back to the well-known locations.
+func getSystemConfigPathFromGit(ctx context.Context, opts *configOptions) string {
+	if ctx.Err() != nil {
+		return ""
+	}
+
+	if opts != nil && opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	cmd := opts.gitCommand(ctx, "config", "--system", "--show-origin", "--list")
 	output, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -64,20 +161,23 @@ func parseSystemConfigPath(output string) string {
 		if strings.HasPrefix(line, "file:") {
 			parts := strings.SplitN(line, "\t", 2)
 			if len(parts) > 0 {
-				return strings.TrimPrefix(parts[0], "file:")
+				return gitOriginPath(strings.TrimPrefix(parts[0], "file:"))
 			}
 		}
 	}
 	return ""
 }
 
-func getSystemConfigPathFallback() string {
-	paths := []string{
-		SystemConfigFile,
-		"/usr/local/etc/gitconfig",
+// getSystemConfigPathFallback returns the first system config that exists
+// among the well-known locations for this platform.
+func getSystemConfigPathFallback(opts *configOptions) string {
+	binary := "git"
+	if opts != nil && opts.gitBinary != "" {
+		binary = opts.gitBinary
 	}
+	gitPath, _ := exec.LookPath(binary)
 
-	for _, path := range paths {
+	for _, path := range systemConfigCandidates(runtime.GOOS, gitPath, os.Getenv("ProgramData")) {
 		if _, err := os.Stat(path); err == nil {
 			return path
 		}
@@ -86,6 +186,89 @@ func getSystemConfigPathFallback() string {
 	return ""
 }
 
+// systemConfigCandidates lists where the system config may live, most likely
+// first. On Windows, Git for Windows keeps it under its install root, found
+// from the git executable, and also reads %ProgramData%\Git\config.
+func systemConfigCandidates(goos, gitPath, programData string) []string {
+	if goos != "windows" {
+		return []string{
+			SystemConfigFile,
+			"/usr/local/etc/gitconfig",
+		}
+	}
+
+	var paths []string
+	if root := gitInstallRoot(gitPath); root != "" {
+		paths = append(paths,
+			root+`\etc\gitconfig`,
+			root+`\mingw64\etc\gitconfig`,
+		)
+	}
+	paths = append(paths, `C:\Program Files\Git\etc\gitconfig`)
+	if programData != "" {
+		paths = append(paths, strings.TrimRight(programData, `\/`)+`\Git\config`)
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if key := strings.ToLower(path); !seen[key] {
+			seen[key] = true
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
+
+// gitInstallRoot returns the Git for Windows install directory containing
+// gitPath, which is usually <root>\cmd\git.exe or <root>\bin\git.exe. It
+// works on Windows paths regardless of the host platform.
+func gitInstallRoot(gitPath string) string {
+	if gitPath == "" {
+		return ""
+	}
+
+	dir := windowsDir(gitPath)
+	for _, suffix := range []string{`\mingw64\bin`, `\mingw32\bin`, `\usr\bin`, `\cmd`, `\bin`} {
+		if len(dir) > len(suffix) && strings.EqualFold(strings.ReplaceAll(dir[len(dir)-len(suffix):], "/", `\`), suffix) {
+			return dir[:len(dir)-len(suffix)]
+		}
+	}
+	return ""
+}
+
+func windowsDir(path string) string {
+	if i := strings.LastIndexAny(path, `\/`); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// gitOriginPath turns an origin printed by git --show-origin into a path.
+// git quotes origins containing special characters and prints forward
+// slashes on Windows.
+func gitOriginPath(origin string) string {
+	if strings.HasPrefix(origin, `"`) {
+		if unquoted, err := strconv.Unquote(origin); err == nil {
+			origin = unquoted
+		}
+	}
+	return filepath.FromSlash(origin)
+}
+
+// isAbsGitPath is like filepath.IsAbs but also accepts Windows drive-letter
+// and UNC paths on any platform, since git may report either.
+func isAbsGitPath(path string) bool {
+	if filepath.IsAbs(path) {
+		return true
+	}
+	if strings.HasPrefix(path, `\\`) || strings.HasPrefix(path, "//") {
+		return true
+	}
+	return len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/') &&
+		(('a' <= path[0] && path[0] <= 'z') || ('A' <= path[0] && path[0] <= 'Z'))
+}
+
 func getGlobalConfigPath() string {
 	if path := getXDGConfigPath(); path != "" {
 		return path
@@ -157,11 +340,25 @@ func getWorktreeConfigPath(repoPath string) string {
 	return ""
 }
 
-func getAllConfigPaths(opts *configOptions) []ConfigSource {
+// getGitDirConfigPath returns <gitDir>/<name> if that file exists.
+func getGitDirConfigPath(gitDir, name string) string {
+	if gitDir == "" {
+		return ""
+	}
+
+	path := filepath.Join(gitDir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	return ""
+}
+
+func getAllConfigPaths(ctx context.Context, opts *configOptions) []ConfigSource {
 	var sources []ConfigSource
 
 	if opts.includeSystem {
-		if path := getSystemConfigPath(); path != "" {
+		if path := getSystemConfigPath(ctx, opts); path != "" {
 			sources = append(sources, ConfigSource{
 				Type: SourceTypeSystem,
 				Path: path,
@@ -178,7 +375,14 @@ func getAllConfigPaths(opts *configOptions) []ConfigSource {
 		}
 	}
 
-	if opts.includeLocal && opts.repoPath != "" {
+	if opts.includeLocal && opts.gitDir != "" {
+		if path := getGitDirConfigPath(opts.gitDir, "config"); path != "" {
+			sources = append(sources, ConfigSource{
+				Type: SourceTypeLocal,
+				Path: path,
+			})
+		}
+	} else if opts.includeLocal && opts.repoPath != "" {
 		if path := getLocalConfigPath(opts.repoPath); path != "" {
 			sources = append(sources, ConfigSource{
 				Type: SourceTypeLocal,
@@ -187,7 +391,14 @@ func getAllConfigPaths(opts *configOptions) []ConfigSource {
 		}
 	}
 
-	if opts.includeWorktree && opts.repoPath != "" {
+	if opts.includeWorktree && opts.gitDir != "" {
+		if path := getGitDirConfigPath(opts.gitDir, "config.worktree"); path != "" {
+			sources = append(sources, ConfigSource{
+				Type: SourceTypeWorktree,
+				Path: path,
+			})
+		}
+	} else if opts.includeWorktree && opts.repoPath != "" {
 		if path := getWorktreeConfigPath(opts.repoPath); path != "" {
 			sources = append(sources, ConfigSource{
 				Type: SourceTypeWorktree,
@@ -196,5 +407,12 @@ func getAllConfigPaths(opts *configOptions) []ConfigSource {
 		}
 	}
 
+	for _, path := range opts.files {
+		sources = append(sources, ConfigSource{
+			Type: SourceTypeCustom,
+			Path: path,
+		})
+	}
+
 	return sources
 }
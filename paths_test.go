@@ -1,9 +1,13 @@
 package gitcfg
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestValidateRepoPath(t *testing.T) {
@@ -41,7 +45,7 @@ func TestValidateRepoPath(t *testing.T) {
 }
 
 func TestGetSystemConfigPath(t *testing.T) {
-	path := getSystemConfigPath()
+	path := getSystemConfigPath(context.Background(), nil)
 	t.Logf("System config path: %s", path)
 }
 
@@ -125,7 +129,7 @@ func TestGetAllConfigPaths(t *testing.T) {
 		includeGlobal: true,
 	}
 
-	sources := getAllConfigPaths(opts)
+	sources := getAllConfigPaths(context.Background(), opts)
 	t.Logf("Found %d config sources", len(sources))
 
 	for _, source := range sources {
@@ -181,6 +185,8 @@ func TestParseSystemConfigPath(t *testing.T) {
 	}{
 		{"file:/etc/gitconfig\tcore.editor=vim", "/etc/gitconfig"},
 		{"file:/usr/local/etc/gitconfig\tuser.name=test", "/usr/local/etc/gitconfig"},
+		{"file:C:/Program Files/Git/etc/gitconfig\tcore.autocrlf=true", filepath.FromSlash("C:/Program Files/Git/etc/gitconfig")},
+		{`file:"C:\\ProgramData\\Git\\config"` + "\tcore.symlinks=false", `C:\ProgramData\Git\config`},
 		{"no file prefix", ""},
 		{"", ""},
 	}
@@ -194,6 +200,97 @@ func TestParseSystemConfigPath(t *testing.T) {
 }
 
 func TestGetSystemConfigPathFallback(t *testing.T) {
-	path := getSystemConfigPathFallback()
+	path := getSystemConfigPathFallback(nil)
 	t.Logf("System config fallback path: %s", path)
 }
+
+func TestSystemConfigDiscoveryHonorsContext(t *testing.T) {
+	// A git that never answers must not hold up Load
+	fakeGit(t, "exec sleep 10")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	_, err := LoadWithContext(ctx, WithSystem())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Load took %v with a cancelled context", elapsed)
+	}
+}
+
+func TestSystemConfigDiscoveryTimeoutFallsBack(t *testing.T) {
+	fakeGit(t, "exec sleep 10")
+
+	start := time.Now()
+	if _, err := Load(WithSystem(), WithTimeout(100*time.Millisecond)); err != nil {
+		t.Fatalf("Expected fallback after timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Load took %v despite a 100ms timeout", elapsed)
+	}
+}
+
+func TestSystemConfigCandidates(t *testing.T) {
+	unix := systemConfigCandidates("linux", "/usr/bin/git", "")
+	if !reflect.DeepEqual(unix, []string{SystemConfigFile, "/usr/local/etc/gitconfig"}) {
+		t.Errorf("Unexpected unix candidates: %v", unix)
+	}
+
+	tests := []struct {
+		gitPath     string
+		programData string
+		expected    []string
+	}{
+		{
+			`D:\Tools\Git\cmd\git.exe`, `C:\ProgramData`,
+			[]string{
+				`D:\Tools\Git\etc\gitconfig`,
+				`D:\Tools\Git\mingw64\etc\gitconfig`,
+				`C:\Program Files\Git\etc\gitconfig`,
+				`C:\ProgramData\Git\config`,
+			},
+		},
+		{
+			`C:\Program Files\Git\mingw64\bin\git.exe`, "",
+			[]string{
+				`C:\Program Files\Git\etc\gitconfig`,
+				`C:\Program Files\Git\mingw64\etc\gitconfig`,
+			},
+		},
+		{
+			"", `C:\ProgramData\`,
+			[]string{
+				`C:\Program Files\Git\etc\gitconfig`,
+				`C:\ProgramData\Git\config`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got := systemConfigCandidates("windows", test.gitPath, test.programData)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("systemConfigCandidates(%q, %q) = %v, expected %v", test.gitPath, test.programData, got, test.expected)
+		}
+	}
+}
+
+func TestIsAbsGitPath(t *testing.T) {
+	tests := map[string]bool{
+		"/etc/gitconfig":           true,
+		`C:\Users\me\.gitconfig`:   true,
+		"c:/Users/me/.gitconfig":   true,
+		`\\server\share\gitconfig`: true,
+		".git/config":              false,
+		`.git\config`:              false,
+		"C:relative":               false,
+	}
+
+	for path, expected := range tests {
+		if got := isAbsGitPath(path); got != expected {
+			t.Errorf("isAbsGitPath(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gitcfg
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// GetProxyConfig returns the proxy settings of git's HTTP transport. A set
+// http.proxy applies to both schemes; otherwise each scheme falls back to
+// the environment variables curl reads, in the order curl reads them.
+// URL-scoped http.<url>.proxy keys are resolved by GetEffectiveProxy.
+func (c *Config) GetProxyConfig() (*ProxyConfig, error) {
+	cfg := &ProxyConfig{}
+
+	r := &fieldReader{c: c}
+	var proxy string
+	readField(r, HTTPProxy, &proxy)
+	readField(r, HTTPProxyAuthMethod, &cfg.ProxyAuthMethod)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	cfg.HTTPProxy = proxy
+	if cfg.HTTPProxy == "" {
+		cfg.HTTPProxy = c.firstEnv("http_proxy", "all_proxy", "ALL_PROXY")
+	}
+	cfg.HTTPSProxy = proxy
+	if cfg.HTTPSProxy == "" {
+		cfg.HTTPSProxy = c.firstEnv("https_proxy", "HTTPS_PROXY", "all_proxy", "ALL_PROXY")
+	}
+	cfg.NoProxy = c.firstEnv("no_proxy", "NO_PROXY")
+	if method := c.firstEnv("GIT_HTTP_PROXY_AUTHMETHOD"); method != "" {
+		cfg.ProxyAuthMethod = method
+	}
+
+	return cfg, nil
+}
+
+// GetEffectiveProxy returns the proxy git would use to reach rawURL, or an
+// empty string for a direct connection. The most specific matching
+// http.<url>.proxy wins over http.proxy, which wins over the environment,
+// and a host excluded by no_proxy is always reached directly.
+func (c *Config) GetEffectiveProxy(rawURL string) (string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", &ConfigError{
+			Op:      "get",
+			Section: "http",
+			Err:     fmt.Errorf("invalid URL %q: %w", rawURL, ErrInvalidValue),
+		}
+	}
+
+	httpConfig, err := c.GetHTTPForURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := c.GetProxyConfig()
+	if err != nil {
+		return "", err
+	}
+
+	proxy := httpConfig.Proxy
+	if proxy == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http":
+			proxy = cfg.HTTPProxy
+		case "https":
+			proxy = cfg.HTTPSProxy
+		}
+	}
+	if proxy == "" || matchNoProxy(cfg.NoProxy, u) {
+		return "", nil
+	}
+	return proxy, nil
+}
+
+// firstEnv returns the first of the environment variables that is set to a
+// non-empty value.
+func (c *Config) firstEnv(names ...string) string {
+	for _, name := range names {
+		if value, ok := c.getenv(name); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// matchNoProxy reports whether u is excluded by noProxy, read the way curl
+// reads it: a list separated by commas or spaces of "*", host names that
+// also match their subdomains, with or without a leading dot, and IP
+// addresses or CIDR ranges. An entry may name a port.
+func matchNoProxy(noProxy string, u *url.URL) bool {
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	port := u.Port()
+	if port == "" {
+		port = defaultPorts[strings.ToLower(u.Scheme)]
+	}
+	addr, addrErr := netip.ParseAddr(host)
+
+	for _, entry := range strings.FieldsFunc(noProxy, func(r rune) bool { return r == ',' || r == ' ' }) {
+		entry = strings.ToLower(entry)
+		if entry == "*" {
+			return true
+		}
+
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			if addrErr == nil && prefix.Contains(addr) {
+				return true
+			}
+			continue
+		}
+
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		entry = strings.TrimSuffix(strings.Trim(entry, "[]"), ".")
+
+		if addrErr == nil {
+			if entryAddr, err := netip.ParseAddr(entry); err == nil && entryAddr == addr {
+				return true
+			}
+			continue
+		}
+
+		entry = strings.TrimPrefix(entry, ".")
+		if entry != "" && (host == entry || strings.HasSuffix(host, "."+entry)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package gitcfg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetProxyConfig(t *testing.T) {
+	config := parseTestConfig(t, `[http]
+    proxyAuthMethod = basic
+`)
+	config.lookupEnv = envMap(map[string]string{
+		"http_proxy":  "http://env-http:3128",
+		"HTTPS_PROXY": "http://env-https:3128",
+		"NO_PROXY":    "localhost,.internal",
+	})
+
+	cfg, err := config.GetProxyConfig()
+	if err != nil {
+		t.Fatalf("GetProxyConfig failed: %v", err)
+	}
+	expected := ProxyConfig{
+		HTTPProxy:       "http://env-http:3128",
+		HTTPSProxy:      "http://env-https:3128",
+		NoProxy:         "localhost,.internal",
+		ProxyAuthMethod: "basic",
+	}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+
+	config = parseTestConfig(t, `[http]
+    proxy = http://config:8080
+`)
+	config.lookupEnv = envMap(map[string]string{
+		"https_proxy":               "http://env-https:3128",
+		"GIT_HTTP_PROXY_AUTHMETHOD": "ntlm",
+	})
+	cfg, err = config.GetProxyConfig()
+	if err != nil {
+		t.Fatalf("GetProxyConfig failed: %v", err)
+	}
+	if cfg.HTTPProxy != "http://config:8080" || cfg.HTTPSProxy != "http://config:8080" {
+		t.Errorf("Expected http.proxy to win over the environment, got %+v", *cfg)
+	}
+	if cfg.ProxyAuthMethod != "ntlm" {
+		t.Errorf("Expected GIT_HTTP_PROXY_AUTHMETHOD to win, got '%s'", cfg.ProxyAuthMethod)
+	}
+}
+
+func TestGetEffectiveProxy(t *testing.T) {
+	config := parseTestConfig(t, `[http]
+    proxy = http://default:8080
+[http "https://corp.example.com"]
+    proxy = http://corp:8080
+`)
+	config.lookupEnv = envMap(map[string]string{
+		"no_proxy": "localhost, .internal.example.com,10.0.0.0/8,git.example.org:8443,[::1]",
+	})
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://github.com/org/repo.git", "http://default:8080"},
+		{"https://corp.example.com/repo.git", "http://corp:8080"},
+		{"http://localhost/repo.git", ""},
+		{"https://git.internal.example.com/repo.git", ""},
+		{"https://internal.example.com/repo.git", ""},
+		{"https://notinternal.example.com/repo.git", "http://default:8080"},
+		{"https://10.1.2.3/repo.git", ""},
+		{"https://11.1.2.3/repo.git", "http://default:8080"},
+		{"https://git.example.org:8443/repo.git", ""},
+		{"https://git.example.org/repo.git", "http://default:8080"},
+		{"https://[::1]/repo.git", ""},
+	}
+	for _, test := range tests {
+		proxy, err := config.GetEffectiveProxy(test.url)
+		if err != nil {
+			t.Fatalf("GetEffectiveProxy(%q) failed: %v", test.url, err)
+		}
+		if proxy != test.expected {
+			t.Errorf("GetEffectiveProxy(%q) = %q, expected %q", test.url, proxy, test.expected)
+		}
+	}
+
+	config = parseTestConfig(t, "")
+	config.lookupEnv = envMap(map[string]string{
+		"http_proxy": "http://env-http:3128",
+		"no_proxy":   "*",
+	})
+	if proxy, _ := config.GetEffectiveProxy("http://example.com/repo.git"); proxy != "" {
+		t.Errorf("Expected no_proxy=* to exclude every host, got %q", proxy)
+	}
+
+	config.lookupEnv = envMap(map[string]string{"http_proxy": "http://env-http:3128"})
+	if proxy, _ := config.GetEffectiveProxy("http://example.com/repo.git"); proxy != "http://env-http:3128" {
+		t.Errorf("Expected http_proxy for an http URL, got %q", proxy)
+	}
+	if proxy, _ := config.GetEffectiveProxy("https://example.com/repo.git"); proxy != "" {
+		t.Errorf("Expected no proxy for an https URL, got %q", proxy)
+	}
+
+	if _, err := config.GetEffectiveProxy("example.com/repo.git"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for a URL without a scheme, got %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package gitcfg
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeyValue is a single key/value pair returned by queries. Multi-valued keys
+// produce one KeyValue per value, like `git config --get-regexp`.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// keyGlob is a dotted glob pattern compiled one component at a time: the
+// section, the subsection if the pattern has one, and the key.
+type keyGlob struct {
+	parts []*regexp.Regexp
+}
+
+// compileGlob compiles a dotted glob pattern. The pattern is split into
+// components at its first and last dot, and each component is matched against
+// the same component of a name, so "*" stands for a whole subsection even when
+// it contains dots: "url.*.insteadof" matches url.https://github.com/.insteadof
+// while "remote.*.url" still does not match remote.origin.pushurl. Character
+// classes ([abc], [!a-z]) and backslash escapes are supported; malformed
+// patterns return path.ErrBadPattern.
+func compileGlob(pattern string) (*keyGlob, error) {
+	dots := globDots(pattern)
+	var components []string
+	switch len(dots) {
+	case 0:
+		components = []string{pattern}
+	case 1:
+		components = []string{pattern[:dots[0]], pattern[dots[0]+1:]}
+	default:
+		first, last := dots[0], dots[len(dots)-1]
+		components = []string{pattern[:first], pattern[first+1 : last], pattern[last+1:]}
+	}
+
+	g := &keyGlob{parts: make([]*regexp.Regexp, 0, len(components))}
+	for _, component := range components {
+		re, err := compileGlobComponent(component)
+		if err != nil {
+			return nil, err
+		}
+		g.parts = append(g.parts, re)
+	}
+	return g, nil
+}
+
+// globDots returns the positions of the dots in pattern that separate
+// components, skipping escaped dots and those inside character classes.
+func globDots(pattern string) []int {
+	var dots []int
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			if end := strings.IndexByte(pattern[i+1:], ']'); end > 0 {
+				i += end + 1
+			}
+		case '.':
+			dots = append(dots, i)
+		}
+	}
+	return dots
+}
+
+// compileGlobComponent translates one component of a glob pattern into an
+// anchored regexp.
+func compileGlobComponent(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; ch {
+		case '*':
+			sb.WriteString(`.*`)
+		case '?':
+			sb.WriteString(`.`)
+		case '\\':
+			if i+1 >= len(pattern) {
+				return nil, path.ErrBadPattern
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end <= 0 {
+				return nil, path.ErrBadPattern
+			}
+			class := pattern[i+1 : i+1+end]
+			if class[0] == '!' || class[0] == '^' {
+				if len(class) == 1 {
+					return nil, path.ErrBadPattern
+				}
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// matchKey reports whether the dotted key, split into its section,
+// subsection and key at its first and last dot, matches the pattern.
+func (g *keyGlob) matchKey(fullKey string) bool {
+	first, last := strings.IndexByte(fullKey, '.'), strings.LastIndexByte(fullKey, '.')
+	switch {
+	case first < 0:
+		return g.match(fullKey)
+	case first == last:
+		return g.match(fullKey[:first], fullKey[first+1:])
+	default:
+		return g.match(fullKey[:first], fullKey[first+1:last], fullKey[last+1:])
+	}
+}
+
+// matchSection reports whether the dotted section name, split into its
+// section and subsection at its first dot, matches the pattern.
+func (g *keyGlob) matchSection(section string) bool {
+	if name, subsection, found := strings.Cut(section, "."); found {
+		return g.match(name, subsection)
+	}
+	return g.match(section)
+}
+
+func (g *keyGlob) match(components ...string) bool {
+	if len(components) != len(g.parts) {
+		return false
+	}
+	for i, re := range g.parts {
+		if !re.MatchString(components[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetKeysMatchingPattern returns the sorted dotted keys matching a glob
+// pattern, where '*' matches within one key component: the section, the
+// whole subsection or the key.
+func (c *Config) GetKeysMatchingPattern(pattern string) ([]string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	glob, err := compileGlob(pattern)
+	if err != nil {
+		return nil, &ConfigError{
+			Op:  "query",
+			Key: pattern,
+			Err: fmt.Errorf("invalid pattern: %w", err),
+		}
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0)
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			if fullKey := section + "." + key; glob.matchKey(fullKey) {
+				keys = append(keys, fullKey)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// GetSectionsMatchingPattern returns the sorted section names (in their
+// dotted form, e.g. remote.origin) matching a glob pattern.
+func (c *Config) GetSectionsMatchingPattern(pattern string) ([]string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	glob, err := compileGlob(pattern)
+	if err != nil {
+		return nil, &ConfigError{
+			Op:  "query",
+			Key: pattern,
+			Err: fmt.Errorf("invalid pattern: %w", err),
+		}
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	sections := make([]string, 0)
+	for section := range c.sections {
+		if glob.matchSection(section) {
+			sections = append(sections, section)
+		}
+	}
+
+	sort.Strings(sections)
+	return sections, nil
+}
+
+// GetRegexp returns every key/value pair whose fully-qualified dotted key
+// matches the regular expression, like `git config --get-regexp`. Results are
+// sorted by key, with the values of a multi-valued key in load order.
+func (c *Config) GetRegexp(pattern string) ([]KeyValue, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, &ConfigError{
+			Op:  "query",
+			Key: pattern,
+			Err: err,
+		}
+	}
+
+	return c.queryValues(re.MatchString), nil
+}
+
+// GetGlob is like GetRegexp but takes a glob pattern in which '*' matches
+// within a single key component, so "remote.*.url" matches remote.origin.url
+// but not remote.origin.pushurl, and "url.*.insteadof" matches every
+// url.<base>.insteadof whatever dots the base contains.
+func (c *Config) GetGlob(pattern string) ([]KeyValue, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	glob, err := compileGlob(pattern)
+	if err != nil {
+		return nil, &ConfigError{
+			Op:  "query",
+			Key: pattern,
+			Err: fmt.Errorf("invalid pattern: %w", err),
+		}
+	}
+
+	return c.queryValues(glob.matchKey), nil
+}
+
+// queryValues returns a KeyValue for every value of the keys match accepts.
+func (c *Config) queryValues(match func(fullKey string) bool) []KeyValue {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	var keys []string
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			if fullKey := section + "." + key; match(fullKey) {
+				keys = append(keys, fullKey)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	result := make([]KeyValue, 0, len(keys))
+	for _, fullKey := range keys {
+		for _, value := range valueStrings(c.lookupRawValues(fullKey)) {
+			result = append(result, KeyValue{Key: fullKey, Value: value})
+		}
+	}
+	return result
+}
+
+// GetConfigAtPath evaluates a simple path expression, giving one addressing
+// syntax for values and source metadata:
+//
+//	remote.origin.url    the effective value of a key, as Get
+//	remote[origin].url   the same key with its subsection in brackets
+//	sources[0].path      the path of the first source, as GetSources
+//	sources[0].type      its type, e.g. global
+//
+// The sources form may also be written with a leading '$'. A source index
+// out of range is an error wrapping ErrKeyNotFound.
+func (c *Config) GetConfigAtPath(expr string) (string, error) {
+	if rest, ok := strings.CutPrefix(strings.TrimPrefix(expr, "$"), "sources["); ok {
+		return c.sourceAtPath(expr, rest)
+	}
+
+	key := expr
+	if open := strings.IndexByte(expr, '['); open >= 0 {
+		end := strings.LastIndex(expr, "].")
+		if end < open {
+			return "", &ConfigError{
+				Op:  "query",
+				Key: expr,
+				Err: fmt.Errorf("%w: unterminated subsection", ErrInvalidKeyFormat),
+			}
+		}
+		key = expr[:open] + "." + expr[open+1:end] + "." + expr[end+2:]
+	}
+
+	return Get[string](c, key)
+}
+
+// sourceAtPath resolves the part of a sources[n].field expression after
+// "sources[".
+func (c *Config) sourceAtPath(expr, rest string) (string, error) {
+	index, field, ok := strings.Cut(rest, "].")
+	n, err := strconv.Atoi(index)
+	if !ok || err != nil || n < 0 {
+		return "", &ConfigError{
+			Op:  "query",
+			Key: expr,
+			Err: fmt.Errorf("%w: expected sources[n].path or sources[n].type", ErrInvalidKeyFormat),
+		}
+	}
+
+	sources := c.GetSources()
+	if n >= len(sources) {
+		return "", &ConfigError{
+			Op:  "query",
+			Key: expr,
+			Err: fmt.Errorf("%w: source index %d out of range with %d sources", ErrKeyNotFound, n, len(sources)),
+		}
+	}
+
+	switch field {
+	case "path":
+		return sources[n].Path, nil
+	case "type":
+		return sources[n].Type.String(), nil
+	default:
+		return "", &ConfigError{
+			Op:  "query",
+			Key: expr,
+			Err: fmt.Errorf("%w: unknown source field %q", ErrInvalidKeyFormat, field),
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package gitcfg
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func newQueryTestConfig() *Config {
+	return &Config{
+		sections: map[string]map[string]string{
+			"user":            {"name": "Test User", "email": "test@example.com"},
+			"remote.origin":   {"url": "https://github.com/example/repo.git", "fetch": "+refs/heads/*:refs/remotes/origin/*", "pushurl": "git@github.com:example/repo.git"},
+			"remote.upstream": {"url": "https://github.com/upstream/repo.git"},
+			"branch.main":     {"remote": "origin", "merge": "refs/heads/main"},
+		},
+	}
+}
+
+func TestGetKeys(t *testing.T) {
+	config := newQueryTestConfig()
+
+	keys := config.GetKeys()
+	if len(keys) != 8 {
+		t.Errorf("Expected 8 keys, got %d: %v", len(keys), keys)
+	}
+}
+
+func TestGetKeysMatchingPattern(t *testing.T) {
+	config := newQueryTestConfig()
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"remote.*.url", []string{"remote.origin.url", "remote.upstream.url"}},
+		{"branch.*.remote", []string{"branch.main.remote"}},
+		{"user.*", []string{"user.email", "user.name"}},
+		{"*.name", []string{"user.name"}},
+		{"remote.origin.[fp]*", []string{"remote.origin.fetch", "remote.origin.pushurl"}},
+		{"remote.*", []string{}},
+		{"core.*", []string{}},
+	}
+
+	for _, test := range tests {
+		keys, err := config.GetKeysMatchingPattern(test.pattern)
+		if err != nil {
+			t.Errorf("GetKeysMatchingPattern(%q) failed: %v", test.pattern, err)
+			continue
+		}
+		if !reflect.DeepEqual(keys, test.expected) {
+			t.Errorf("GetKeysMatchingPattern(%q): expected %v, got %v", test.pattern, test.expected, keys)
+		}
+	}
+}
+
+func TestGetKeysMatchingPatternMalformed(t *testing.T) {
+	config := newQueryTestConfig()
+
+	for _, pattern := range []string{"remote.[origin.url", `user.name\`, "user.[!]"} {
+		_, err := config.GetKeysMatchingPattern(pattern)
+		if err == nil {
+			t.Errorf("Expected error for malformed pattern %q", pattern)
+			continue
+		}
+		if !errors.Is(err, path.ErrBadPattern) {
+			t.Errorf("Expected ErrBadPattern for %q, got %v", pattern, err)
+		}
+	}
+}
+
+func TestGlobMatchesDottedSubsections(t *testing.T) {
+	config := newQueryTestConfig()
+	config.sections["url.https://github.com/"] = map[string]string{"insteadof": "gh:"}
+	config.sections["http.https://example.com"] = map[string]string{"sslverify": "false"}
+	config.sections["credential.https://git.example.com"] = map[string]string{"helper": "store"}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"url.*.insteadof", []string{"url.https://github.com/.insteadof"}},
+		{"http.*.sslverify", []string{"http.https://example.com.sslverify"}},
+		{"credential.*.helper", []string{"credential.https://git.example.com.helper"}},
+		{"url.https://github.com/.insteadof", []string{"url.https://github.com/.insteadof"}},
+		{"url.https://*.com/.*", []string{"url.https://github.com/.insteadof"}},
+		{"*.*.url", []string{"remote.origin.url", "remote.upstream.url"}},
+		{"remote.*.url", []string{"remote.origin.url", "remote.upstream.url"}},
+	}
+	for _, test := range tests {
+		keys, err := config.GetKeysMatchingPattern(test.pattern)
+		if err != nil {
+			t.Errorf("GetKeysMatchingPattern(%q) failed: %v", test.pattern, err)
+			continue
+		}
+		if !reflect.DeepEqual(keys, test.expected) {
+			t.Errorf("GetKeysMatchingPattern(%q): expected %v, got %v", test.pattern, test.expected, keys)
+		}
+	}
+
+	sections, err := config.GetSectionsMatchingPattern("url.*")
+	if err != nil {
+		t.Fatalf("GetSectionsMatchingPattern failed: %v", err)
+	}
+	if expected := []string{"url.https://github.com/"}; !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Expected %v, got %v", expected, sections)
+	}
+
+	redaction, err := newRedactor([]string{"http.*.sslverify"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !redaction.isSensitive("http.https://example.com", "sslverify") {
+		t.Error("Expected the redaction pattern to match a dotted subsection")
+	}
+}
+
+func TestGetSectionsMatchingPattern(t *testing.T) {
+	config := newQueryTestConfig()
+
+	sections, err := config.GetSectionsMatchingPattern("remote.*")
+	if err != nil {
+		t.Fatalf("GetSectionsMatchingPattern failed: %v", err)
+	}
+
+	expected := []string{"remote.origin", "remote.upstream"}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Expected %v, got %v", expected, sections)
+	}
+}
+
+func TestGetRegexp(t *testing.T) {
+	config := newQueryTestConfig()
+	config.sections["url.corp"] = map[string]string{"insteadof": "https://github.com/"}
+
+	results, err := config.GetRegexp(`^url\..*\.insteadof$`)
+	if err != nil {
+		t.Fatalf("GetRegexp failed: %v", err)
+	}
+	expected := []KeyValue{{Key: "url.corp.insteadof", Value: "https://github.com/"}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	results, err = config.GetRegexp(`^remote\.`)
+	if err != nil {
+		t.Fatalf("GetRegexp failed: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 remote entries, got %d", len(results))
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i-1].Key > results[i].Key {
+			t.Errorf("Results not sorted: %v", results)
+		}
+	}
+}
+
+func TestGetRegexpInvalid(t *testing.T) {
+	config := newQueryTestConfig()
+
+	_, err := config.GetRegexp(`remote.(`)
+	if err == nil {
+		t.Fatal("Expected error for invalid regexp")
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Op != "query" {
+		t.Errorf("Expected ConfigError with Op 'query', got %v", err)
+	}
+}
+
+func TestGetGlob(t *testing.T) {
+	config := newQueryTestConfig()
+	config.values = map[string]map[string][]configValue{
+		"remote.origin": {"url": {{value: "https://github.com/example/repo.git"}, {value: "https://mirror.example.com/repo.git"}}},
+	}
+
+	results, err := config.GetGlob("remote.*.url")
+	if err != nil {
+		t.Fatalf("GetGlob failed: %v", err)
+	}
+
+	expected := []KeyValue{
+		{Key: "remote.origin.url", Value: "https://github.com/example/repo.git"},
+		{Key: "remote.origin.url", Value: "https://mirror.example.com/repo.git"},
+		{Key: "remote.upstream.url", Value: "https://github.com/upstream/repo.git"},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestGetConfigAtPath(t *testing.T) {
+	config := newQueryTestConfig()
+	config.sections["url.https://github.com/"] = map[string]string{"insteadof": "gh:"}
+	config.sources = []ConfigSource{
+		{Type: SourceTypeGlobal, Path: "/home/user/.gitconfig"},
+		{Type: SourceTypeLocal, Path: "/repo/.git/config"},
+	}
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"user.name", "Test User"},
+		{"remote.origin.url", "https://github.com/example/repo.git"},
+		{"remote[origin].url", "https://github.com/example/repo.git"},
+		{"url[https://github.com/].insteadOf", "gh:"},
+		{"sources[0].path", "/home/user/.gitconfig"},
+		{"sources[1].type", "local"},
+		{"$sources[1].path", "/repo/.git/config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			value, err := config.GetConfigAtPath(tt.expr)
+			if err != nil {
+				t.Fatalf("GetConfigAtPath() error = %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("GetConfigAtPath() = %q, want %q", value, tt.expected)
+			}
+		})
+	}
+
+	errorTests := []struct {
+		expr string
+		err  error
+	}{
+		{"sources[2].path", ErrKeyNotFound},
+		{"sources[0].size", ErrInvalidKeyFormat},
+		{"sources[x].path", ErrInvalidKeyFormat},
+		{"remote[origin.url", ErrInvalidKeyFormat},
+		{"remote[origin].proxy", ErrKeyNotFound},
+	}
+
+	for _, tt := range errorTests {
+		if _, err := config.GetConfigAtPath(tt.expr); !errors.Is(err, tt.err) {
+			t.Errorf("GetConfigAtPath(%q) error = %v, want %v", tt.expr, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gitcfg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GetPackedRefs reads the packed-refs file next to the local config and
+// returns the object name of each packed ref, keyed by the full ref name.
+// A repository without packed-refs has no packed refs, which is not an
+// error. It fails with an error wrapping ErrSectionNotFound when no local
+// config is loaded.
+func (c *Config) GetPackedRefs() (map[string]string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	refs, _, err := c.readPackedRefs()
+	return refs, err
+}
+
+// GetPackedRefPeeled is like GetPackedRefs but returns, for each annotated
+// tag that packed-refs records a peeled value for, the object the tag points
+// to, taken from the "^" line that follows it.
+func (c *Config) GetPackedRefPeeled() (map[string]string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	_, peeled, err := c.readPackedRefs()
+	return peeled, err
+}
+
+func (c *Config) readPackedRefs() (refs, peeled map[string]string, err error) {
+	gitDir := c.gitDir()
+	if gitDir == "" {
+		return nil, nil, &ConfigError{
+			Op:  "get",
+			Err: fmt.Errorf("%w: no local config loaded", ErrSectionNotFound),
+		}
+	}
+
+	refs = make(map[string]string)
+	peeled = make(map[string]string)
+
+	path := filepath.Join(gitDir, "packed-refs")
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return refs, peeled, nil
+	} else if err != nil {
+		return nil, nil, &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    err,
+		}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	var lastRef string
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" || line[0] == '#' {
+			// The "# pack-refs with: ..." header
+			continue
+		}
+
+		if sha, ok := strings.CutPrefix(line, "^"); ok {
+			if lastRef == "" {
+				return nil, nil, packedRefsError(path, lineNumber, "peeled line without a ref")
+			}
+			peeled[lastRef] = sha
+			continue
+		}
+
+		sha, ref, ok := strings.Cut(line, " ")
+		if !ok || sha == "" || ref == "" {
+			return nil, nil, packedRefsError(path, lineNumber, "expected <sha> <refname>")
+		}
+		refs[ref] = sha
+		lastRef = ref
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    err,
+		}
+	}
+
+	return refs, peeled, nil
+}
+
+func packedRefsError(path string, line int, msg string) error {
+	return &ConfigError{
+		Op:     "get",
+		Source: path,
+		Line:   line,
+		Err:    fmt.Errorf("%w: %s", ErrInvalidValue, msg),
+	}
+}
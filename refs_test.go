@@ -0,0 +1,65 @@
+package gitcfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetPackedRefs(t *testing.T) {
+	gitDir := t.TempDir()
+	source := ConfigSource{Type: SourceTypeLocal, Path: filepath.Join(gitDir, "config")}
+	config := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  []ConfigSource{source},
+	}
+
+	refs, err := config.GetPackedRefs()
+	if err != nil || len(refs) != 0 {
+		t.Fatalf("GetPackedRefs() without packed-refs = %v, %v, want empty", refs, err)
+	}
+
+	data := "# pack-refs with: peeled fully-peeled sorted \n" +
+		"3f786850e387550fdab836ed7e6dc881de23001b refs/heads/main\n" +
+		"89e6c98d92887913cadf06b2adb97f26cde4849b refs/remotes/origin/main\n" +
+		"2b66fd261ee5c6cfc8de7fa466bab600bcfe4f69 refs/tags/v1.0\n" +
+		"^3f786850e387550fdab836ed7e6dc881de23001b\n"
+	if err := os.WriteFile(filepath.Join(gitDir, "packed-refs"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err = config.GetPackedRefs()
+	if err != nil {
+		t.Fatalf("GetPackedRefs() error = %v", err)
+	}
+	wantRefs := map[string]string{
+		"refs/heads/main":          "3f786850e387550fdab836ed7e6dc881de23001b",
+		"refs/remotes/origin/main": "89e6c98d92887913cadf06b2adb97f26cde4849b",
+		"refs/tags/v1.0":           "2b66fd261ee5c6cfc8de7fa466bab600bcfe4f69",
+	}
+	if !reflect.DeepEqual(refs, wantRefs) {
+		t.Errorf("GetPackedRefs() = %v, want %v", refs, wantRefs)
+	}
+
+	peeled, err := config.GetPackedRefPeeled()
+	if err != nil {
+		t.Fatalf("GetPackedRefPeeled() error = %v", err)
+	}
+	wantPeeled := map[string]string{"refs/tags/v1.0": "3f786850e387550fdab836ed7e6dc881de23001b"}
+	if !reflect.DeepEqual(peeled, wantPeeled) {
+		t.Errorf("GetPackedRefPeeled() = %v, want %v", peeled, wantPeeled)
+	}
+
+	if err := os.WriteFile(filepath.Join(gitDir, "packed-refs"), []byte("^3f786850e387550fdab836ed7e6dc881de23001b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.GetPackedRefs(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("GetPackedRefs() with a stray peeled line error = %v, want ErrInvalidValue", err)
+	}
+
+	if _, err := parseTestConfig(t, "[core]\n\tbare = false\n").GetPackedRefs(); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("GetPackedRefs() without a local source error = %v, want ErrSectionNotFound", err)
+	}
+}
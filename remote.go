@@ -0,0 +1,239 @@
+package gitcfg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GetRemote returns the configuration of the named remote, or an error
+// wrapping ErrSectionNotFound if no such remote is configured.
+func (c *Config) GetRemote(name string) (*Remote, error) {
+	return c.GetRemoteWithContext(context.Background(), name)
+}
+
+// GetRemoteWithContext is like GetRemote but returns ctx.Err() if ctx is
+// already done.
+func (c *Config) GetRemoteWithContext(ctx context.Context, name string) (*Remote, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	section := "remote." + name
+	if !c.HasSection(section) {
+		return nil, &ConfigError{
+			Op:      "get",
+			Section: section,
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	remote := &Remote{Name: name}
+
+	r := &fieldReader{c: c}
+	readField(r, section+".url", &remote.URL)
+	readField(r, section+".pushurl", &remote.PushURL)
+	readMultiField(r, section+".fetch", &remote.Fetch)
+	readMultiField(r, section+".push", &remote.Push)
+	readField(r, section+".proxy", &remote.Proxy)
+	readField(r, section+".mirror", &remote.Mirror)
+	readField(r, section+".skipdefaultupdate", &remote.SkipDefaultUpdate)
+	readField(r, section+".tagopt", &remote.TagOpt)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	remote.FetchURL = c.rewriteURL(remote.URL, "insteadof")
+	remote.EffectivePushURL = c.pushURL(remote)
+
+	return remote, nil
+}
+
+// GetRemoteMirrorConfig returns the mirror and transport settings of the
+// named remote, or an error wrapping ErrSectionNotFound if no such remote is
+// configured.
+func (c *Config) GetRemoteMirrorConfig(name string) (*MirrorConfig, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	section := "remote." + name
+	if !c.HasSection(section) {
+		return nil, &ConfigError{
+			Op:      "get",
+			Section: section,
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	cfg := &MirrorConfig{RemoteName: name}
+
+	r := &fieldReader{c: c}
+	readField(r, section+".mirror", &cfg.Mirror)
+	readField(r, section+".skipdefaultupdate", &cfg.SkipDefaultUpdate)
+	readField(r, section+".receivepack", &cfg.ReceivePack)
+	readField(r, section+".uploadpack", &cfg.UploadPack)
+	readField(r, section+".tagopt", &cfg.TagOpt)
+	readField(r, section+".proxyauthmethod", &cfg.ProxyAuthMethod)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetAllRemotes returns every configured remote sorted by name.
+func (c *Config) GetAllRemotes() ([]*Remote, error) {
+	return c.GetAllRemotesWithContext(context.Background())
+}
+
+// GetAllRemotesWithContext is like GetAllRemotes but stops with ctx.Err()
+// once ctx is done, checking every ContextCheckInterval remotes.
+func (c *Config) GetAllRemotesWithContext(ctx context.Context) ([]*Remote, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	names := c.SubsectionNames("remote")
+
+	remotes := make([]*Remote, 0, len(names))
+	for i, name := range names {
+		if err := checkContext(ctx, i); err != nil {
+			return nil, err
+		}
+		remote, err := c.GetRemote(name)
+		if err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, remote)
+	}
+	return remotes, nil
+}
+
+// GetRemotesByURL returns the remotes, sorted by name, whose URL, FetchURL or
+// PushURL contains pattern. A pattern starting with '^' is treated as a
+// regular expression instead.
+func (c *Config) GetRemotesByURL(pattern string) ([]*Remote, error) {
+	return c.GetRemotesByURLWithContext(context.Background(), pattern)
+}
+
+// GetRemotesByURLWithContext is like GetRemotesByURL but stops with
+// ctx.Err() once ctx is done.
+func (c *Config) GetRemotesByURLWithContext(ctx context.Context, pattern string) ([]*Remote, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	match := func(url string) bool { return strings.Contains(url, pattern) }
+	if strings.HasPrefix(pattern, "^") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, &ConfigError{
+				Op:  "query",
+				Key: pattern,
+				Err: fmt.Errorf("invalid pattern: %w", err),
+			}
+		}
+		match = re.MatchString
+	}
+
+	remotes, err := c.GetAllRemotesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*Remote, 0)
+	for _, remote := range remotes {
+		if remote.matchesURL(match) {
+			matches = append(matches, remote)
+		}
+	}
+	return matches, nil
+}
+
+// GetRemoteByExactURL returns the first remote, by name, whose URL, FetchURL
+// or PushURL equals url, or an error wrapping ErrSectionNotFound.
+func (c *Config) GetRemoteByExactURL(url string) (*Remote, error) {
+	return c.GetRemoteByExactURLWithContext(context.Background(), url)
+}
+
+// GetRemoteByExactURLWithContext is like GetRemoteByExactURL but stops with
+// ctx.Err() once ctx is done.
+func (c *Config) GetRemoteByExactURLWithContext(ctx context.Context, url string) (*Remote, error) {
+	remotes, err := c.GetAllRemotesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, remote := range remotes {
+		if remote.matchesURL(func(u string) bool { return u == url }) {
+			return remote, nil
+		}
+	}
+
+	return nil, &ConfigError{
+		Op:      "get",
+		Section: "remote",
+		Err:     fmt.Errorf("%w: no remote with URL %s", ErrSectionNotFound, url),
+	}
+}
+
+// pushURL resolves the URL git pushes to: pushurl rewritten by insteadOf if
+// set, otherwise url rewritten by pushInsteadOf, falling back to insteadOf
+// when no pushInsteadOf rule matches.
+func (c *Config) pushURL(remote *Remote) string {
+	if remote.PushURL != "" {
+		return c.rewriteURL(remote.PushURL, "insteadof")
+	}
+	if rewritten := c.rewriteURL(remote.URL, "pushinsteadof"); rewritten != remote.URL {
+		return rewritten
+	}
+	return remote.FetchURL
+}
+
+func (r *Remote) matchesURL(match func(string) bool) bool {
+	for _, url := range []string{r.URL, r.FetchURL, r.PushURL} {
+		if url != "" && match(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteURL applies git's url.<base>.insteadOf (or pushInsteadOf) rules:
+// the longest matching prefix is replaced by its base.
+func (c *Config) rewriteURL(url, key string) string {
+	if url == "" {
+		return url
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	var base, longest string
+	for section := range c.sections {
+		candidate, ok := strings.CutPrefix(section, "url.")
+		if !ok {
+			continue
+		}
+		for _, prefix := range valueStrings(c.rawValues(section, key)) {
+			if !strings.HasPrefix(url, prefix) || prefix == "" {
+				continue
+			}
+			// Ties are broken by base so the result does not depend on map order
+			if len(prefix) > len(longest) || (len(prefix) == len(longest) && candidate < base) {
+				base, longest = candidate, prefix
+			}
+		}
+	}
+
+	if longest == "" {
+		return url
+	}
+	return base + url[len(longest):]
+}
@@ -0,0 +1,264 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const remotesTestConfig = `[remote "origin"]
+    url = https://github.com/user/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+[remote "fork"]
+    url = gh:someone/repo.git
+    pushurl = git@github.com:someone/repo.git
+[remote "mirror"]
+    url = https://github.com/user/repo.git
+    push = +refs/heads/*:refs/heads/*
+[remote "gitlab"]
+    url = https://gitlab.com/user/other.git
+[url "https://github.com/"]
+    insteadOf = gh:
+`
+
+func TestGetRemote(t *testing.T) {
+	config := parseTestConfig(t, remotesTestConfig)
+
+	origin, err := config.GetRemote("origin")
+	if err != nil {
+		t.Fatalf("GetRemote failed: %v", err)
+	}
+
+	expected := &Remote{
+		Name:             "origin",
+		URL:              "https://github.com/user/repo.git",
+		FetchURL:         "https://github.com/user/repo.git",
+		EffectivePushURL: "https://github.com/user/repo.git",
+		Fetch:            []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"},
+	}
+	if !reflect.DeepEqual(origin, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, origin)
+	}
+
+	fork, err := config.GetRemote("fork")
+	if err != nil {
+		t.Fatalf("GetRemote failed: %v", err)
+	}
+	if fork.FetchURL != "https://github.com/someone/repo.git" {
+		t.Errorf("Expected insteadOf rewrite, got %s", fork.FetchURL)
+	}
+	if fork.PushURL != "git@github.com:someone/repo.git" {
+		t.Errorf("Unexpected push URL %s", fork.PushURL)
+	}
+
+	if _, err := config.GetRemote("missing"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+}
+
+func TestGetRemotesByURL(t *testing.T) {
+	config := parseTestConfig(t, remotesTestConfig)
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"user/repo.git", []string{"mirror", "origin"}},
+		{"github.com", []string{"fork", "mirror", "origin"}},
+		{"someone", []string{"fork"}},
+		{`^https://gitlab\.com/`, []string{"gitlab"}},
+		{"^git@", []string{"fork"}},
+		{"bitbucket.org", []string{}},
+	}
+
+	for _, test := range tests {
+		remotes, err := config.GetRemotesByURL(test.pattern)
+		if err != nil {
+			t.Fatalf("GetRemotesByURL(%q) failed: %v", test.pattern, err)
+		}
+
+		names := make([]string, len(remotes))
+		for i, remote := range remotes {
+			names[i] = remote.Name
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("GetRemotesByURL(%q) = %v, expected %v", test.pattern, names, test.expected)
+		}
+	}
+
+	if _, err := config.GetRemotesByURL("^("); err == nil {
+		t.Error("Expected error for invalid regexp")
+	}
+}
+
+func TestGetRemoteByExactURL(t *testing.T) {
+	config := parseTestConfig(t, remotesTestConfig)
+
+	remote, err := config.GetRemoteByExactURL("https://github.com/user/repo.git")
+	if err != nil {
+		t.Fatalf("GetRemoteByExactURL failed: %v", err)
+	}
+	if remote.Name != "mirror" {
+		t.Errorf("Expected first match by name 'mirror', got %s", remote.Name)
+	}
+
+	remote, err = config.GetRemoteByExactURL("git@github.com:someone/repo.git")
+	if err != nil {
+		t.Fatalf("GetRemoteByExactURL failed: %v", err)
+	}
+	if remote.Name != "fork" {
+		t.Errorf("Expected 'fork', got %s", remote.Name)
+	}
+
+	if _, err := config.GetRemoteByExactURL("https://github.com/user"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+}
+
+func TestGetRemotePushURLAndProxy(t *testing.T) {
+	config := parseTestConfig(t, `[remote "origin"]
+    url = https://github.com/user/repo.git
+    proxy = http://proxy.example.com:3128
+[remote "fork"]
+    url = gh:someone/repo.git
+    pushurl = gh:someone/push.git
+[url "https://github.com/"]
+    insteadOf = gh:
+[url "git@github.com:"]
+    pushInsteadOf = https://github.com/`)
+
+	origin, err := config.GetRemote("origin")
+	if err != nil {
+		t.Fatalf("GetRemote failed: %v", err)
+	}
+	if origin.Proxy != "http://proxy.example.com:3128" {
+		t.Errorf("Expected proxy, got %q", origin.Proxy)
+	}
+	if origin.EffectivePushURL != "git@github.com:user/repo.git" {
+		t.Errorf("Expected pushInsteadOf rewrite, got %q", origin.EffectivePushURL)
+	}
+
+	fork, err := config.GetRemote("fork")
+	if err != nil {
+		t.Fatalf("GetRemote failed: %v", err)
+	}
+	// An explicit pushurl is only subject to insteadOf, never pushInsteadOf
+	if fork.EffectivePushURL != "https://github.com/someone/push.git" {
+		t.Errorf("Expected insteadOf rewrite of pushurl, got %q", fork.EffectivePushURL)
+	}
+}
+
+// countingContext counts calls to Err and reports cancellation from the
+// given call onwards.
+type countingContext struct {
+	context.Context
+	calls    int
+	cancelAt int
+}
+
+func (c *countingContext) Err() error {
+	c.calls++
+	if c.calls >= c.cancelAt {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestGetAllRemotesWithContext(t *testing.T) {
+	const remotes = 2500
+
+	var sb strings.Builder
+	for i := 0; i < remotes; i++ {
+		fmt.Fprintf(&sb, "[remote \"r%04d\"]\n    url = https://example.com/%d.git\n", i, i)
+	}
+	config := parseTestConfig(t, sb.String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := config.GetAllRemotesWithContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := config.GetRemoteWithContext(ctx, "r0000"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GetRemoteWithContext, got %v", err)
+	}
+
+	// Cancellation partway through is noticed at the next check
+	counting := &countingContext{Context: context.Background(), cancelAt: 2}
+	if _, err := config.GetAllRemotesWithContext(counting); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	counting = &countingContext{Context: context.Background(), cancelAt: remotes}
+	all, err := config.GetAllRemotesWithContext(counting)
+	if err != nil {
+		t.Fatalf("GetAllRemotesWithContext failed: %v", err)
+	}
+	if len(all) != remotes {
+		t.Errorf("Expected %d remotes, got %d", remotes, len(all))
+	}
+	if expected := (remotes + ContextCheckInterval - 1) / ContextCheckInterval; counting.calls != expected {
+		t.Errorf("Expected %d context checks, got %d", expected, counting.calls)
+	}
+}
+
+const mirrorTestConfig = `[remote "backup"]
+    url = git@backup.example.com:org/repo.git
+    mirror = true
+    skipDefaultUpdate = true
+    tagOpt = --no-tags
+    receivepack = /usr/local/bin/git-receive-pack
+    uploadpack = /usr/local/bin/git-upload-pack
+    proxyAuthMethod = basic
+[remote "origin"]
+    url = https://github.com/user/repo.git
+`
+
+func TestGetRemoteMirrorConfig(t *testing.T) {
+	config := parseTestConfig(t, mirrorTestConfig)
+
+	cfg, err := config.GetRemoteMirrorConfig("backup")
+	if err != nil {
+		t.Fatalf("GetRemoteMirrorConfig failed: %v", err)
+	}
+	expected := &MirrorConfig{
+		RemoteName:        "backup",
+		Mirror:            true,
+		SkipDefaultUpdate: true,
+		ReceivePack:       "/usr/local/bin/git-receive-pack",
+		UploadPack:        "/usr/local/bin/git-upload-pack",
+		TagOpt:            "--no-tags",
+		ProxyAuthMethod:   "basic",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, cfg)
+	}
+
+	origin, err := config.GetRemoteMirrorConfig("origin")
+	if err != nil {
+		t.Fatalf("GetRemoteMirrorConfig failed: %v", err)
+	}
+	if !reflect.DeepEqual(origin, &MirrorConfig{RemoteName: "origin"}) {
+		t.Errorf("Expected an unset mirror config, got %+v", origin)
+	}
+
+	remote, err := config.GetRemote("backup")
+	if err != nil {
+		t.Fatalf("GetRemote failed: %v", err)
+	}
+	if !remote.Mirror || !remote.SkipDefaultUpdate || remote.TagOpt != "--no-tags" {
+		t.Errorf("Expected GetRemote to read the mirror settings, got %+v", remote)
+	}
+
+	if _, err := config.GetRemoteMirrorConfig("missing"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+
+	invalid := parseTestConfig(t, "[remote \"backup\"]\n    mirror = sometimes\n")
+	if _, err := invalid.GetRemoteMirrorConfig("backup"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
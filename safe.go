@@ -0,0 +1,106 @@
+package gitcfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// caseInsensitiveFS reports whether paths are compared case-insensitively,
+// as on the default macOS and Windows filesystems.
+var caseInsensitiveFS = runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+
+// GetSafeDirectories returns the safe.directory values from the system and
+// global scopes in precedence order. Like git, it ignores values from
+// repository and worktree config, which the repository being checked
+// controls. An empty value resets the list. The result is empty, not an
+// error, when the key is absent.
+func (c *Config) GetSafeDirectories() ([]string, error) {
+	values, err := c.GetMultiValueWithSources(SafeDirectory)
+	if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrSectionNotFound) {
+		return make([]string, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0, len(values))
+	for _, value := range values {
+		if !isProtectedSource(value.Source.Type) {
+			continue
+		}
+		if value.Value == "" {
+			dirs = dirs[:0]
+			continue
+		}
+		dirs = append(dirs, value.Value)
+	}
+	return dirs, nil
+}
+
+// isProtectedSource reports whether git honors security-sensitive keys such
+// as safe.directory from a source of type t.
+func isProtectedSource(t ConfigSourceType) bool {
+	return t == SourceTypeSystem || t == SourceTypeGlobal
+}
+
+// IsSafeDirectory reports whether safe.directory trusts path. A value of "*"
+// trusts every directory and a value ending in "/*" trusts everything below
+// it; any other value must name path exactly. Both sides are compared after
+// expanding ~, resolving symlinks and, on case-insensitive filesystems,
+// folding case.
+func (c *Config) IsSafeDirectory(path string) bool {
+	dirs, err := c.GetSafeDirectories()
+	if err != nil || len(dirs) == 0 {
+		return false
+	}
+
+	target, ok := canonicalDir(path)
+	if !ok {
+		return false
+	}
+
+	for _, dir := range dirs {
+		if dir == "*" {
+			return true
+		}
+
+		if prefix, found := strings.CutSuffix(dir, "/*"); found {
+			prefix, ok := canonicalDir(prefix)
+			if ok && strings.HasPrefix(target, strings.TrimSuffix(prefix, string(filepath.Separator))+string(filepath.Separator)) {
+				return true
+			}
+			continue
+		}
+
+		if dir, ok := canonicalDir(dir); ok && dir == target {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalDir returns path in the form safe.directory values are compared
+// in. Paths that do not exist are compared as written.
+func canonicalDir(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+
+	abs, err := expandPath(path)
+	if err != nil {
+		return "", false
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	} else if !os.IsNotExist(err) {
+		return "", false
+	}
+
+	if caseInsensitiveFS {
+		abs = strings.ToLower(abs)
+	}
+	return abs, true
+}
@@ -0,0 +1,121 @@
+package gitcfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetSafeDirectories(t *testing.T) {
+	config := parseTestConfig(t, `[safe]
+    directory = /srv/old
+    directory =
+    directory = /srv/one
+    directory = /srv/two`)
+
+	dirs, err := config.GetSafeDirectories()
+	if err != nil {
+		t.Fatalf("GetSafeDirectories() error = %v", err)
+	}
+	if want := []string{"/srv/one", "/srv/two"}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("GetSafeDirectories() = %v, want %v", dirs, want)
+	}
+
+	empty := parseTestConfig(t, "[user]\n    name = Test User")
+	dirs, err = empty.GetSafeDirectories()
+	if err != nil || len(dirs) != 0 {
+		t.Errorf("GetSafeDirectories() = %v, %v, want empty", dirs, err)
+	}
+}
+
+func TestIsSafeDirectory(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repos", "project")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(repo, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		values []string
+		path   string
+		want   bool
+	}{
+		{"none", nil, repo, false},
+		{"exact", []string{repo}, repo, true},
+		{"other", []string{filepath.Join(root, "other")}, repo, false},
+		{"wildcard", []string{"*"}, repo, true},
+		{"prefix", []string{filepath.Join(root, "repos") + "/*"}, repo, true},
+		{"prefix excludes itself", []string{repo + "/*"}, repo, false},
+		{"symlinked path", []string{repo}, link, true},
+		{"symlinked value", []string{link}, repo, true},
+		{"reset", []string{"*", ""}, repo, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			b.WriteString("[safe]\n")
+			for _, value := range tt.values {
+				b.WriteString("    directory = " + value + "\n")
+			}
+			config := parseTestConfig(t, b.String())
+
+			if got := config.IsSafeDirectory(tt.path); got != tt.want {
+				t.Errorf("IsSafeDirectory(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSafeDirectoryCaseFolding(t *testing.T) {
+	saved := caseInsensitiveFS
+	caseInsensitiveFS = true
+	defer func() { caseInsensitiveFS = saved }()
+
+	config := parseTestConfig(t, "[safe]\n    directory = /Srv/Repo\n")
+	if !config.IsSafeDirectory("/srv/repo") {
+		t.Error("Expected case-insensitive match")
+	}
+
+	caseInsensitiveFS = false
+	if config.IsSafeDirectory("/srv/repo") {
+		t.Error("Expected case-sensitive mismatch")
+	}
+}
+
+func TestSafeDirectoryIgnoresRepositoryConfig(t *testing.T) {
+	repo := t.TempDir()
+
+	config := &Config{sections: make(map[string]map[string]string)}
+	scopes := []struct {
+		source ConfigSource
+		data   string
+	}{
+		{ConfigSource{Type: SourceTypeGlobal, Path: "global"}, "[safe]\n    directory = /srv/trusted\n"},
+		{ConfigSource{Type: SourceTypeLocal, Path: "local"}, "[safe]\n    directory = *\n    directory =\n"},
+		{ConfigSource{Type: SourceTypeWorktree, Path: "worktree"}, "[safe]\n    directory = " + repo + "\n"},
+	}
+	for _, scope := range scopes {
+		if err := newParser().parseConfigReader(strings.NewReader(scope.data), config, scope.source); err != nil {
+			t.Fatalf("parseConfigReader failed: %v", err)
+		}
+	}
+
+	dirs, err := config.GetSafeDirectories()
+	if err != nil {
+		t.Fatalf("GetSafeDirectories() error = %v", err)
+	}
+	if want := []string{"/srv/trusted"}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("GetSafeDirectories() = %v, want %v", dirs, want)
+	}
+	if config.IsSafeDirectory(repo) {
+		t.Error("Expected safe.directory from repository config to be ignored")
+	}
+}
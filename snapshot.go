@@ -0,0 +1,232 @@
+package gitcfg
+
+import (
+	"context"
+	"crypto/sha256"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Snapshot is an immutable, point-in-time copy of a configuration. It is
+// made with a single copy under the read lock of the Config, after which its
+// methods read plain maps without any locking, so a Snapshot can be shared by
+// any number of goroutines and kept as a consistent view for as long as
+// needed. To publish a fresh view after every Reload, store Snapshots in an
+// atomic.Pointer. Typed values are read with SnapshotGet.
+//
+// The zero value represents an empty config.
+type Snapshot struct {
+	config *Config
+}
+
+// Snapshot captures the current keys and values of the configuration.
+func (c *Config) Snapshot() *Snapshot {
+	return &Snapshot{config: c.Clone()}
+}
+
+// view returns the captured config, or an empty one for a nil or zero
+// Snapshot.
+func (s *Snapshot) view() *Config {
+	if s == nil || s.config == nil {
+		return &Config{}
+	}
+	return s.config
+}
+
+// SnapshotGet is Get for a Snapshot.
+func SnapshotGet[T Constraint](s *Snapshot, key string) (T, error) {
+	value, _, err := lookupValue[T](s.view(), key)
+	return value, err
+}
+
+// SnapshotGetWithSource is GetWithSource for a Snapshot.
+func SnapshotGetWithSource[T Constraint](s *Snapshot, key string) (T, *ConfigSource, error) {
+	value, source, err := lookupValue[T](s.view(), key)
+	if err != nil {
+		return value, nil, err
+	}
+	return value, copySource(source), nil
+}
+
+// SnapshotGetWithDefault is GetWithDefault for a Snapshot.
+func SnapshotGetWithDefault[T Constraint](s *Snapshot, key string, defaultValue T) T {
+	value, err := SnapshotGet[T](s, key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetStringOK returns the effective value of key and whether it is set.
+func (s *Snapshot) GetStringOK(key string) (string, bool) {
+	_, _, value, err := s.view().lookup(key)
+	if err != nil {
+		return "", false
+	}
+	return value.value, true
+}
+
+// Has reports whether key is set.
+func (s *Snapshot) Has(key string) bool {
+	section, subkey, err := parseConfigKey(key)
+	if err != nil {
+		return false
+	}
+	_, exists := s.view().sections[section][subkey]
+	return exists
+}
+
+// HasSection reports whether section exists.
+func (s *Snapshot) HasSection(section string) bool {
+	_, exists := s.view().sections[section]
+	return exists
+}
+
+// GetSection returns a copy of the effective values of section.
+func (s *Snapshot) GetSection(section string) map[string]string {
+	sectionMap := s.view().sections[section]
+	result := make(map[string]string, len(sectionMap))
+	for k, v := range sectionMap {
+		result[k] = v
+	}
+	return result
+}
+
+// ForEachInSection calls fn for each key of section with its effective
+// value, in no particular order, until fn returns false.
+func (s *Snapshot) ForEachInSection(section string, fn func(key, value string) bool) {
+	for key, value := range s.view().sections[section] {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// GetSections returns the names of all sections.
+func (s *Snapshot) GetSections() []string {
+	sections := s.view().sections
+	names := make([]string, 0, len(sections))
+	for section := range sections {
+		names = append(names, section)
+	}
+	return names
+}
+
+// GetKeys returns the fully-qualified dotted names of all keys.
+func (s *Snapshot) GetKeys() []string {
+	var keys []string
+	for section, sectionMap := range s.view().sections {
+		for key := range sectionMap {
+			keys = append(keys, section+"."+key)
+		}
+	}
+	return keys
+}
+
+// GetMultiValue returns all values of a multi-valued key in the order they
+// were loaded.
+func (s *Snapshot) GetMultiValue(key string) ([]string, error) {
+	values, err := s.view().multiValues(key)
+	if err != nil {
+		return nil, err
+	}
+	return valueStrings(values), nil
+}
+
+// Config returns a mutable copy of the snapshot.
+func (s *Snapshot) Config() *Config {
+	return s.view().Clone()
+}
+
+// HasChanged reports whether the configuration differs from the snapshot,
+// using the same semantics as Equal. A nil snapshot stands for an empty
+// config.
+func (c *Config) HasChanged(since *Snapshot) bool {
+	return !Diff(since.view(), c).IsEmpty()
+}
+
+// ChangedKeysSince returns the sorted, fully qualified names of all keys that
+// were added, removed or modified since the snapshot was taken. A nil
+// snapshot stands for an empty config.
+func (c *Config) ChangedKeysSince(since *Snapshot) []string {
+	diff := Diff(since.view(), c)
+
+	keys := make([]string, 0, len(diff.Added)+len(diff.Removed)+len(diff.Changed))
+	for _, entries := range [][]DiffEntry{diff.Added, diff.Removed, diff.Changed} {
+		for _, entry := range entries {
+			keys = append(keys, entry.Key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// WatchForChanges reloads the configuration every interval and sends the
+// keys changed since the last snapshot to ch. since is advanced to the new
+// state after every change that is reported, so it must not be used
+// concurrently by the caller. Failed reloads are skipped and retried on the
+// next tick, as files may be caught mid-write.
+//
+// WatchForChanges blocks until ctx is done; run it in its own goroutine.
+func (c *Config) WatchForChanges(ctx context.Context, interval time.Duration, since *Snapshot, ch chan<- []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.ReloadWithContext(ctx); err != nil {
+			continue
+		}
+
+		changed := c.ChangedKeysSince(since)
+		if len(changed) == 0 {
+			continue
+		}
+		*since = *c.Snapshot()
+
+		select {
+		case ch <- changed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Checksum returns a SHA-256 fingerprint of the keys and values, independent
+// of the order they were loaded or set in. Every value of a multi-valued key
+// contributes, in order; sources and line numbers do not.
+func (c *Config) Checksum() [32]byte {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	type entry struct{ section, key string }
+	var entries []entry
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			entries = append(entries, entry{section, key})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].section != entries[j].section {
+			return entries[i].section < entries[j].section
+		}
+		return entries[i].key < entries[j].key
+	})
+
+	h := sha256.New()
+	for _, e := range entries {
+		for _, v := range c.rawValues(e.section, e.key) {
+			h.Write([]byte(e.section + "\x00" + e.key + "\x00" + v.value + "\x00"))
+		}
+	}
+
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
@@ -0,0 +1,289 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func loadTestFile(t *testing.T, path string) *Config {
+	t.Helper()
+
+	source := ConfigSource{Type: SourceTypeLocal, Path: path}
+	config := &Config{sections: make(map[string]map[string]string)}
+	if err := newParser().parseConfigFile(source, config); err != nil {
+		t.Fatalf("Failed to parse %s: %v", path, err)
+	}
+	config.sources = append(config.sources, source)
+	return config
+}
+
+func TestConfigHasChanged(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n    name = Test User\n    email = test@example.com\n[core]\n    editor = vim\n")
+
+	snapshot := config.Snapshot()
+	if config.HasChanged(snapshot) {
+		t.Error("Expected no change right after Snapshot")
+	}
+	if keys := config.ChangedKeysSince(snapshot); len(keys) != 0 {
+		t.Errorf("Expected no changed keys, got %v", keys)
+	}
+
+	if err := config.Set("user.name", "Other User"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := config.Set("http.proxy", "http://proxy.example.com:8080"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if !config.HasChanged(snapshot) {
+		t.Error("Expected change after Set")
+	}
+
+	expected := []string{"http.proxy", "user.name"}
+	if keys := config.ChangedKeysSince(snapshot); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected %v, got %v", expected, keys)
+	}
+
+	if !config.HasChanged(nil) {
+		t.Error("Expected a change against a nil snapshot")
+	}
+	expected = []string{"core.editor", "http.proxy", "user.email", "user.name"}
+	if keys := config.ChangedKeysSince(nil); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected every key to be new against a nil snapshot, got %v", keys)
+	}
+}
+
+func TestConfigChangedKeysSinceReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("[user]\n    name = Test User\n[core]\n    editor = vim\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config := loadTestFile(t, path)
+	snapshot := config.Snapshot()
+
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if config.HasChanged(snapshot) {
+		t.Error("Expected no change after reloading an unchanged file")
+	}
+
+	if err := os.WriteFile(path, []byte("[user]\n    name = Test User\n[http]\n    proxy = http://proxy\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	expected := []string{"core.editor", "http.proxy"}
+	if keys := config.ChangedKeysSince(snapshot); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected %v, got %v", expected, keys)
+	}
+}
+
+func TestConfigWatchForChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("[user]\n    name = Test User\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config := loadTestFile(t, path)
+	snapshot := config.Snapshot()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan []string, 1)
+	done := make(chan struct{})
+	go func() {
+		config.WatchForChanges(ctx, 10*time.Millisecond, snapshot, ch)
+		close(done)
+	}()
+
+	// Several reloads of the unchanged file must not emit anything
+	select {
+	case keys := <-ch:
+		t.Fatalf("Expected no changes for an unchanged file, got %v", keys)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(path, []byte("[user]\n    name = Other User\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	select {
+	case keys := <-ch:
+		if !reflect.DeepEqual(keys, []string{"user.name"}) {
+			t.Errorf("Expected [user.name], got %v", keys)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for change")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestChecksum(t *testing.T) {
+	const data = `[user]
+    name = Test User
+    email = test@example.com
+[remote "origin"]
+    url = https://example.com/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*`
+
+	a := parseTestConfig(t, data)
+	b := parseTestConfig(t, data)
+	if a.Checksum() != b.Checksum() {
+		t.Error("Expected identical configs to have the same checksum")
+	}
+
+	before := a.Checksum()
+	if err := a.Set("user.email", "other@example.com"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if a.Checksum() == before {
+		t.Error("Expected checksum to change after modifying a key")
+	}
+
+	forward := &Config{}
+	backward := &Config{}
+	keys := []string{"user.name", "user.email", "core.editor", "remote.origin.url"}
+	for i, key := range keys {
+		if err := forward.Set(key, key+"-value"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		reverse := keys[len(keys)-1-i]
+		if err := backward.Set(reverse, reverse+"-value"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if forward.Checksum() != backward.Checksum() {
+		t.Error("Expected insertion order not to affect the checksum")
+	}
+
+	// Every value of a multi-valued key counts, not just the last
+	if err := b.addRawValue("remote.origin.fetch", "+refs/tags/*:refs/tags/*", nil, 0); err != nil {
+		t.Fatalf("addRawValue failed: %v", err)
+	}
+	if b.Checksum() == parseTestConfig(t, data).Checksum() {
+		t.Error("Expected an added multi-value to change the checksum")
+	}
+}
+
+func TestSnapshotReads(t *testing.T) {
+	config := parseTestConfig(t, "[core]\n    bare = false\n    compression = 9\n[remote \"origin\"]\n    url = https://example.com/a.git\n    fetch = +refs/heads/*:refs/remotes/origin/*\n    fetch = +refs/tags/*:refs/tags/*\n")
+	snapshot := config.Snapshot()
+
+	if err := config.Set("core.compression", "1"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if level, err := SnapshotGet[int](snapshot, "core.compression"); err != nil || level != 9 {
+		t.Errorf("SnapshotGet[int]() = %d, %v, want 9", level, err)
+	}
+	if bare := SnapshotGetWithDefault(snapshot, "core.bare", true); bare {
+		t.Error("SnapshotGetWithDefault(core.bare) = true, want false")
+	}
+	if _, err := SnapshotGet[bool](snapshot, "core.compression"); err == nil {
+		t.Error("SnapshotGet[bool](core.compression) succeeded, want error")
+	}
+	if _, err := SnapshotGet[string](snapshot, "core.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("SnapshotGet(core.missing) error = %v, want ErrKeyNotFound", err)
+	}
+	if url, ok := snapshot.GetStringOK("remote.origin.url"); !ok || url != "https://example.com/a.git" {
+		t.Errorf("GetStringOK() = %q, %v", url, ok)
+	}
+	if !snapshot.Has("remote.origin.url") || snapshot.Has("remote.origin.pushurl") {
+		t.Error("Has() mismatch")
+	}
+	if !snapshot.HasSection("remote.origin") {
+		t.Error("HasSection(remote.origin) = false")
+	}
+
+	fetch, err := snapshot.GetMultiValue("remote.origin.fetch")
+	if err != nil {
+		t.Fatalf("GetMultiValue() error = %v", err)
+	}
+	if want := []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"}; !reflect.DeepEqual(fetch, want) {
+		t.Errorf("GetMultiValue() = %v, want %v", fetch, want)
+	}
+
+	section := snapshot.GetSection("core")
+	section["bare"] = "true"
+	if value, _ := snapshot.GetStringOK("core.bare"); value != "false" {
+		t.Error("modifying GetSection result changed the snapshot")
+	}
+
+	var zero Snapshot
+	if _, err := SnapshotGet[string](&zero, "core.bare"); err == nil {
+		t.Error("SnapshotGet on zero Snapshot succeeded")
+	}
+	if len(zero.GetKeys()) != 0 {
+		t.Error("zero Snapshot has keys")
+	}
+}
+
+func TestSnapshotConcurrentPublish(t *testing.T) {
+	config := parseTestConfig(t, "[core]\n    compression = 0\n")
+
+	var current atomic.Pointer[Snapshot]
+	current.Store(config.Snapshot())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if _, err := SnapshotGet[int](current.Load(), "core.compression"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for level := 1; level <= 9; level++ {
+		if err := config.Set("core.compression", strconv.Itoa(level)); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		current.Store(config.Snapshot())
+	}
+	wg.Wait()
+
+	if level, _ := SnapshotGet[int](current.Load(), "core.compression"); level != 9 {
+		t.Errorf("latest snapshot compression = %d, want 9", level)
+	}
+}
+
+func BenchmarkSnapshotGetParallel(b *testing.B) {
+	snapshot := benchmarkReadConfig(b).Snapshot()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := SnapshotGet[string](snapshot, "remote.r100.url"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	config := benchmarkReadConfig(b)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := Get[string](config, "remote.r100.url"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package gitcfg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GetSparseCheckoutConfig returns the sparse-checkout settings. Each is false
+// when unset.
+func (c *Config) GetSparseCheckoutConfig() (*SparseCheckoutConfig, error) {
+	cfg := &SparseCheckoutConfig{}
+
+	r := &fieldReader{c: c}
+	readField(r, CoreSparseCheckout, &cfg.Enabled)
+	readField(r, CoreSparseCheckoutCone, &cfg.Cone)
+	readField(r, IndexSparse, &cfg.IndexSparse)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return cfg, nil
+}
+
+// GetSparseCheckoutPatterns reads the patterns of info/sparse-checkout in
+// the git directory, or in the worktree's own git directory when its
+// config.worktree is loaded. Blank lines and comments are left out. A
+// missing file means no patterns, which is not an error. It fails with an
+// error wrapping ErrSectionNotFound when no local config is loaded.
+func (c *Config) GetSparseCheckoutPatterns() ([]string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	dir := c.sparseCheckoutDir()
+	if dir == "" {
+		return nil, &ConfigError{
+			Op:  "get",
+			Err: fmt.Errorf("%w: no local config loaded", ErrSectionNotFound),
+		}
+	}
+
+	patterns := make([]string, 0)
+
+	path := filepath.Join(dir, "info", "sparse-checkout")
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return patterns, nil
+	} else if err != nil {
+		return nil, &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    err,
+		}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &ConfigError{
+			Op:     "get",
+			Source: path,
+			Err:    err,
+		}
+	}
+
+	return patterns, nil
+}
+
+// sparseCheckoutDir returns the git directory holding info/sparse-checkout:
+// that of config.worktree, which is per worktree, or else that of the local
+// config.
+func (c *Config) sparseCheckoutDir() string {
+	for _, source := range c.GetSources() {
+		if source.Type == SourceTypeWorktree && source.Path != "" {
+			return filepath.Dir(source.Path)
+		}
+	}
+	return c.gitDir()
+}
@@ -0,0 +1,79 @@
+package gitcfg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetSparseCheckoutConfig(t *testing.T) {
+	config := parseTestConfig(t, `[core]
+    sparseCheckout = true
+    sparseCheckoutCone
+[index]
+    sparse = false
+`)
+
+	cfg, err := config.GetSparseCheckoutConfig()
+	if err != nil {
+		t.Fatalf("GetSparseCheckoutConfig failed: %v", err)
+	}
+	expected := SparseCheckoutConfig{Enabled: true, Cone: true, IndexSparse: false}
+	if *cfg != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *cfg)
+	}
+
+	config = parseTestConfig(t, "[core]\n    sparseCheckout = maybe\n")
+	if _, err := config.GetSparseCheckoutConfig(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestGetSparseCheckoutPatterns(t *testing.T) {
+	gitDir := t.TempDir()
+	config := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  []ConfigSource{{Type: SourceTypeLocal, Path: filepath.Join(gitDir, "config")}},
+	}
+
+	patterns, err := config.GetSparseCheckoutPatterns()
+	if err != nil || patterns == nil || len(patterns) != 0 {
+		t.Fatalf("GetSparseCheckoutPatterns() without a file = %v, %v, want empty", patterns, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(gitDir, "info"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "# written by git sparse-checkout\n/*\n!/*/\n\n/services/api/\r\n/libs/\n"
+	if err := os.WriteFile(filepath.Join(gitDir, "info", "sparse-checkout"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns, err = config.GetSparseCheckoutPatterns()
+	if err != nil {
+		t.Fatalf("GetSparseCheckoutPatterns() error = %v", err)
+	}
+	expected := []string{"/*", "!/*/", "/services/api/", "/libs/"}
+	if !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("Expected %q, got %q", expected, patterns)
+	}
+
+	// A linked worktree keeps its patterns in its own git directory
+	worktreeDir := filepath.Join(gitDir, "worktrees", "feature")
+	if err := os.MkdirAll(filepath.Join(worktreeDir, "info"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreeDir, "info", "sparse-checkout"), []byte("/docs/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config.sources = append(config.sources, ConfigSource{Type: SourceTypeWorktree, Path: filepath.Join(worktreeDir, "config.worktree")})
+	if patterns, _ := config.GetSparseCheckoutPatterns(); !reflect.DeepEqual(patterns, []string{"/docs/"}) {
+		t.Errorf("Expected the worktree's patterns, got %q", patterns)
+	}
+
+	if _, err := parseTestConfig(t, "").GetSparseCheckoutPatterns(); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound without a local config, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package gitcfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultSSHCommand is the command git runs when nothing overrides it.
+const DefaultSSHCommand = "ssh"
+
+// GetSSHCommand returns the ssh command git would run to reach remote, in
+// git's order of precedence: the GIT_SSH_COMMAND environment variable, then
+// core.sshCommand, then the GIT_SSH program, then DefaultSSHCommand. The
+// command is returned as written and is meant for a shell; SplitCommand
+// turns it into an argv. GIT_SSH names a single program, so it is returned
+// quoted when needed.
+//
+// Git has no per-remote ssh setting, so the result is the same for every
+// remote; a non-empty remote must however be configured, otherwise an error
+// wrapping ErrSectionNotFound is returned.
+func (c *Config) GetSSHCommand(remote string) (string, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return "", err
+	}
+
+	if remote != "" && !c.HasSection("remote."+remote) {
+		return "", &ConfigError{
+			Op:      "get",
+			Section: "remote." + remote,
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	if command, ok := c.getenv("GIT_SSH_COMMAND"); ok && command != "" {
+		return command, nil
+	}
+
+	command, err := Get[string](c, CoreSSHCommand)
+	if err == nil && command != "" {
+		return command, nil
+	}
+	if err != nil && !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrSectionNotFound) {
+		return "", err
+	}
+
+	if program, ok := c.getenv("GIT_SSH"); ok && program != "" {
+		return shellQuote(program), nil
+	}
+
+	return DefaultSSHCommand, nil
+}
+
+// SplitCommand splits a shell command line such as a GetSSHCommand result
+// into its arguments, honoring single quotes, double quotes and backslash
+// escapes. Variables, globs and other shell syntax are left as they are.
+func SplitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes a few characters
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("%w: unterminated quote or escape in %q", ErrInvalidValue, command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything special.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,82 @@
+package gitcfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetSSHCommand(t *testing.T) {
+	configured := `[core]
+    sshCommand = ssh -i ~/.ssh/deploy_key
+[remote "origin"]
+    url = git@github.com:user/repo.git`
+	unconfigured := `[remote "origin"]
+    url = git@github.com:user/repo.git`
+
+	tests := []struct {
+		name   string
+		config string
+		env    map[string]string
+		want   string
+	}{
+		{"default", unconfigured, nil, "ssh"},
+		{"core.sshCommand", configured, nil, "ssh -i ~/.ssh/deploy_key"},
+		{"GIT_SSH_COMMAND before config", configured, map[string]string{"GIT_SSH_COMMAND": "ssh -v"}, "ssh -v"},
+		{"config before GIT_SSH", configured, map[string]string{"GIT_SSH": "/usr/bin/plink"}, "ssh -i ~/.ssh/deploy_key"},
+		{"GIT_SSH", unconfigured, map[string]string{"GIT_SSH": "/opt/my ssh/bin/ssh"}, "'/opt/my ssh/bin/ssh'"},
+		{"empty GIT_SSH_COMMAND ignored", configured, map[string]string{"GIT_SSH_COMMAND": ""}, "ssh -i ~/.ssh/deploy_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := parseTestConfig(t, tt.config)
+			config.lookupEnv = envMap(tt.env)
+
+			got, err := config.GetSSHCommand("origin")
+			if err != nil {
+				t.Fatalf("GetSSHCommand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetSSHCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	config := parseTestConfig(t, configured)
+	if _, err := config.GetSSHCommand("missing"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("GetSSHCommand(missing) error = %v, want ErrSectionNotFound", err)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ssh", []string{"ssh"}},
+		{"  ssh   -i key  ", []string{"ssh", "-i", "key"}},
+		{`ssh -o "ProxyCommand nc %h %p"`, []string{"ssh", "-o", "ProxyCommand nc %h %p"}},
+		{`'/opt/my ssh/bin/ssh' -v`, []string{"/opt/my ssh/bin/ssh", "-v"}},
+		{`ssh -i my\ key`, []string{"ssh", "-i", "my key"}},
+		{`echo "a \"b\" \n" ''`, []string{"echo", `a "b" \n`, ""}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := SplitCommand(tt.command)
+		if err != nil {
+			t.Errorf("SplitCommand(%q) error = %v", tt.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+
+	for _, command := range []string{`ssh "unterminated`, `ssh 'x`, `ssh \`} {
+		if _, err := SplitCommand(command); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("SplitCommand(%q) error = %v, want ErrInvalidValue", command, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package gitcfg
+
+import (
+	"os"
+	"time"
+)
+
+// SourceStat describes what one source contributed to a loaded config and
+// what reading it cost.
+//
+// With WithGitCommand, ByteSize and ModTime describe the file on disk, and
+// ParseDuration is the time of the git invocation that listed the source,
+// shared by the files a scope includes.
+type SourceStat struct {
+	Path          string
+	Type          ConfigSourceType
+	KeyCount      int           // values the source set, counting each value of a multi-valued key
+	ByteSize      int64         // size of the config text
+	ModTime       time.Time     // modification time of the file, zero for blobs
+	ParseDuration time.Duration // time spent reading and parsing the source
+}
+
+// SourceStats returns the statistics captured for each source while the
+// config was loaded, in load order. Reload refreshes them. Sources added
+// afterwards, e.g. by Import or Merge, have no statistics.
+func (c *Config) SourceStats() []SourceStat {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	stats := make([]SourceStat, len(c.stats))
+	copy(stats, c.stats)
+	return stats
+}
+
+// fileStat returns the statistics of a config file parsed since start, after
+// values values and size bytes were read from it.
+func fileStat(source ConfigSource, values int, size int64, start time.Time) SourceStat {
+	stat := SourceStat{
+		Path:          source.Path,
+		Type:          source.Type,
+		KeyCount:      values,
+		ByteSize:      size,
+		ParseDuration: time.Since(start),
+	}
+	if info, err := os.Stat(source.Path); err == nil {
+		stat.ModTime = info.ModTime()
+	}
+	return stat
+}
+
+// countSourceValues fills in the KeyCount of stats from the values config
+// records for each source. Callers must hold the lock or own config.
+func countSourceValues(config *Config, stats []SourceStat) {
+	counts := make(map[ConfigSource]int)
+	for _, valueMap := range config.values {
+		for _, values := range valueMap {
+			for _, v := range values {
+				if v.source != nil {
+					counts[*v.source]++
+				}
+			}
+		}
+	}
+	for i := range stats {
+		stats[i].KeyCount = counts[ConfigSource{Type: stats[i].Type, Path: stats[i].Path}]
+	}
+}
+
+// statsBySource indexes stats for rendering the source header.
+func statsBySource(stats []SourceStat) map[ConfigSource]SourceStat {
+	if len(stats) == 0 {
+		return nil
+	}
+	index := make(map[ConfigSource]SourceStat, len(stats))
+	for _, stat := range stats {
+		index[ConfigSource{Type: stat.Type, Path: stat.Path}] = stat
+	}
+	return index
+}
@@ -0,0 +1,106 @@
+package gitcfg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceStats(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	file := filepath.Join(t.TempDir(), "extra.cfg")
+	writeTestFile(t, file, "[user]\n\tname = Test User\n[remote \"origin\"]\n\tfetch = a\n\tfetch = b\n")
+
+	config, err := Load(WithFile(file))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	stats := config.SourceStats()
+	if len(stats) != 1 {
+		t.Fatalf("Expected 1 source stat, got %+v", stats)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := stats[0]
+	if stat.Path != file || stat.Type != SourceTypeCustom {
+		t.Errorf("Expected custom source %s, got %s %s", file, stat.Type, stat.Path)
+	}
+	if stat.KeyCount != 3 {
+		t.Errorf("Expected 3 values, got %d", stat.KeyCount)
+	}
+	if stat.ByteSize != info.Size() {
+		t.Errorf("Expected %d bytes, got %d", info.Size(), stat.ByteSize)
+	}
+	if !stat.ModTime.Equal(info.ModTime()) {
+		t.Errorf("Expected mod time %v, got %v", info.ModTime(), stat.ModTime)
+	}
+	if stat.ParseDuration < 0 {
+		t.Errorf("Expected a non-negative parse duration, got %v", stat.ParseDuration)
+	}
+
+	if !strings.Contains(config.String(), "# file: "+file+" (3 keys)\n") {
+		t.Errorf("Expected the source header to include the key count, got:\n%s", config.String())
+	}
+
+	clone := config.Clone()
+	writeTestFile(t, file, "[user]\n\tname = Test User\n")
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if stats := config.SourceStats(); len(stats) != 1 || stats[0].KeyCount != 1 {
+		t.Errorf("Expected Reload to refresh the stats, got %+v", stats)
+	}
+	if !strings.Contains(config.String(), "(1 key)\n") {
+		t.Errorf("Expected a singular key count, got:\n%s", config.String())
+	}
+	if stats := clone.SourceStats(); len(stats) != 1 || stats[0].KeyCount != 3 {
+		t.Errorf("Expected the clone to keep its stats, got %+v", stats)
+	}
+
+	config.SourceStats()[0].KeyCount = 100
+	if stats := config.SourceStats(); stats[0].KeyCount != 1 {
+		t.Error("SourceStats returned the config's own slice")
+	}
+}
+
+func TestSourceStatsGitCommand(t *testing.T) {
+	repo, file := setupScopeRepo(t)
+
+	opts := []ConfigOption{WithGlobal(), WithLocal(), WithWorktree(), WithRepoPath(repo), WithFile(file)}
+	fromFiles, err := Load(opts...)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	fromGit, err := Load(append(opts, WithGitCommand())...)
+	if err != nil {
+		t.Fatalf("Load with git command failed: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, stat := range fromFiles.SourceStats() {
+		counts[stat.Path] = stat.KeyCount
+	}
+	gitStats := fromGit.SourceStats()
+	if len(gitStats) != len(counts) {
+		t.Fatalf("Expected %d sources, got %+v", len(counts), gitStats)
+	}
+	for _, stat := range gitStats {
+		if stat.KeyCount != counts[stat.Path] {
+			t.Errorf("%s: expected %d values, got %d", stat.Path, counts[stat.Path], stat.KeyCount)
+		}
+		info, err := os.Stat(stat.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat.ByteSize != info.Size() || !stat.ModTime.Equal(info.ModTime()) {
+			t.Errorf("%s: expected size %d and mod time %v, got %d and %v",
+				stat.Path, info.Size(), info.ModTime(), stat.ByteSize, stat.ModTime)
+		}
+	}
+}
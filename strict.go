@@ -0,0 +1,119 @@
+package gitcfg
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	knownKeysMu sync.RWMutex
+	knownKeys   = newKeyPatterns(defaultKnownKeys...)
+)
+
+// defaultKnownKeys are the keys accepted by WithStrictMode out of the box:
+// the keys in types.go and those read by the remote, branch, submodule and
+// URL-scoped accessors, plus what git init writes. A "*" subsection matches
+// any subsection and a "*" key any key of the section.
+var defaultKnownKeys = []string{
+	CommitGPGSign, CommitTemplate, CommitCleanup, CommitStatus, CommitVerbose, CommitAuthor,
+	StatusShort, StatusBranch, StatusAheadBehind, StatusShowStash, StatusShowUntrackedFiles,
+	StatusSubmoduleSummary, StatusRenameLimit,
+	TagGPGSign, TagSort, TagForceSignAnnotated,
+	NotesDisplayRef, NotesRewriteRef, NotesRewrite + ".*",
+	AMKeepCR, AMThreeWay, AMSignoff, ApplyIgnoreWhitespace, ApplyWhitespace, ApplyStat,
+	UserName, UserEmail, UserSigningKey, UserUseConfigOnly,
+	GPGFormat, GPGProgram, GPGSSHProgram, GPGSSHAllowedSignersFile, GPGSSHDefaultKeyCommand,
+	CredentialHelper, CredentialUseHTTPPath, CredentialUsername,
+	"credential.*.helper", "credential.*.usehttppath", "credential.*.username",
+	LFSURL, LFSBatch, LFSConcurrentTransfers, LFSTLS, LFSLocksVerify, LFSDialTimeout,
+	LFSKeepAlive, LFSTLSTimeout, LFSActivityTimeout, LFSSSLNoVerify, LFSObjectStorageURL,
+	MergeTool, MergeConflictStyle, MergeFF, DiffTool, DiffAlgorithm, DiffRenames, DiffColorMoved,
+	"mergetool.*.cmd", "difftool.*.cmd",
+	FetchPrune, FetchPruneTags, FetchParallel, FetchRecurseSubmodules,
+	PullRebase, PullFF, PushDefault, PushAutoSetupRemote, PushFollowTags, PushGPGSign,
+	InitDefaultBranch, InitTemplateDir,
+	CoreEditor, CorePager, CoreAutoCRLF, CoreFileMode, CoreIgnoreCase, CoreBare,
+	CoreExcludesFile, CoreAttributesFile, CoreHooksPath, CoreWorktree, CoreSSHCommand,
+	CoreSparseCheckout, CoreSparseCheckoutCone, IndexSparse,
+	"core.repositoryformatversion", "core.logallrefupdates", "core.symlinks", "core.precomposeunicode",
+	SafeDirectory,
+	AdviceSection + ".*", MaintenanceAuto, MaintenanceStrategy, MaintenanceRepo,
+	HTTPSSLVerify, HTTPSSLCAInfo, HTTPSSLCert, HTTPSSLKey, HTTPProxy, HTTPPostBuffer,
+	HTTPLowSpeedLimit, HTTPLowSpeedTime, HTTPExtraHeader, HTTPCookieFile, HTTPUserAgent,
+	"http.*.sslverify", "http.*.sslcainfo", "http.*.sslcert", "http.*.sslkey", "http.*.proxy",
+	"http.*.postbuffer", "http.*.lowspeedlimit", "http.*.lowspeedtime", "http.*.extraheader",
+	"http.*.cookiefile", "http.*.useragent", HTTPProxyAuthMethod, "http.*.proxyauthmethod",
+	CommitGraphGenerationVersion, CommitGraphReadChangedPaths, FetchWriteCommitGraph, GCWriteCommitGraph,
+	SendEmailIdentity, SendEmailSMTPServer, SendEmailSMTPUser, SendEmailSMTPEncryption,
+	SendEmailSMTPServerPort, SendEmailFrom, SendEmailTo, SendEmailCc, SendEmailBcc,
+	"sendemail.*.smtpserver", "sendemail.*.smtpuser", "sendemail.*.smtpencryption",
+	"sendemail.*.smtpserverport", "sendemail.*.from", "sendemail.*.to", "sendemail.*.cc", "sendemail.*.bcc",
+	GitFlowBranchMaster, GitFlowBranchDevelop, GitFlowPrefixFeature, GitFlowPrefixBugfix,
+	GitFlowPrefixRelease, GitFlowPrefixHotfix, GitFlowPrefixSupport, GitFlowPrefixVersionTag,
+	"remote.*.url", "remote.*.pushurl", "remote.*.fetch", "remote.*.push", "remote.*.proxy",
+	"remote.*.mirror", "remote.*.skipdefaultupdate", "remote.*.tagopt", "remote.*.receivepack",
+	"remote.*.uploadpack", "remote.*.proxyauthmethod",
+	"branch.*.remote", "branch.*.pushremote", "branch.*.merge", "branch.*.rebase", "branch.*.description",
+	"submodule.*.path", "submodule.*.url", "submodule.*.branch", "submodule.*.update",
+	"submodule.*.ignore", "submodule.*.shallow",
+	"filter.*.clean", "filter.*.smudge", "filter.*.process", "filter.*.required",
+	"url.*.insteadof", "url.*.pushinsteadof",
+	"include.path", "includeif.*.path",
+	NamespaceGit, CoreObjectsDirectory, ObjectsAlternates,
+}
+
+// RegisterKnownKey adds a key to those accepted by WithStrictMode. Use "*"
+// as the subsection to accept the key in every subsection, e.g.
+// "remote.*.tagopt", or as the key name to accept a whole section, e.g.
+// "alias.*". It is safe for concurrent use and affects later loads only.
+func RegisterKnownKey(fullKey string) {
+	knownKeysMu.Lock()
+	defer knownKeysMu.Unlock()
+
+	knownKeys.add(fullKey)
+}
+
+// isKnownKey reports whether fullKey, or a pattern covering it, has been
+// registered.
+func isKnownKey(fullKey string) bool {
+	section, key, err := parseConfigKey(fullKey)
+	if err != nil {
+		return false
+	}
+
+	knownKeysMu.RLock()
+	defer knownKeysMu.RUnlock()
+
+	return knownKeys.match(section, key)
+}
+
+// keyPatterns is a set of dotted keys in which "*" as the subsection matches
+// any subsection and "*" as the key name any key of the section.
+type keyPatterns map[string]bool
+
+func newKeyPatterns(keys ...string) keyPatterns {
+	patterns := make(keyPatterns, len(keys))
+	for _, key := range keys {
+		patterns.add(key)
+	}
+	return patterns
+}
+
+func (p keyPatterns) add(fullKey string) {
+	if section, key, err := parseConfigKey(fullKey); err == nil {
+		fullKey = section + "." + key
+	}
+	p[fullKey] = true
+}
+
+// match reports whether the key, split and normalized by parseConfigKey, is
+// in the set.
+func (p keyPatterns) match(section, key string) bool {
+	if p[section+"."+key] || p[section+".*"] {
+		return true
+	}
+	if name, _, ok := strings.Cut(section, "."); ok {
+		return p[name+".*."+key]
+	}
+	return false
+}
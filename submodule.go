@@ -0,0 +1,149 @@
+package gitcfg
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// GetSubmodules returns every submodule.<name> section sorted by name.
+func (c *Config) GetSubmodules() ([]Submodule, error) {
+	return c.GetSubmodulesWithContext(context.Background())
+}
+
+// GetSubmodulesWithContext is like GetSubmodules but stops with ctx.Err()
+// once ctx is done, checking every ContextCheckInterval submodules.
+func (c *Config) GetSubmodulesWithContext(ctx context.Context) ([]Submodule, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	names := c.SubsectionNames("submodule")
+
+	submodules := make([]Submodule, 0, len(names))
+	for i, name := range names {
+		if err := checkContext(ctx, i); err != nil {
+			return nil, err
+		}
+		submodule := Submodule{Name: name}
+		if err := c.readSubmodule(&submodule); err != nil {
+			return nil, err
+		}
+		submodules = append(submodules, submodule)
+	}
+	return submodules, nil
+}
+
+// readSubmodule fills the fields of s that are set in the receiver, leaving
+// the others untouched so that configs can be layered.
+func (c *Config) readSubmodule(s *Submodule) error {
+	section := "submodule." + s.Name
+
+	r := &fieldReader{c: c}
+	readField(r, section+".path", &s.Path)
+	readField(r, section+".url", &s.URL)
+	readField(r, section+".branch", &s.Branch)
+	readField(r, section+".update", &s.Update)
+	readField(r, section+".ignore", &s.Ignore)
+	readField(r, section+".shallow", &s.Shallow)
+	return r.err
+}
+
+// LoadGitmodules parses the .gitmodules file at the root of repoPath. Its
+// values are recorded with a SourceTypeGitmodules source.
+func LoadGitmodules(repoPath string) (*Config, error) {
+	source := ConfigSource{
+		Type: SourceTypeGitmodules,
+		Path: filepath.Join(repoPath, ".gitmodules"),
+	}
+
+	config := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0, 1),
+	}
+	if err := newParser().parseConfigFile(source, config); err != nil {
+		return nil, err
+	}
+	config.sources = append(config.sources, source)
+
+	return config, nil
+}
+
+// MergeSubmoduleConfig computes the effective settings of the submodules
+// defined in gitmodules. As in git, submodule.<name>.* entries in repo (the
+// repository's .git/config) override .gitmodules, except for the path, which
+// only .gitmodules defines. Relative URLs (./ or ../) are resolved against
+// repo's remote.origin.url when it is set. repo may be nil.
+func MergeSubmoduleConfig(gitmodules, repo *Config) ([]Submodule, error) {
+	submodules, err := gitmodules.GetSubmodules()
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return submodules, nil
+	}
+
+	originURL := GetWithDefault(repo, "remote.origin.url", "")
+	for i := range submodules {
+		path := submodules[i].Path
+		if err := repo.readSubmodule(&submodules[i]); err != nil {
+			return nil, err
+		}
+		submodules[i].Path = path
+
+		if url := submodules[i].URL; originURL != "" && isRelativeURL(url) {
+			submodules[i].URL = resolveRelativeURL(originURL, url)
+		}
+	}
+
+	return submodules, nil
+}
+
+func isRelativeURL(url string) bool {
+	return strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../")
+}
+
+// resolveRelativeURL resolves a submodule URL like ../lib.git against the
+// superproject's remote URL the way git submodule does: each ../ strips one
+// path component from the base. scp-like bases (git@host:path) are handled
+// by treating the colon as a separator.
+func resolveRelativeURL(base, rel string) string {
+	base = strings.TrimSuffix(base, "/")
+
+	for {
+		if rest, ok := strings.CutPrefix(rel, "./"); ok {
+			rel = rest
+			continue
+		}
+		rest, ok := strings.CutPrefix(rel, "../")
+		if !ok {
+			break
+		}
+		rel = rest
+
+		sep := strings.LastIndexByte(base, '/')
+		if scheme := strings.Index(base, "://"); scheme >= 0 {
+			// Never strip the host of a scheme URL
+			if sep < scheme+len("://") {
+				continue
+			}
+		} else if colon := strings.LastIndexByte(base, ':'); colon > sep {
+			sep = colon
+		}
+
+		switch {
+		case sep < 0:
+			base = "."
+		case base[sep] == ':':
+			base = base[:sep+1]
+		default:
+			base = base[:sep]
+		}
+	}
+
+	if strings.HasSuffix(base, ":") {
+		return base + rel
+	}
+	return fmt.Sprintf("%s/%s", base, rel)
+}
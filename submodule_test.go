@@ -0,0 +1,124 @@
+package gitcfg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const gitmodulesTestData = `[submodule "lib"]
+    path = vendor/lib
+    url = ../lib.git
+    branch = main
+[submodule "docs"]
+    path = docs
+    url = https://github.com/user/docs.git
+    update = rebase
+    ignore = dirty
+    shallow = true
+`
+
+func TestGetSubmodules(t *testing.T) {
+	config := parseTestConfig(t, gitmodulesTestData)
+
+	submodules, err := config.GetSubmodules()
+	if err != nil {
+		t.Fatalf("GetSubmodules failed: %v", err)
+	}
+
+	expected := []Submodule{
+		{Name: "docs", Path: "docs", URL: "https://github.com/user/docs.git", Update: "rebase", Ignore: "dirty", Shallow: true},
+		{Name: "lib", Path: "vendor/lib", URL: "../lib.git", Branch: "main"},
+	}
+	if !reflect.DeepEqual(submodules, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, submodules)
+	}
+}
+
+func TestLoadGitmodules(t *testing.T) {
+	repoPath := t.TempDir()
+	path := filepath.Join(repoPath, ".gitmodules")
+	if err := os.WriteFile(path, []byte(gitmodulesTestData), 0644); err != nil {
+		t.Fatalf("Failed to write .gitmodules: %v", err)
+	}
+
+	config, err := LoadGitmodules(repoPath)
+	if err != nil {
+		t.Fatalf("LoadGitmodules failed: %v", err)
+	}
+
+	expected := []ConfigSource{{Type: SourceTypeGitmodules, Path: path}}
+	if sources := config.GetSources(); !reflect.DeepEqual(sources, expected) {
+		t.Errorf("Expected sources %v, got %v", expected, sources)
+	}
+	if _, source, err := config.GetWithSource("submodule.lib.url"); err != nil || source.Type != SourceTypeGitmodules {
+		t.Errorf("Expected gitmodules source, got %v (%v)", source, err)
+	}
+	if SourceTypeGitmodules.String() != "gitmodules" {
+		t.Errorf("Unexpected source type name %s", SourceTypeGitmodules)
+	}
+
+	if _, err := LoadGitmodules(t.TempDir()); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist for missing .gitmodules, got %v", err)
+	}
+}
+
+func TestMergeSubmoduleConfig(t *testing.T) {
+	gitmodules := parseTestConfig(t, gitmodulesTestData)
+	repo := parseTestConfig(t, `[remote "origin"]
+    url = https://github.com/user/project.git
+[submodule "docs"]
+    url = https://mirror.example.com/docs.git
+    path = ignored
+    active = true
+`)
+
+	submodules, err := MergeSubmoduleConfig(gitmodules, repo)
+	if err != nil {
+		t.Fatalf("MergeSubmoduleConfig failed: %v", err)
+	}
+
+	expected := []Submodule{
+		{Name: "docs", Path: "docs", URL: "https://mirror.example.com/docs.git", Update: "rebase", Ignore: "dirty", Shallow: true},
+		{Name: "lib", Path: "vendor/lib", URL: "https://github.com/user/lib.git", Branch: "main"},
+	}
+	if !reflect.DeepEqual(submodules, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, submodules)
+	}
+
+	// Without a repository config the .gitmodules values are used as is
+	submodules, err = MergeSubmoduleConfig(gitmodules, nil)
+	if err != nil {
+		t.Fatalf("MergeSubmoduleConfig failed: %v", err)
+	}
+	if submodules[1].URL != "../lib.git" {
+		t.Errorf("Expected unresolved URL, got %s", submodules[1].URL)
+	}
+}
+
+func TestResolveRelativeURL(t *testing.T) {
+	tests := []struct {
+		base     string
+		rel      string
+		expected string
+	}{
+		{"https://github.com/user/project.git", "../lib.git", "https://github.com/user/lib.git"},
+		{"https://github.com/user/project.git/", "../lib.git", "https://github.com/user/lib.git"},
+		{"https://github.com/user/project.git", "./lib.git", "https://github.com/user/project.git/lib.git"},
+		{"https://github.com/user/project.git", "../../other/lib.git", "https://github.com/other/lib.git"},
+		{"https://github.com", "../lib.git", "https://github.com/lib.git"},
+		{"https://host:8080/project", "../lib", "https://host:8080/lib"},
+		{"git@github.com:user/project.git", "../lib.git", "git@github.com:user/lib.git"},
+		{"git@github.com:project.git", "../lib.git", "git@github.com:lib.git"},
+		{"/srv/git/project.git", "../lib.git", "/srv/git/lib.git"},
+	}
+
+	for _, test := range tests {
+		if result := resolveRelativeURL(test.base, test.rel); result != test.expected {
+			t.Errorf("resolveRelativeURL(%q, %q) = %q, expected %q", test.base, test.rel, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package gitcfg
+
+import (
+	"bytes"
+)
+
+// TextSourcePath is the source path of values read by UnmarshalText.
+const TextSourcePath = "<text>"
+
+// MarshalText encodes the configuration in git config format, as written by
+// PrintTo with OutputFormatINI: sorted, with every value of multi-valued
+// keys and quoted where git needs it. Unlike PrintTo it never redacts, so the
+// text can be stored and decoded with UnmarshalText without loss. Sources
+// are not part of the text; comments kept by WithPreserveComments are, each
+// before the section or key it preceded.
+func (c *Config) MarshalText() ([]byte, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	c.rlock()
+	entries := c.entries(nil)
+	comments := c.comments
+	c.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := writeINI(&buf, entries, comments); err != nil {
+		return nil, &ConfigError{Op: "marshal", Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalText replaces the configuration with text in git config format.
+// The values are recorded with a SourceTypeCustom source whose path is
+// TextSourcePath. On error the receiver is left unchanged.
+func (c *Config) UnmarshalText(text []byte) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	decoded := &Config{
+		sections: make(map[string]map[string]string),
+		sources:  make([]ConfigSource, 0, 1),
+	}
+
+	source := ConfigSource{Type: SourceTypeCustom, Path: TextSourcePath}
+	if err := newParser().parseConfigReader(bytes.NewReader(text), decoded, source); err != nil {
+		return err
+	}
+	decoded.sources = append(decoded.sources, source)
+
+	c.lock()
+	c.sections = decoded.sections
+	c.values = decoded.values
+	c.sources = decoded.sources
+	c.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,636 @@
+package gitcfg
+
+import (
+	"strings"
+	"time"
+)
+
+// Well-known configuration keys.
+const (
+	CommitGPGSign  = "commit.gpgsign"
+	CommitTemplate = "commit.template"
+	CommitCleanup  = "commit.cleanup"
+	CommitStatus   = "commit.status"
+	CommitVerbose  = "commit.verbose"
+	CommitAuthor   = "commit.author"
+)
+
+// CommitConfig holds the commit.* settings used when creating commits.
+type CommitConfig struct {
+	GPGSign  bool   // commit.gpgSign, defaults to false
+	Template string // commit.template with ~ and environment variables expanded
+	Cleanup  string // commit.cleanup (strip, whitespace, verbatim, scissors, default)
+	Status   bool   // commit.status, defaults to true
+	Verbose  bool   // commit.verbose
+	Author   string // commit.author
+}
+
+const (
+	StatusShort              = "status.short"
+	StatusBranch             = "status.branch"
+	StatusAheadBehind        = "status.aheadbehind"
+	StatusShowStash          = "status.showstash"
+	StatusShowUntrackedFiles = "status.showuntrackedfiles"
+	StatusSubmoduleSummary   = "status.submodulesummary"
+	StatusRenameLimit        = "status.renamelimit"
+)
+
+// StatusConfig holds the status.* settings that shape `git status` output.
+type StatusConfig struct {
+	Short              bool   // status.short
+	Branch             bool   // status.branch
+	AheadBehind        bool   // status.aheadBehind, defaults to true
+	ShowStash          bool   // status.showStash
+	ShowUntrackedFiles string // status.showUntrackedFiles (no, normal, all), defaults to normal
+	SubmoduleSummary   int    // status.submoduleSummary: 0 disabled, -1 unlimited, otherwise a limit
+	RenameLimit        int    // status.renameLimit
+}
+
+const (
+	TagGPGSign            = "tag.gpgsign"
+	TagSort               = "tag.sort"
+	TagForceSignAnnotated = "tag.forcesignannotated"
+)
+
+// TagConfig holds the tag.* settings.
+type TagConfig struct {
+	GPGSign            bool   // tag.gpgSign
+	Sort               string // tag.sort
+	ForceSignAnnotated bool   // tag.forceSignAnnotated
+}
+
+const (
+	NotesDisplayRef = "notes.displayref"
+	NotesRewriteRef = "notes.rewriteref"
+	NotesRewrite    = "notes.rewrite" // subsection holding per-command booleans, e.g. notes.rewrite.amend
+)
+
+// NoteConfig holds the notes.* settings.
+type NoteConfig struct {
+	DisplayRef []string          // every notes.displayRef value in order
+	Rewrite    map[string]string // notes.rewrite.<command> entries keyed by command
+	RewriteRef string            // notes.rewriteRef
+}
+
+const (
+	AMKeepCR              = "am.keepcr"
+	AMThreeWay            = "am.threeway"
+	AMSignoff             = "am.signoff"
+	ApplyIgnoreWhitespace = "apply.ignorewhitespace"
+	ApplyWhitespace       = "apply.whitespace"
+	ApplyStat             = "apply.stat"
+)
+
+// AMConfig holds the am.* settings used by `git am`.
+type AMConfig struct {
+	KeepCR   bool // am.keepcr
+	ThreeWay bool // am.threeWay
+	Signoff  bool // am.signoff
+}
+
+// ApplyConfig holds the apply.* settings used by `git apply`.
+type ApplyConfig struct {
+	IgnoreWhitespace bool   // apply.ignoreWhitespace
+	Whitespace       string // apply.whitespace (nowarn, warn, fix, error, error-all)
+	Stat             bool   // apply.stat
+}
+
+// WhitespaceAction is the parsed form of apply.whitespace.
+type WhitespaceAction int
+
+const (
+	WhitespaceUnset WhitespaceAction = iota
+	WhitespaceNoWarn
+	WhitespaceWarn
+	WhitespaceFix
+	WhitespaceError
+	WhitespaceErrorAll
+	WhitespaceUnknown
+)
+
+func (a WhitespaceAction) String() string {
+	switch a {
+	case WhitespaceUnset:
+		return ""
+	case WhitespaceNoWarn:
+		return "nowarn"
+	case WhitespaceWarn:
+		return "warn"
+	case WhitespaceFix:
+		return "fix"
+	case WhitespaceError:
+		return "error"
+	case WhitespaceErrorAll:
+		return "error-all"
+	default:
+		return "unknown"
+	}
+}
+
+// WhitespaceAction maps apply.whitespace to a WhitespaceAction. "strip" is
+// accepted as git's synonym for fix.
+func (a *ApplyConfig) WhitespaceAction() WhitespaceAction {
+	switch strings.ToLower(strings.TrimSpace(a.Whitespace)) {
+	case "":
+		return WhitespaceUnset
+	case "nowarn":
+		return WhitespaceNoWarn
+	case "warn":
+		return WhitespaceWarn
+	case "fix", "strip":
+		return WhitespaceFix
+	case "error":
+		return WhitespaceError
+	case "error-all":
+		return WhitespaceErrorAll
+	default:
+		return WhitespaceUnknown
+	}
+}
+
+const (
+	UserName          = "user.name"
+	UserEmail         = "user.email"
+	UserSigningKey    = "user.signingkey"
+	UserUseConfigOnly = "user.useconfigonly"
+)
+
+// Remote describes a remote.<name> section.
+type Remote struct {
+	Name              string
+	URL               string   // remote.<name>.url as configured
+	FetchURL          string   // URL after url.<base>.insteadOf rewriting
+	PushURL           string   // remote.<name>.pushurl, empty if unset
+	EffectivePushURL  string   // URL git pushes to, after insteadOf/pushInsteadOf rewriting
+	Fetch             []string // remote.<name>.fetch refspecs
+	Push              []string // remote.<name>.push refspecs
+	Proxy             string   // remote.<name>.proxy, empty if unset
+	Mirror            bool     // remote.<name>.mirror
+	SkipDefaultUpdate bool     // remote.<name>.skipDefaultUpdate
+	TagOpt            string   // remote.<name>.tagOpt, --tags or --no-tags; empty if unset
+}
+
+// MirrorConfig holds the remote.<name> settings used by mirror setups and by
+// tools that run git fetch or git push against a remote on their own.
+type MirrorConfig struct {
+	RemoteName        string
+	Mirror            bool   // remote.<name>.mirror
+	SkipDefaultUpdate bool   // remote.<name>.skipDefaultUpdate
+	ReceivePack       string // remote.<name>.receivepack, empty for git-receive-pack
+	UploadPack        string // remote.<name>.uploadpack, empty for git-upload-pack
+	TagOpt            string // remote.<name>.tagOpt: --tags, --no-tags or --follow-tags
+	ProxyAuthMethod   string // remote.<name>.proxyAuthMethod, empty if unset
+}
+
+const (
+	GPGFormat                = "gpg.format"
+	GPGProgram               = "gpg.program"
+	GPGSSHProgram            = "gpg.ssh.program"
+	GPGSSHAllowedSignersFile = "gpg.ssh.allowedsignersfile"
+	GPGSSHDefaultKeyCommand  = "gpg.ssh.defaultkeycommand"
+)
+
+// SigningField identifies a SigningConfig field in SigningConfig.Set.
+type SigningField uint16
+
+const (
+	SigningCommitGPGSign SigningField = 1 << iota
+	SigningTagGPGSign
+	SigningFormat
+	SigningProgram
+	SigningSSHProgram
+	SigningSSHAllowedSignersFile
+	SigningSSHDefaultKeyCommand
+	SigningKey
+)
+
+// SigningConfig holds the settings that control commit and tag signing.
+// Fields not present in the configuration carry git's defaults; Set records
+// which ones were configured explicitly.
+type SigningConfig struct {
+	CommitGPGSign         bool   // commit.gpgSign
+	TagGPGSign            bool   // tag.gpgSign
+	Format                string // gpg.format: openpgp (default), x509 or ssh
+	Program               string // gpg.program, default "gpg"
+	SSHProgram            string // gpg.ssh.program, default "ssh-keygen"
+	SSHAllowedSignersFile string // gpg.ssh.allowedSignersFile, expanded
+	SSHDefaultKeyCommand  string // gpg.ssh.defaultKeyCommand
+	SigningKey            string // user.signingKey
+	Set                   SigningField
+}
+
+// IsSet reports whether field was explicitly configured.
+func (s *SigningConfig) IsSet(field SigningField) bool {
+	return s.Set&field != 0
+}
+
+const (
+	CredentialHelper      = "credential.helper"
+	CredentialUseHTTPPath = "credential.usehttppath"
+	CredentialUsername    = "credential.username"
+)
+
+// CredentialConfig holds the credential.* settings that apply to a URL.
+type CredentialConfig struct {
+	Helpers     []string // effective helper list, in invocation order
+	UseHTTPPath bool     // credential.useHttpPath
+	Username    string   // credential.username
+}
+
+const (
+	LFSURL                 = "lfs.url"
+	LFSBatch               = "lfs.batch"
+	LFSConcurrentTransfers = "lfs.concurrenttransfers"
+	LFSTLS                 = "lfs.tls"
+	LFSLocksVerify         = "lfs.locksverify"
+	LFSDialTimeout         = "lfs.dialtimeout"
+	LFSKeepAlive           = "lfs.keepalive"
+	LFSTLSTimeout          = "lfs.tlstimeout"
+	LFSActivityTimeout     = "lfs.activitytimeout"
+	LFSSSLNoVerify         = "lfs.sslnoverify"
+	LFSObjectStorageURL    = "lfs.objectstorageurl.url"
+)
+
+// FilterDriver describes a filter.<name> section used by gitattributes.
+type FilterDriver struct {
+	Name     string
+	Clean    string // filter.<name>.clean
+	Smudge   string // filter.<name>.smudge
+	Process  string // filter.<name>.process
+	Required bool   // filter.<name>.required
+}
+
+// LFSConfig holds the Git LFS filter driver together with the lfs.* settings.
+// Timeouts are given in seconds or as Go durations such as "1m30s".
+type LFSConfig struct {
+	Filter              FilterDriver
+	URL                 string        // lfs.url
+	Batch               bool          // lfs.batch, default true
+	ConcurrentTransfers int           // lfs.concurrenttransfers, default 8; accepts k, m and g suffixes
+	TLS                 bool          // lfs.tls
+	LocksVerify         bool          // lfs.locksverify
+	DialTimeout         time.Duration // lfs.dialtimeout, default 30s
+	KeepAlive           time.Duration // lfs.keepalive, default 30m
+	TLSTimeout          time.Duration // lfs.tlstimeout, default 30s
+	ActivityTimeout     time.Duration // lfs.activitytimeout, default 30s
+	SSLNoVerify         bool          // lfs.sslnoverify
+	ObjectStorageURL    string        // url in the [lfs "objectstorageurl"] subsection
+}
+
+// Submodule describes a submodule.<name> section.
+type Submodule struct {
+	Name    string
+	Path    string // submodule.<name>.path
+	URL     string // submodule.<name>.url
+	Branch  string // submodule.<name>.branch
+	Update  string // submodule.<name>.update: checkout, rebase, merge, none or !command
+	Ignore  string // submodule.<name>.ignore: none, untracked, dirty or all
+	Shallow bool   // submodule.<name>.shallow
+}
+
+const (
+	MergeTool          = "merge.tool"
+	MergeConflictStyle = "merge.conflictstyle"
+	MergeFF            = "merge.ff"
+	DiffTool           = "diff.tool"
+	DiffAlgorithm      = "diff.algorithm"
+	DiffRenames        = "diff.renames"
+	DiffColorMoved     = "diff.colormoved"
+)
+
+// MergeConfig holds the merge.* settings.
+type MergeConfig struct {
+	Tool          string // merge.tool
+	ConflictStyle string // merge.conflictStyle: merge, diff3 or zdiff3
+	FF            string // merge.ff: true, false or only
+}
+
+// DiffConfig holds the diff.* settings.
+type DiffConfig struct {
+	Tool       string // diff.tool
+	Algorithm  string // diff.algorithm: default, myers, minimal, patience or histogram
+	Renames    string // diff.renames: true, false or copies
+	ColorMoved string // diff.colorMoved
+}
+
+// ToolCommand describes a mergetool.<name> or difftool.<name> section.
+type ToolCommand struct {
+	Name          string
+	Cmd           string // <kind>tool.<name>.cmd, empty for tools git knows natively
+	TrustExitCode bool   // <kind>tool.<name>.trustExitCode
+	Prompt        bool   // <kind>tool.prompt, default true
+}
+
+const (
+	FetchPrune             = "fetch.prune"
+	FetchPruneTags         = "fetch.prunetags"
+	FetchParallel          = "fetch.parallel"
+	FetchRecurseSubmodules = "fetch.recursesubmodules"
+	PullRebase             = "pull.rebase"
+	PullFF                 = "pull.ff"
+	PushDefault            = "push.default"
+	PushAutoSetupRemote    = "push.autosetupremote"
+	PushFollowTags         = "push.followtags"
+	PushGPGSign            = "push.gpgsign"
+)
+
+// FetchConfig holds the fetch.* settings.
+type FetchConfig struct {
+	Prune             bool   // fetch.prune
+	PruneTags         bool   // fetch.pruneTags
+	Parallel          int    // fetch.parallel, default 1; 0 picks a reasonable default
+	RecurseSubmodules string // fetch.recurseSubmodules: true, false or on-demand
+}
+
+// RebaseMode is the tri-state (plus variants) value of pull.rebase.
+type RebaseMode int
+
+const (
+	RebaseUnset RebaseMode = iota
+	RebaseFalse
+	RebaseTrue
+	RebaseMerges
+	RebaseInteractive
+)
+
+func (m RebaseMode) String() string {
+	switch m {
+	case RebaseFalse:
+		return "false"
+	case RebaseTrue:
+		return "true"
+	case RebaseMerges:
+		return "merges"
+	case RebaseInteractive:
+		return "interactive"
+	default:
+		return ""
+	}
+}
+
+// PullConfig holds the pull.* settings.
+type PullConfig struct {
+	Rebase RebaseMode // pull.rebase; RebaseUnset when not configured
+	FF     string     // pull.ff: true, false or only
+}
+
+// PushConfig holds the push.* settings.
+type PushConfig struct {
+	Default         string // push.default, default "simple"
+	AutoSetupRemote bool   // push.autoSetupRemote
+	FollowTags      bool   // push.followTags
+	GPGSign         string // push.gpgSign: true, false or if-asked
+}
+
+// Branch describes a branch.<name> section.
+type Branch struct {
+	Name        string
+	Remote      string     // branch.<name>.remote
+	PushRemote  string     // branch.<name>.pushRemote
+	Merge       string     // branch.<name>.merge, the upstream ref
+	Rebase      RebaseMode // branch.<name>.rebase; RebaseUnset when not configured
+	Description string     // branch.<name>.description
+}
+
+const (
+	InitDefaultBranch = "init.defaultbranch"
+	InitTemplateDir   = "init.templatedir"
+)
+
+// DefaultBranchName is the initial branch name git uses when
+// init.defaultBranch is unset.
+const DefaultBranchName = "master"
+
+// InitConfig holds the init.* settings used when creating repositories.
+type InitConfig struct {
+	DefaultBranch         string // init.defaultBranch, default "master"
+	DefaultBranchExplicit bool   // whether init.defaultBranch was configured
+	TemplateDir           string // init.templateDir, expanded
+}
+
+const (
+	CoreEditor         = "core.editor"
+	CorePager          = "core.pager"
+	CoreAutoCRLF       = "core.autocrlf"
+	CoreFileMode       = "core.filemode"
+	CoreIgnoreCase     = "core.ignorecase"
+	CoreBare           = "core.bare"
+	CoreExcludesFile   = "core.excludesfile"
+	CoreAttributesFile = "core.attributesfile"
+	CoreHooksPath      = "core.hookspath"
+	CoreWorktree       = "core.worktree"
+	CoreSSHCommand     = "core.sshcommand"
+)
+
+const (
+	CoreSparseCheckout     = "core.sparsecheckout"
+	CoreSparseCheckoutCone = "core.sparsecheckoutcone"
+	IndexSparse            = "index.sparse"
+)
+
+// SparseCheckoutConfig holds the settings that control a sparse checkout.
+type SparseCheckoutConfig struct {
+	Enabled     bool // core.sparseCheckout
+	Cone        bool // core.sparseCheckoutCone
+	IndexSparse bool // index.sparse
+}
+
+// CoreConfig holds the core.* settings.
+type CoreConfig struct {
+	Editor         string // core.editor
+	Pager          string // core.pager
+	AutoCRLF       string // core.autocrlf: true, false or input
+	FileMode       bool   // core.fileMode, default true
+	IgnoreCase     bool   // core.ignoreCase
+	Bare           bool   // core.bare
+	ExcludesFile   string // core.excludesFile, resolved like GetExcludesFile
+	AttributesFile string // core.attributesFile, resolved like GetAttributesFile
+	HooksPath      string // core.hooksPath, resolved like GetHooksPath
+	Worktree       string // core.worktree, expanded
+}
+
+const (
+	SafeDirectory = "safe.directory"
+)
+
+const (
+	AdviceSection       = "advice"
+	MaintenanceAuto     = "maintenance.auto"
+	MaintenanceStrategy = "maintenance.strategy"
+	MaintenanceRepo     = "maintenance.repo"
+)
+
+// AdviceConfig holds the advice.* hints. Every hint is enabled unless turned
+// off; hints without a field here are reported in Extra, keyed by their
+// lowercased name.
+type AdviceConfig struct {
+	PushUpdateRejected bool // advice.pushUpdateRejected
+	StatusHints        bool // advice.statusHints
+	CommitBeforeMerge  bool // advice.commitBeforeMerge
+	ResolveConflict    bool // advice.resolveConflict
+	ImplicitIdentity   bool // advice.implicitIdentity
+	DetachedHead       bool // advice.detachedHead
+	AddIgnoredFile     bool // advice.addIgnoredFile
+	SkippedCherryPicks bool // advice.skippedCherryPicks
+	Extra              map[string]bool
+}
+
+// Enabled reports whether the named hint is enabled, e.g. Enabled("detachedHead").
+// Hints that are not configured are enabled.
+func (a *AdviceConfig) Enabled(name string) bool {
+	switch strings.ToLower(name) {
+	case "pushupdaterejected":
+		return a.PushUpdateRejected
+	case "statushints":
+		return a.StatusHints
+	case "commitbeforemerge":
+		return a.CommitBeforeMerge
+	case "resolveconflict":
+		return a.ResolveConflict
+	case "implicitidentity":
+		return a.ImplicitIdentity
+	case "detachedhead":
+		return a.DetachedHead
+	case "addignoredfile":
+		return a.AddIgnoredFile
+	case "skippedcherrypicks":
+		return a.SkippedCherryPicks
+	}
+	if enabled, ok := a.Extra[strings.ToLower(name)]; ok {
+		return enabled
+	}
+	return true
+}
+
+// MaintenanceConfig holds the maintenance.* settings.
+type MaintenanceConfig struct {
+	Auto     bool     // maintenance.auto, default true
+	Strategy string   // maintenance.strategy, e.g. none or incremental
+	Repos    []string // maintenance.repo, one entry per registered repository
+}
+
+const (
+	HTTPSSLVerify       = "http.sslverify"
+	HTTPSSLCAInfo       = "http.sslcainfo"
+	HTTPSSLCert         = "http.sslcert"
+	HTTPSSLKey          = "http.sslkey"
+	HTTPProxy           = "http.proxy"
+	HTTPProxyAuthMethod = "http.proxyauthmethod"
+	HTTPPostBuffer      = "http.postbuffer"
+	HTTPLowSpeedLimit   = "http.lowspeedlimit"
+	HTTPLowSpeedTime    = "http.lowspeedtime"
+	HTTPExtraHeader     = "http.extraheader"
+	HTTPCookieFile      = "http.cookiefile"
+	HTTPUserAgent       = "http.useragent"
+)
+
+// HTTPConfig holds the http.* settings that apply to a URL.
+type HTTPConfig struct {
+	SSLVerify     bool     // http.sslVerify, default true
+	SSLCAInfo     string   // http.sslCAInfo, expanded
+	SSLCert       string   // http.sslCert, expanded
+	SSLKey        string   // http.sslKey, expanded
+	Proxy         string   // http.proxy
+	PostBuffer    int      // http.postBuffer in bytes, 0 if unset
+	LowSpeedLimit int      // http.lowSpeedLimit in bytes per second
+	LowSpeedTime  int      // http.lowSpeedTime in seconds
+	ExtraHeaders  []string // http.extraHeader, in order; an empty value resets the list
+	CookieFile    string   // http.cookieFile, expanded
+	UserAgent     string   // http.userAgent
+}
+
+// ProxyConfig holds the proxy settings git's HTTP transport uses, gathered
+// from the config and, as curl does, from the proxy environment variables.
+type ProxyConfig struct {
+	HTTPProxy       string // proxy for http:// URLs: http.proxy, then http_proxy, then all_proxy
+	HTTPSProxy      string // proxy for https:// URLs: http.proxy, then HTTPS_PROXY, then all_proxy
+	NoProxy         string // no_proxy or NO_PROXY, a comma-separated list of excluded hosts
+	ProxyAuthMethod string // GIT_HTTP_PROXY_AUTHMETHOD or http.proxyAuthMethod
+}
+
+const (
+	CommitGraphGenerationVersion = "commitgraph.generationversion"
+	CommitGraphReadChangedPaths  = "commitgraph.readchangedpaths"
+	FetchWriteCommitGraph        = "fetch.writecommitgraph"
+	GCWriteCommitGraph           = "gc.writecommitgraph"
+)
+
+// CommitGraphConfig holds the settings that control commit-graph generation,
+// gathered from the commitGraph, fetch and gc sections.
+type CommitGraphConfig struct {
+	GenerationVersion int  // commitGraph.generationVersion, default 2
+	ReadChangedPaths  bool // commitGraph.readChangedPaths, default true
+	WriteDuringFetch  bool // fetch.writeCommitGraph, default false
+	WriteDuringGC     bool // gc.writeCommitGraph, default true
+}
+
+const (
+	SendEmailIdentity       = "sendemail.identity"
+	SendEmailSMTPServer     = "sendemail.smtpserver"
+	SendEmailSMTPUser       = "sendemail.smtpuser"
+	SendEmailSMTPEncryption = "sendemail.smtpencryption"
+	SendEmailSMTPServerPort = "sendemail.smtpserverport"
+	SendEmailFrom           = "sendemail.from"
+	SendEmailTo             = "sendemail.to"
+	SendEmailCc             = "sendemail.cc"
+	SendEmailBcc            = "sendemail.bcc"
+)
+
+// SendEmailConfig holds the sendemail.* settings for one identity, with the
+// identity's sendemail.<identity>.* values taking precedence over the base
+// section.
+type SendEmailConfig struct {
+	Identity       string   // the identity applied, empty for the base section only
+	SMTPServer     string   // sendemail.smtpServer
+	SMTPUser       string   // sendemail.smtpUser
+	SMTPEncryption string   // sendemail.smtpEncryption: ssl or tls, anything else is plain SMTP
+	SMTPServerPort int      // sendemail.smtpServerPort, 0 if unset
+	From           string   // sendemail.from
+	To             []string // sendemail.to
+	Cc             []string // sendemail.cc
+	Bcc            []string // sendemail.bcc
+}
+
+const (
+	GitFlowBranchSection    = "gitflow.branch"
+	GitFlowPrefixSection    = "gitflow.prefix"
+	GitFlowBranchMaster     = "gitflow.branch.master"
+	GitFlowBranchDevelop    = "gitflow.branch.develop"
+	GitFlowPrefixFeature    = "gitflow.prefix.feature"
+	GitFlowPrefixBugfix     = "gitflow.prefix.bugfix"
+	GitFlowPrefixRelease    = "gitflow.prefix.release"
+	GitFlowPrefixHotfix     = "gitflow.prefix.hotfix"
+	GitFlowPrefixSupport    = "gitflow.prefix.support"
+	GitFlowPrefixVersionTag = "gitflow.prefix.versiontag"
+)
+
+// GitFlowConfig holds the branch names and prefixes that git flow init
+// writes to the gitflow "branch" and "prefix" subsections.
+type GitFlowConfig struct {
+	MasterBranch     string // gitflow.branch.master
+	DevelopBranch    string // gitflow.branch.develop
+	FeaturePrefix    string // gitflow.prefix.feature
+	BugfixPrefix     string // gitflow.prefix.bugfix
+	ReleasePrefix    string // gitflow.prefix.release
+	HotfixPrefix     string // gitflow.prefix.hotfix
+	SupportPrefix    string // gitflow.prefix.support
+	VersionTagPrefix string // gitflow.prefix.versiontag, often empty
+}
+
+const (
+	NamespaceGit         = "namespace.git"
+	CoreObjectsDirectory = "core.objectsdirectory"
+	ObjectsAlternates    = "objects.alternates"
+)
+
+// NamespaceConfig holds the git namespace a server serves a repository
+// under, as recorded in its config by hosting setups.
+type NamespaceConfig struct {
+	Namespace string // namespace.git, empty if unset
+}
+
+// AlternatesConfig lists the object directories a repository borrows
+// objects from, as used by reference clones and CI caches.
+type AlternatesConfig struct {
+	Paths []string // objects.alternates then objects/info/alternates, without duplicates
+}
@@ -0,0 +1,265 @@
+package gitcfg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tagName = "gitcfg"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+type fieldTag struct {
+	key          string
+	defaultValue string
+	hasDefault   bool
+	omitEmpty    bool
+}
+
+func parseFieldTag(tag string) fieldTag {
+	key, options, _ := strings.Cut(tag, ",")
+	ft := fieldTag{key: strings.TrimSpace(key)}
+
+	// default= consumes the rest of the tag so defaults may contain commas
+	if idx := strings.Index(options, "default="); idx >= 0 {
+		ft.defaultValue = options[idx+len("default="):]
+		ft.hasDefault = true
+		options = options[:idx]
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		if strings.TrimSpace(option) == "omitempty" {
+			ft.omitEmpty = true
+		}
+	}
+
+	return ft
+}
+
+// Unmarshal populates the struct pointed to by v from the configuration.
+//
+// Fields are mapped with a `gitcfg:"section.key"` tag. A default can be given
+// with `gitcfg:"http.sslverify,default=true"` and is used when the key is
+// absent. Fields of struct type are filled recursively, with their tag used as
+// a prefix for the nested keys (e.g. `gitcfg:"remote.origin"` on the struct and
+// `gitcfg:"url"` inside it). Untagged fields are ignored.
+//
+// Supported field types are string, bool, all int and uint widths, float32,
+// float64, time.Duration and []string, which receives every value of a
+// multi-valued key.
+func (c *Config) Unmarshal(v any) error {
+	if err := c.EnsureLoaded(); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &ConfigError{
+			Op:  "unmarshal",
+			Err: errors.New("target must be a non-nil pointer to a struct"),
+		}
+	}
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	return c.unmarshalStruct(rv.Elem(), "")
+}
+
+func (c *Config) unmarshalStruct(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok || tag == "-" {
+			continue
+		}
+
+		ft := parseFieldTag(tag)
+		key := ft.key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != durationType {
+			if err := c.unmarshalStruct(fv, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values := c.lookupValues(key)
+		if len(values) == 0 {
+			if !ft.hasDefault {
+				continue
+			}
+			values = []string{ft.defaultValue}
+		}
+
+		if err := setFieldValue(fv, values); err != nil {
+			section, subkey, _ := parseConfigKey(key)
+			return &ConfigError{
+				Op:      "unmarshal",
+				Key:     subkey,
+				Section: section,
+				Err:     fmt.Errorf("field %s: %w", field.Name, err),
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupValues returns all values for a dotted key. Callers must hold the lock.
+func (c *Config) lookupValues(key string) []string {
+	return valueStrings(c.lookupRawValues(key))
+}
+
+func setFieldValue(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Slice {
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%w: unsupported slice type %s", ErrInvalidValue, fv.Type())
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			slice.Index(i).SetString(value)
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	// Single-valued fields follow git's last-one-wins rule
+	return setScalarValue(fv, values[len(values)-1])
+}
+
+func setScalarValue(fv reflect.Value, value string) error {
+	if fv.Type() == durationType {
+		d, err := parseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	var err error
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		var b bool
+		if b, err = parseBool(value); err == nil {
+			fv.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(strings.TrimSpace(value), 10, fv.Type().Bits()); err == nil {
+			fv.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(strings.TrimSpace(value), 10, fv.Type().Bits()); err == nil {
+			fv.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(strings.TrimSpace(value), fv.Type().Bits()); err == nil {
+			fv.SetFloat(f)
+		}
+	default:
+		err = fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+	return nil
+}
+
+// GetSectionAsStruct populates dst from a single section. Each exported field
+// tagged `gitcfg:"key"` receives the value of section.key, converted as by
+// Unmarshal; []string fields receive every value of the key.
+// A tagged key that is absent yields an error wrapping ErrKeyNotFound unless
+// the tag has a default= or the omitempty option, and a missing section
+// yields ErrSectionNotFound.
+func GetSectionAsStruct[T any](c *Config, section string, dst *T) error {
+	rv := reflect.ValueOf(dst)
+	if dst == nil || rv.Elem().Kind() != reflect.Struct {
+		return &ConfigError{
+			Op:      "get",
+			Section: section,
+			Err:     errors.New("target must be a non-nil pointer to a struct"),
+		}
+	}
+	rv = rv.Elem()
+
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	if err := c.loadErr(); err != nil {
+		return err
+	}
+
+	section = normalizeSectionPrefix(section)
+	if _, exists := c.sections[section]; !exists {
+		return &ConfigError{
+			Op:      "get",
+			Section: section,
+			Err:     ErrSectionNotFound,
+		}
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok || tag == "-" {
+			continue
+		}
+
+		ft := parseFieldTag(tag)
+		key := strings.ToLower(ft.key)
+
+		values := valueStrings(c.rawValues(section, key))
+		if len(values) == 0 {
+			switch {
+			case ft.hasDefault:
+				values = []string{ft.defaultValue}
+			case ft.omitEmpty:
+				continue
+			default:
+				return &ConfigError{
+					Op:      "get",
+					Key:     key,
+					Section: section,
+					Err:     fmt.Errorf("field %s: %w", field.Name, ErrKeyNotFound),
+				}
+			}
+		}
+
+		if err := setFieldValue(rv.Field(i), values); err != nil {
+			return &ConfigError{
+				Op:      "get",
+				Key:     key,
+				Section: section,
+				Err:     fmt.Errorf("field %s: type conversion failed: %w", field.Name, err),
+			}
+		}
+	}
+
+	return nil
+}
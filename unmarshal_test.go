@@ -0,0 +1,271 @@
+package gitcfg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshal(t *testing.T) {
+	parser := newParser()
+	config := &Config{
+		sections: make(map[string]map[string]string),
+	}
+
+	configData := `[user]
+    name = Test User
+[core]
+    compression = 9
+    bigFileThreshold = 512
+[http]
+    timeout = 1m30s
+    postbuffer = 1048576
+[pack]
+    ratio = 0.75
+[remote "origin"]
+    url = https://github.com/example/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+`
+	if err := parser.parseConfigReader(strings.NewReader(configData), config, ConfigSource{Path: "test"}); err != nil {
+		t.Fatalf("parseConfigReader failed: %v", err)
+	}
+
+	type remote struct {
+		URL   string   `gitcfg:"url"`
+		Fetch []string `gitcfg:"fetch"`
+	}
+
+	var cfg struct {
+		Name        string        `gitcfg:"user.name"`
+		Email       string        `gitcfg:"user.email"`
+		SSLVerify   bool          `gitcfg:"http.sslverify,default=true"`
+		Compression int8          `gitcfg:"core.compression"`
+		Threshold   uint32        `gitcfg:"core.bigFileThreshold"`
+		PostBuffer  int64         `gitcfg:"http.postbuffer"`
+		Timeout     time.Duration `gitcfg:"http.timeout"`
+		Ratio       float64       `gitcfg:"pack.ratio"`
+		Origin      remote        `gitcfg:"remote.origin"`
+		Ignored     string
+	}
+
+	if err := config.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if cfg.Name != "Test User" {
+		t.Errorf("Expected 'Test User', got '%s'", cfg.Name)
+	}
+	if cfg.Email != "" {
+		t.Errorf("Expected empty email for missing key, got '%s'", cfg.Email)
+	}
+	if !cfg.SSLVerify {
+		t.Error("Expected default SSLVerify to be true")
+	}
+	if cfg.Compression != 9 {
+		t.Errorf("Expected compression 9, got %d", cfg.Compression)
+	}
+	if cfg.Threshold != 512 {
+		t.Errorf("Expected threshold 512, got %d", cfg.Threshold)
+	}
+	if cfg.PostBuffer != 1048576 {
+		t.Errorf("Expected postbuffer 1048576, got %d", cfg.PostBuffer)
+	}
+	if cfg.Timeout != 90*time.Second {
+		t.Errorf("Expected timeout 1m30s, got %s", cfg.Timeout)
+	}
+	if cfg.Ratio != 0.75 {
+		t.Errorf("Expected ratio 0.75, got %f", cfg.Ratio)
+	}
+	if cfg.Origin.URL != "https://github.com/example/repo.git" {
+		t.Errorf("Expected origin URL, got '%s'", cfg.Origin.URL)
+	}
+	if len(cfg.Origin.Fetch) != 2 {
+		t.Fatalf("Expected 2 fetch refspecs, got %d", len(cfg.Origin.Fetch))
+	}
+	if cfg.Origin.Fetch[1] != "+refs/tags/*:refs/tags/*" {
+		t.Errorf("Expected tags refspec, got '%s'", cfg.Origin.Fetch[1])
+	}
+}
+
+func TestUnmarshalConversionError(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{
+			"core": {"compression": "high"},
+		},
+	}
+
+	var cfg struct {
+		Compression int `gitcfg:"core.compression"`
+	}
+
+	err := config.Unmarshal(&cfg)
+	if err == nil {
+		t.Fatal("Expected conversion error")
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("Expected ConfigError, got %T", err)
+	}
+	if configErr.Section != "core" || configErr.Key != "compression" {
+		t.Errorf("Expected error for core.compression, got %s.%s", configErr.Section, configErr.Key)
+	}
+	if !strings.Contains(err.Error(), "Compression") {
+		t.Errorf("Expected error to name the struct field, got '%s'", err.Error())
+	}
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Error("Expected error to wrap ErrInvalidValue")
+	}
+}
+
+func TestUnmarshalInvalidTarget(t *testing.T) {
+	config := &Config{
+		sections: map[string]map[string]string{},
+	}
+
+	var cfg struct{}
+	if err := config.Unmarshal(cfg); err == nil {
+		t.Error("Expected error for non-pointer target")
+	}
+	if err := config.Unmarshal(nil); err == nil {
+		t.Error("Expected error for nil target")
+	}
+}
+
+func TestGetMultiValue(t *testing.T) {
+	parser := newParser()
+	config := &Config{
+		sections: make(map[string]map[string]string),
+	}
+
+	configData := `[remote "origin"]
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+`
+	if err := parser.parseConfigReader(strings.NewReader(configData), config, ConfigSource{Path: "test"}); err != nil {
+		t.Fatalf("parseConfigReader failed: %v", err)
+	}
+
+	values, err := config.GetMultiValue("remote.origin.fetch")
+	if err != nil {
+		t.Fatalf("GetMultiValue failed: %v", err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, got %d", len(values))
+	}
+
+	// Single-value access keeps last-one-wins semantics
+	last, _ := Get[string](config, "remote.origin.fetch")
+	if last != "+refs/tags/*:refs/tags/*" {
+		t.Errorf("Expected last value, got '%s'", last)
+	}
+
+	if _, err := config.GetMultiValue("remote.origin.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestGetSectionAsStruct(t *testing.T) {
+	config := parseTestConfig(t, `[remote "origin"]
+    url = https://github.com/user/repo.git
+    fetch = +refs/heads/*:refs/remotes/origin/*
+    fetch = +refs/tags/*:refs/tags/*
+    prune = true
+    skipFetchAll = no
+    timeout = 30
+    tagOpt = --no-tags
+`)
+
+	type tagMode string
+	type remoteSettings struct {
+		URL          string   `gitcfg:"url"`
+		Fetch        []string `gitcfg:"fetch"`
+		Prune        bool     `gitcfg:"prune"`
+		SkipFetchAll bool     `gitcfg:"skipfetchall"`
+		Timeout      uint16   `gitcfg:"timeout"`
+		TagOpt       tagMode  `gitcfg:"tagOpt"`
+		PushURL      string   `gitcfg:"pushurl,omitempty"`
+		Mirror       bool     `gitcfg:"mirror,default=false"`
+		Ignored      string
+	}
+
+	var settings remoteSettings
+	if err := GetSectionAsStruct(config, "remote.origin", &settings); err != nil {
+		t.Fatalf("GetSectionAsStruct failed: %v", err)
+	}
+
+	expected := remoteSettings{
+		URL:     "https://github.com/user/repo.git",
+		Fetch:   []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"},
+		Prune:   true,
+		Timeout: 30,
+		TagOpt:  "--no-tags",
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, settings)
+	}
+}
+
+func TestGetSectionAsStructErrors(t *testing.T) {
+	config := parseTestConfig(t, "[remote \"origin\"]\n    url = https://github.com/user/repo.git\n    prune = sometimes\n")
+
+	var missing struct {
+		PushURL string `gitcfg:"pushurl"`
+	}
+	if err := GetSectionAsStruct(config, "remote.origin", &missing); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+
+	var invalid struct {
+		Prune bool `gitcfg:"prune"`
+	}
+	if err := GetSectionAsStruct(config, "remote.origin", &invalid); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue, got %v", err)
+	}
+
+	var unsupported struct {
+		URL []byte `gitcfg:"url"`
+	}
+	if err := GetSectionAsStruct(config, "remote.origin", &unsupported); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for unsupported type, got %v", err)
+	}
+
+	var other struct {
+		URL string `gitcfg:"url"`
+	}
+	if err := GetSectionAsStruct(config, "remote.upstream", &other); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+}
+
+func TestGetSectionAsStructMatchesUnmarshal(t *testing.T) {
+	config := parseTestConfig(t, `[http]
+    lowSpeedTime = 30s
+    postBuffer = " 524288 "
+`)
+
+	var settings struct {
+		LowSpeedTime time.Duration `gitcfg:"lowspeedtime"`
+		PostBuffer   int           `gitcfg:"postbuffer"`
+	}
+	if err := GetSectionAsStruct(config, "http", &settings); err != nil {
+		t.Fatalf("GetSectionAsStruct failed: %v", err)
+	}
+	if settings.LowSpeedTime != 30*time.Second || settings.PostBuffer != 524288 {
+		t.Errorf("Expected 30s and 524288, got %+v", settings)
+	}
+
+	var unmarshaled struct {
+		LowSpeedTime time.Duration `gitcfg:"http.lowspeedtime"`
+		PostBuffer   int           `gitcfg:"http.postbuffer"`
+	}
+	if err := config.Unmarshal(&unmarshaled); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if unmarshaled.LowSpeedTime != settings.LowSpeedTime || unmarshaled.PostBuffer != settings.PostBuffer {
+		t.Errorf("Expected Unmarshal to agree, got %+v", unmarshaled)
+	}
+}
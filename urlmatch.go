@@ -0,0 +1,142 @@
+package gitcfg
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ftp":   "21",
+	"ftps":  "990",
+	"ssh":   "22",
+	"git":   "9418",
+}
+
+// urlMatch is the strength of a match between a configured URL pattern and a
+// target URL, compared like git's urlmatch: a longer host pattern wins, then a
+// longer path, then a pattern that names the user.
+type urlMatch struct {
+	hostLen int
+	pathLen int
+	user    bool
+}
+
+func (m urlMatch) less(other urlMatch) bool {
+	if m.hostLen != other.hostLen {
+		return m.hostLen < other.hostLen
+	}
+	if m.pathLen != other.pathLen {
+		return m.pathLen < other.pathLen
+	}
+	return !m.user && other.user
+}
+
+type normalizedURL struct {
+	scheme string
+	user   string
+	host   string
+	port   string
+	path   string
+}
+
+func normalizeURL(raw string) (normalizedURL, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return normalizedURL{}, false
+	}
+
+	n := normalizedURL{
+		scheme: strings.ToLower(u.Scheme),
+		host:   strings.ToLower(u.Hostname()),
+		port:   u.Port(),
+		path:   u.Path,
+	}
+	if u.User != nil {
+		n.user = u.User.Username()
+	}
+	if n.port == "" {
+		n.port = defaultPorts[n.scheme]
+	}
+	if n.path == "" {
+		n.path = "/"
+	}
+	return n, true
+}
+
+// matchURL reports whether the configured URL pattern (the subsection of
+// e.g. http.<url>.* or credential.<url>.*) applies to target. The pattern
+// host may use '*' wildcards within a single label, like *.example.com, and
+// the pattern path must be a prefix of the target path at a '/' boundary.
+func matchURL(pattern, target string) (urlMatch, bool) {
+	p, ok := normalizeURL(pattern)
+	if !ok {
+		return urlMatch{}, false
+	}
+	t, ok := normalizeURL(target)
+	if !ok {
+		return urlMatch{}, false
+	}
+
+	if p.scheme != t.scheme || p.port != t.port {
+		return urlMatch{}, false
+	}
+	if p.user != "" && p.user != t.user {
+		return urlMatch{}, false
+	}
+	if !matchHost(p.host, t.host) {
+		return urlMatch{}, false
+	}
+
+	prefix := strings.TrimSuffix(p.path, "/")
+	if prefix != "" && t.path != prefix && !strings.HasPrefix(t.path, prefix+"/") {
+		return urlMatch{}, false
+	}
+
+	return urlMatch{hostLen: len(p.host), pathLen: len(prefix), user: p.user != ""}, true
+}
+
+func matchHost(pattern, host string) bool {
+	patternLabels := strings.Split(pattern, ".")
+	hostLabels := strings.Split(host, ".")
+	if len(patternLabels) != len(hostLabels) {
+		return false
+	}
+
+	for i, label := range patternLabels {
+		if matched, err := path.Match(label, hostLabels[i]); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// matchingURLSections returns the section.<url> sections whose URL applies
+// to target, ordered from the weakest to the strongest match so that later
+// entries take precedence.
+func (c *Config) matchingURLSections(section, target string) []string {
+	type candidate struct {
+		name  string
+		match urlMatch
+	}
+
+	var candidates []candidate
+	for _, subsection := range c.SubsectionNames(section) {
+		if match, ok := matchURL(subsection, target); ok {
+			candidates = append(candidates, candidate{section + "." + subsection, match})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].match.less(candidates[j].match)
+	})
+
+	names := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		names[i] = candidate.name
+	}
+	return names
+}
@@ -0,0 +1,64 @@
+package gitcfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchURL(t *testing.T) {
+	tests := []struct {
+		pattern string
+		target  string
+		matches bool
+	}{
+		{"https://example.com", "https://example.com/repo.git", true},
+		{"https://example.com/", "https://example.com", true},
+		{"https://Example.COM", "https://example.com/repo.git", true},
+		{"https://example.com:443", "https://example.com/repo.git", true},
+		{"https://example.com:8443", "https://example.com/repo.git", false},
+		{"http://example.com", "https://example.com/repo.git", false},
+		{"https://example.com/org", "https://example.com/org/repo.git", true},
+		{"https://example.com/org/", "https://example.com/org/repo.git", true},
+		{"https://example.com/org", "https://example.com/organization/repo.git", false},
+		{"https://*.example.com", "https://git.example.com/repo.git", true},
+		{"https://*.example.com", "https://example.com/repo.git", false},
+		{"https://*.example.com", "https://a.b.example.com/repo.git", false},
+		{"https://user@example.com", "https://user@example.com/repo.git", true},
+		{"https://user@example.com", "https://other@example.com/repo.git", false},
+		{"https://user@example.com", "https://example.com/repo.git", false},
+		{"https://example.com", "https://user@example.com/repo.git", true},
+		{"example.com", "https://example.com/repo.git", false},
+		{"https://example.com", "not a url", false},
+	}
+
+	for _, test := range tests {
+		if _, ok := matchURL(test.pattern, test.target); ok != test.matches {
+			t.Errorf("matchURL(%q, %q) = %v, expected %v", test.pattern, test.target, ok, test.matches)
+		}
+	}
+}
+
+func TestMatchingURLSectionsOrder(t *testing.T) {
+	config := parseTestConfig(t, `[http "https://example.com/org/repo.git"]
+    sslVerify = false
+[http "https://*.com"]
+    sslVerify = false
+[http "https://example.com"]
+    sslVerify = true
+[http "https://user@example.com"]
+    sslVerify = true
+[http "https://other.com"]
+    sslVerify = true
+`)
+
+	expected := []string{
+		"http.https://*.com",
+		"http.https://example.com",
+		"http.https://user@example.com",
+		"http.https://example.com/org/repo.git",
+	}
+	sections := config.matchingURLSections("http", "https://user@example.com/org/repo.git")
+	if !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Expected %v, got %v", expected, sections)
+	}
+}
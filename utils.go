@@ -1,25 +1,83 @@
 package gitcfg
 
 import (
+    "fmt"
     "strings"
 )
 
+// parseConfigKey splits a dotted key into its internal section and key name.
+// The key name is everything after the last dot and the section everything
+// before it, so subsections may themselves contain dots:
+//
+//	remote.origin.url                -> remote.origin, url
+//	url.https://github.com/.insteadOf -> url.https://github.com/, insteadof
+//
+// Section and key names are case-insensitive in git and are lowercased;
+// subsection names are case-sensitive and kept as-is.
 func parseConfigKey(key string) (section, keyName string, err error) {
-	parts := strings.SplitN(key, ".", 2)
-	if len(parts) != 2 {
+	first := strings.IndexByte(key, '.')
+	last := strings.LastIndexByte(key, '.')
+	if first <= 0 || last == len(key)-1 {
 		return "", "", ErrInvalidKeyFormat
 	}
 
-	section, remaining := parts[0], parts[1]
-
-	// remote.origin.url -> section: remote.origin, key: url
-	if strings.Contains(remaining, ".") {
-		subparts := strings.SplitN(remaining, ".", 2)
-		if len(subparts) == 2 {
-			section = section + "." + subparts[0]
-			remaining = subparts[1]
+	section = strings.ToLower(key[:first])
+	if last > first {
+		if section == key[:first] {
+			// Already lowercase: slice instead of concatenating
+			section = key[:last]
+		} else {
+			section += key[first:last]
 		}
 	}
 
-	return section, remaining, nil
+	return section, strings.ToLower(key[last+1:]), nil
+}
+
+// SplitConfigKey splits a dotted key into its section, optional subsection
+// and key name. Section and key names are lowercased, the subsection keeps
+// its case and may contain dots:
+//
+//	user.name                         -> user, "", name
+//	remote.origin.url                 -> remote, origin, url
+//	url.https://github.com/.insteadOf -> url, https://github.com/, insteadof
+func SplitConfigKey(key string) (section, subsection, keyName string, err error) {
+	if !isValidConfigKey(key) {
+		return "", "", "", fmt.Errorf("%w: %s", ErrInvalidKeyFormat, key)
+	}
+
+	fullSection, keyName, err := parseConfigKey(key)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%w: %s", err, key)
+	}
+
+	section, subsection, _ = strings.Cut(fullSection, ".")
+	return section, subsection, keyName, nil
+}
+
+// JoinConfigKey validates the components of a key and returns its canonical
+// dotted form, with section and key name lowercased. An empty subsection
+// yields a two-component key.
+func JoinConfigKey(section, subsection, key string) (string, error) {
+	if !isValidKeyName(section) {
+		return "", fmt.Errorf("%w: invalid section name %q", ErrInvalidKeyFormat, section)
+	}
+	if subsection != "" && !isValidSubsection(subsection) {
+		return "", fmt.Errorf("%w: invalid subsection name %q", ErrInvalidKeyFormat, subsection)
+	}
+	if !isValidKeyName(key) {
+		return "", fmt.Errorf("%w: invalid key name %q", ErrInvalidKeyFormat, key)
+	}
+
+	section, key = strings.ToLower(section), strings.ToLower(key)
+	if subsection == "" {
+		return section + "." + key, nil
+	}
+	return section + "." + subsection + "." + key, nil
+}
+
+// IsValidConfigKey reports whether key is a valid section.key or
+// section.subsection.key.
+func IsValidConfigKey(key string) bool {
+	return isValidConfigKey(key)
 }
@@ -0,0 +1,134 @@
+package gitcfg
+
+import (
+	"context"
+	"slices"
+	"sort"
+)
+
+// VerifyKind classifies a key on which the file parser and git disagree.
+type VerifyKind int
+
+const (
+	// The key is reported by git but was not read from the files.
+	MissingInFiles VerifyKind = iota
+	// The key was read from the files but git does not report it.
+	MissingInGit
+	// Both define the key with different values.
+	ValueMismatch
+)
+
+func (k VerifyKind) String() string {
+	switch k {
+	case MissingInFiles:
+		return "missing in files"
+	case MissingInGit:
+		return "missing in git"
+	case ValueMismatch:
+		return "value mismatch"
+	default:
+		return "unknown"
+	}
+}
+
+// VerifyEntry is a key on which the file parser and git disagree. Values
+// hold every value of the key in load order.
+type VerifyEntry struct {
+	Key        string
+	Kind       VerifyKind
+	FileValues []string
+	GitValues  []string
+}
+
+// VerifyDiff lists the keys on which the file parser and git disagree,
+// sorted by key.
+type VerifyDiff struct {
+	Entries []VerifyEntry
+}
+
+// IsEmpty reports whether git agreed with the config on every key.
+func (d *VerifyDiff) IsEmpty() bool {
+	return len(d.Entries) == 0
+}
+
+// VerifyAgainstGit reads the scopes, files and repository of the Load that
+// produced the config once more through the git binary, as WithGitCommand
+// does, and reports every key where git's view differs from the config's
+// current values. Values supplied by WithDefaults are ignored since git
+// never sees them. It fails with ErrNotLoaded for configs not produced by
+// Load and with ErrPartialConfig for filtered ones.
+func (c *Config) VerifyAgainstGit(ctx context.Context) (*VerifyDiff, error) {
+	if err := c.EnsureLoaded(); err != nil {
+		return nil, err
+	}
+
+	c.rlock()
+	load := c.load
+	partial := c.partial
+	c.mu.RUnlock()
+
+	if partial {
+		return nil, &ConfigError{Op: "verify", Err: ErrPartialConfig}
+	}
+	if load == nil {
+		return nil, &ConfigError{Op: "verify", Err: ErrNotLoaded}
+	}
+
+	opts := *load
+	opts.useGitCommand = true
+	parser := newParser()
+	parser.logger = opts.logger
+	gitConfig, err := parser.parseFromGitCommand(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyEntries(c.loadedValues(), gitConfig.flatValues()), nil
+}
+
+// loadedValues is flatValues without the values supplied by WithDefaults.
+func (c *Config) loadedValues() map[string][]string {
+	c.rlock()
+	defer c.mu.RUnlock()
+
+	flat := make(map[string][]string)
+	for section, sectionMap := range c.sections {
+		for key := range sectionMap {
+			var values []string
+			for _, v := range c.rawValues(section, key) {
+				if v.source == nil || v.source.Type != SourceTypeDefault {
+					values = append(values, v.value)
+				}
+			}
+			if len(values) > 0 {
+				flat[section+"."+key] = values
+			}
+		}
+	}
+	return flat
+}
+
+func verifyEntries(files, git map[string][]string) *VerifyDiff {
+	diff := &VerifyDiff{}
+
+	for key, fileValues := range files {
+		gitValues, exists := git[key]
+		switch {
+		case !exists:
+			diff.Entries = append(diff.Entries, VerifyEntry{Key: key, Kind: MissingInGit, FileValues: fileValues})
+		case !slices.Equal(fileValues, gitValues):
+			diff.Entries = append(diff.Entries, VerifyEntry{Key: key, Kind: ValueMismatch, FileValues: fileValues, GitValues: gitValues})
+		}
+	}
+
+	for key, gitValues := range git {
+		if _, exists := files[key]; !exists {
+			diff.Entries = append(diff.Entries, VerifyEntry{Key: key, Kind: MissingInFiles, GitValues: gitValues})
+		}
+	}
+
+	sort.Slice(diff.Entries, func(i, j int) bool {
+		return diff.Entries[i].Key < diff.Entries[j].Key
+	})
+	return diff
+}
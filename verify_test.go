@@ -0,0 +1,62 @@
+package gitcfg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyAgainstGit(t *testing.T) {
+	repo, file := setupScopeRepo(t)
+
+	config, err := Load(WithGlobal(), WithLocal(), WithWorktree(), WithRepoPath(repo), WithFile(file),
+		WithDefaults(map[string]string{"init.defaultbranch": "main"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	diff, err := config.VerifyAgainstGit(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyAgainstGit failed: %v", err)
+	}
+	if !diff.IsEmpty() {
+		t.Fatalf("Expected file mode to agree with git, got %+v", diff.Entries)
+	}
+
+	config.SetMultiValue("url.https://github.com/.insteadof", nil)
+	config.Set("user.name", "Changed User")
+	config.Set("extra.local", "only in memory")
+
+	diff, err = config.VerifyAgainstGit(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyAgainstGit failed: %v", err)
+	}
+	expected := []VerifyEntry{
+		{Key: "extra.local", Kind: MissingInGit, FileValues: []string{"only in memory"}},
+		{Key: "url.https://github.com/.insteadof", Kind: MissingInFiles, GitValues: []string{"gh:"}},
+		{Key: "user.name", Kind: ValueMismatch, FileValues: []string{"Changed User"}, GitValues: []string{"Global User"}},
+	}
+	if !reflect.DeepEqual(diff.Entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diff.Entries)
+	}
+	if kind := diff.Entries[1].Kind.String(); kind != "missing in files" {
+		t.Errorf("Expected kind 'missing in files', got '%s'", kind)
+	}
+}
+
+func TestVerifyAgainstGitErrors(t *testing.T) {
+	config := parseTestConfig(t, "[user]\n\tname = Test User\n")
+	if _, err := config.VerifyAgainstGit(context.Background()); !errors.Is(err, ErrNotLoaded) {
+		t.Errorf("Expected ErrNotLoaded, got %v", err)
+	}
+
+	repo, _ := setupScopeRepo(t)
+	config, err := Load(WithLocal(), WithRepoPath(repo))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := config.Filter("remote").VerifyAgainstGit(context.Background()); !errors.Is(err, ErrPartialConfig) {
+		t.Errorf("Expected ErrPartialConfig, got %v", err)
+	}
+}